@@ -1,13 +1,50 @@
 package main
 
-import "github.com/a0dotrun/a0ctl/internal/command/root"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/a0dotrun/a0ctl/internal/builder"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/command/root"
+	"github.com/a0dotrun/a0ctl/internal/update"
+)
 
 func main() {
-	cmd := root.New()
+	os.Exit(run())
+}
+
+// run runs a0ctl and returns its exit code.
+func run() int {
+	cmd := root.New(cmdutil.New("", os.Stderr))
+	if isCredentialHelper(os.Args[0]) {
+		cmd.SetArgs(append([]string{"registry", "credential-helper"}, os.Args[1:]...))
+	}
 	err := cmd.Execute()
-	if err != nil {
-		return
+	if err == nil {
+		update.PrintNotice(os.Stderr)
+		return 0
 	}
+
+	var exitErr *cli.ExitError
+	if errors.As(err, &exitErr) {
+		update.PrintNotice(os.Stderr)
+		return exitErr.Code
+	}
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	update.PrintNotice(os.Stderr)
+	return 1
+}
+
+// isCredentialHelper tells whether a0ctl runs as the Docker credential
+// helper, through a link named docker-credential-a0ctl.
+func isCredentialHelper(arg0 string) bool {
+	name := strings.TrimSuffix(filepath.Base(arg0), ".exe")
+	return name == builder.CredentialHelperPrefix+builder.CredentialHelper
 }
 
 //
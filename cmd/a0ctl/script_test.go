@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api/apitest"
+	"github.com/a0dotrun/a0ctl/internal/settings"
+	"github.com/rogpeppe/go-internal/testscript"
+)
+
+// envLoginToken is the token the fake browser completes logins with, the
+// default user's if unset.
+const envLoginToken = "FAKE_LOGIN_TOKEN"
+
+func TestMain(m *testing.M) {
+	os.Exit(testscript.RunMain(m, map[string]func() int{
+		"a0ctl": run,
+		// Opened by auth login on Linux.
+		"xdg-open": fakeBrowser,
+	}))
+}
+
+// TestScripts runs the scripts in testdata/script against a fake API. Each
+// script gets its own API and settings directory, $A0_CONFIG_PATH.
+func TestScripts(t *testing.T) {
+	testscript.Run(t, testscript.Params{
+		Dir:                 filepath.Join("testdata", "script"),
+		RequireExplicitExec: true,
+		Setup: func(env *testscript.Env) error {
+			s := apitest.NewServer()
+			env.Defer(s.Close)
+			env.Values["server"] = s
+
+			env.Setenv(settings.EnvConfigPath, filepath.Join(env.WorkDir, "config"))
+			env.Setenv(settings.EnvBaseURL, s.URL)
+			env.Setenv(settings.EnvHomeURL, s.URL)
+			env.Setenv("XDG_CACHE_HOME", filepath.Join(env.WorkDir, "cache"))
+			env.Setenv("CI", "1")
+			return nil
+		},
+		Cmds: map[string]func(ts *testscript.TestScript, neg bool, args []string){
+			"fakeapi": fakeAPI,
+		},
+	})
+}
+
+// fakeAPI programs the fake API of the script:
+//
+//	fakeapi user <username> <token>
+//	fakeapi fail <pattern> <status> [message]
+func fakeAPI(ts *testscript.TestScript, neg bool, args []string) {
+	if neg {
+		ts.Fatalf("unsupported: ! fakeapi")
+	}
+	s := ts.Value("server").(*apitest.Server)
+	if len(args) == 0 {
+		ts.Fatalf("usage: fakeapi user|fail ...")
+	}
+	switch args[0] {
+	case "user":
+		if len(args) != 3 {
+			ts.Fatalf("usage: fakeapi user <username> <token>")
+		}
+		s.AddUser(args[1], args[2])
+	case "fail":
+		if len(args) < 3 || len(args) > 4 {
+			ts.Fatalf("usage: fakeapi fail <pattern> <status> [message]")
+		}
+		status, err := strconv.Atoi(args[2])
+		ts.Check(err)
+		f := apitest.Failure{Status: status}
+		if len(args) == 4 {
+			f.Message = args[3]
+		}
+		s.Inject(args[1], f)
+	default:
+		ts.Fatalf("unknown fakeapi command %q", args[0])
+	}
+}
+
+// fakeBrowser completes the login flow like a user would in the browser,
+// by calling back the local server of auth login with a token.
+func fakeBrowser() int {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: xdg-open <url>")
+		return 2
+	}
+	u, err := url.Parse(os.Args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	token := os.Getenv(envLoginToken)
+	if token == "" {
+		token = apitest.DefaultToken
+	}
+	q := u.Query()
+	callback := url.URL{
+		Scheme:   "http",
+		Host:     "127.0.0.1:" + q.Get("port"),
+		Path:     "/",
+		RawQuery: url.Values{"state": {q.Get("state")}, "jwt": {token}}.Encode(),
+	}
+	res, err := http.Get(callback.String())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		fmt.Fprintln(os.Stderr, "callback failed:", res.Status)
+		return 1
+	}
+	return 0
+}
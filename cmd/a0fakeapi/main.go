@@ -0,0 +1,35 @@
+// Command a0fakeapi serves the in-process fake of the a0 API with some
+// demo data, to try a0ctl offline.
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/api/apitest"
+	"github.com/a0dotrun/a0ctl/internal/settings"
+)
+
+func main() {
+	s := apitest.NewServer()
+	defer s.Close()
+
+	s.AddOrg(api.Org{ID: "org_demo", Slug: "demo", Name: "Demo Inc", Role: api.OrgRoleOwner, CreatedAt: time.Now().UTC()})
+	s.AddRegion(api.Region{Code: "fra", Name: "Frankfurt"})
+	s.AddRegion(api.Region{Code: "iad", Name: "Washington, D.C."})
+	s.AddApp(api.App{Name: "hello", Regions: []string{"fra"}})
+	s.AddLogs("hello", apitest.LogLine{Instance: "i-1", Region: "fra", Message: "listening on :8080"})
+
+	fmt.Printf("Fake a0 API listening on %s\n\n", s.URL)
+	fmt.Println("Point a0ctl at it with:")
+	fmt.Printf("  export %s=%s\n", settings.EnvBaseURL, s.URL)
+	fmt.Printf("  export %s=%s\n", settings.EnvAccessToken, apitest.DefaultToken)
+	fmt.Printf("  export %s=$(mktemp -d)\n", settings.EnvConfigPath)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	<-stop
+}
@@ -0,0 +1,289 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/klauspost/compress/zstd"
+)
+
+// buildContext is the state of a build context.
+type buildContext struct {
+	api.BuildContext
+	manifest api.ContextManifest
+}
+
+func (s *Server) registerDeployRoutes() {
+	s.routes.HandleFunc("POST /v1/blobs/missing", s.missingBlobs)
+	s.routes.HandleFunc("PUT /v1/blobs/{digest}", s.putBlob)
+	s.routes.HandleFunc("POST /v1/blobs/{digest}/upload", s.blobFromUpload)
+	s.routes.HandleFunc("POST /v1/contexts", s.createContext)
+	s.routes.HandleFunc("POST /v1/apps/{app}/deploys", s.withApp(s.createDeploy))
+	s.routes.HandleFunc("POST /v1/registry/tokens", s.registryToken)
+}
+
+// Registry is the host of the registry the fake issues credentials for.
+const Registry = "registry.a0.test"
+
+// RegistryPassword returns the registry password the fake issues to a
+// user.
+func RegistryPassword(username string) string {
+	return "registry-" + username
+}
+
+// Blob returns the decompressed content of a blob.
+func (s *Server) Blob(digest string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.blobs[digest]
+	return bytes.Clone(data), ok
+}
+
+// Context returns the manifest of a build context.
+func (s *Server) Context(id string) (api.ContextManifest, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bc, ok := s.contexts[id]
+	if !ok {
+		return api.ContextManifest{}, false
+	}
+	return bc.manifest, true
+}
+
+// Deployments returns the deployments of an app, latest first.
+func (s *Server) Deployments(name string) []api.Deployment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.apps[name]
+	if !ok {
+		return nil
+	}
+	return append([]api.Deployment(nil), a.deployments...)
+}
+
+// DeployRequests returns the requests of the deployments of an app, in
+// the order of Deployments.
+func (s *Server) DeployRequests(name string) []api.DeployRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.apps[name]
+	if !ok {
+		return nil
+	}
+	return append([]api.DeployRequest(nil), a.deployRequests...)
+}
+
+func (s *Server) missingBlobs(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.user(w, r); !ok {
+		return
+	}
+	var req struct {
+		Digests []string `json:"digests"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body: %v", err)
+		return
+	}
+	if len(req.Digests) > api.MaxMissingBlobs {
+		writeError(w, http.StatusBadRequest, "at most %d digests can be checked at once", api.MaxMissingBlobs)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	missing := []string{}
+	for _, digest := range req.Digests {
+		if _, ok := s.blobs[digest]; !ok {
+			missing = append(missing, digest)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string][]string{"missing": missing})
+}
+
+// putBlob stores a zstd-compressed blob after verifying its digest.
+func (s *Server) putBlob(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.user(w, r); !ok {
+		return
+	}
+	digest := r.PathValue("digest")
+	if r.Header.Get("Content-Encoding") != api.BlobEncodingZstd {
+		writeError(w, http.StatusUnsupportedMediaType, "blobs must be compressed with %s", api.BlobEncodingZstd)
+		return
+	}
+	compressed, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "could not read blob: %v", err)
+		return
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	defer decoder.Close()
+	data, err := decoder.DecodeAll(compressed, nil)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid zstd data: %v", err)
+		return
+	}
+	if got := digestOf(data); got != digest {
+		// Corrupted in transit, the client sends it again.
+		writeError(w, http.StatusUnprocessableEntity, "digest of the blob is %s, not %s", got, digest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[digest] = data
+	w.WriteHeader(http.StatusCreated)
+}
+
+// blobFromUpload stores the content of a completed upload as a blob.
+func (s *Server) blobFromUpload(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.user(w, r); !ok {
+		return
+	}
+	digest := r.PathValue("digest")
+	var req struct {
+		UploadID string `json:"uploadId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.uploads[req.UploadID]
+	if !ok || u.State != api.UploadStateCompleted {
+		writeError(w, http.StatusNotFound, "completed upload %s not found", req.UploadID)
+		return
+	}
+	if got := "sha256:" + u.SHA256; got != digest {
+		writeError(w, http.StatusUnprocessableEntity, "digest of upload %s is %s, not %s", u.ID, got, digest)
+		return
+	}
+	s.blobs[digest] = bytes.Clone(u.data)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// createContext stores a manifest whose blobs have all been uploaded.
+func (s *Server) createContext(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.user(w, r); !ok {
+		return
+	}
+	var manifest api.ContextManifest
+	if err := json.NewDecoder(r.Body).Decode(&manifest); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body: %v", err)
+		return
+	}
+	if root := api.MerkleRoot(manifest.Files); root != manifest.Root {
+		writeError(w, http.StatusBadRequest, "root of the manifest is %s, not %s", root, manifest.Root)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var missing []string
+	var size int64
+	for _, f := range manifest.Files {
+		size += f.Size
+		if _, ok := s.blobs[f.Digest]; !ok && f.Target == "" {
+			missing = append(missing, f.Path)
+		}
+	}
+	if len(missing) > 0 {
+		writeError(w, http.StatusConflict, "missing blobs for %s", strings.Join(missing, ", "))
+		return
+	}
+
+	bc := &buildContext{
+		BuildContext: api.BuildContext{
+			ID:    fmt.Sprintf("ctx_%d", len(s.contexts)+1),
+			Root:  manifest.Root,
+			Files: len(manifest.Files),
+			Size:  size,
+		},
+		manifest: manifest,
+	}
+	s.contexts[bc.ID] = bc
+	writeJSON(w, http.StatusCreated, bc.BuildContext)
+}
+
+// createDeploy releases the app right away, as if the build succeeded.
+// Deployments with a strategy then roll out as they are polled.
+func (s *Server) createDeploy(w http.ResponseWriter, r *http.Request, a *app) {
+	var req api.DeployRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body: %v", err)
+		return
+	}
+
+	if (req.ContextID == "") == (req.Image == "") {
+		writeError(w, http.StatusBadRequest, "exactly one of contextId and image is required")
+		return
+	}
+	if req.Dockerfile != "" && req.Buildpack != nil {
+		writeError(w, http.StatusBadRequest, "dockerfile and buildpack can't both be set")
+		return
+	}
+	if req.Image != "" && !strings.Contains(req.Image, "@sha256:") {
+		writeError(w, http.StatusUnprocessableEntity, "image %q is not pinned to a digest", req.Image)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	source := req.Image
+	if req.ContextID != "" {
+		if _, ok := s.contexts[req.ContextID]; !ok {
+			writeError(w, http.StatusBadRequest, "build context %q not found", req.ContextID)
+			return
+		}
+		source = req.ContextID
+	}
+
+	release := a.release("Deploy " + source)
+	deployment := api.Deployment{
+		ID:        fmt.Sprintf("dep_%s_%d", a.Name, len(a.deployments)+1),
+		Status:    api.DeploymentStatusSucceeded,
+		ReleaseID: release.ID,
+		CreatedAt: time.Now().UTC(),
+	}
+	if req.Strategy != nil {
+		a.startRollout(&deployment, *req.Strategy)
+	}
+	a.deployments = append([]api.Deployment{deployment}, a.deployments...)
+	a.deployRequests = append([]api.DeployRequest{req}, a.deployRequests...)
+	writeJSON(w, http.StatusCreated, deployment)
+}
+
+// registryToken issues registry credentials for the namespace of the
+// organization of the request, or of the user.
+func (s *Server) registryToken(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.user(w, r)
+	if !ok {
+		return
+	}
+	namespace := r.Header.Get("a0org")
+	if namespace == "" {
+		namespace = user.Username
+	}
+	writeJSON(w, http.StatusCreated, api.RegistryCredentials{
+		Registry:  Registry,
+		Namespace: namespace,
+		Username:  user.Username,
+		Password:  RegistryPassword(user.Username),
+		ExpiresAt: time.Now().Add(time.Hour).UTC(),
+	})
+}
+
+func digestOf(data []byte) string {
+	return "sha256:" + partOf(0, data).SHA256
+}
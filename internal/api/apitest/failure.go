@@ -0,0 +1,82 @@
+package apitest
+
+import (
+	"net/http"
+	"time"
+)
+
+// Failure describes how requests matching a pattern fail.
+type Failure struct {
+	// Status is the status code of the response, with Message as the API
+	// error.
+	Status  int
+	Message string
+	// Delay is waited before responding, e.g. to trigger client timeouts.
+	// A Failure with only a Delay responds normally afterwards.
+	Delay time.Duration
+	// Drop closes the connection without a response.
+	Drop bool
+	// Times is how many requests fail, all of them if 0.
+	Times int
+
+	hits int
+}
+
+// Inject makes requests matching pattern fail, including those to routes
+// programmed with Handle. Patterns are those of http.ServeMux. Injecting
+// again for the same pattern replaces the previous Failure.
+func (s *Server) Inject(pattern string, f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.failure[pattern]; !ok {
+		// The handler is never called, the mux is only used for matching.
+		s.failures.HandleFunc(pattern, http.NotFound)
+	}
+	s.failure[pattern] = &f
+}
+
+// injectFailure applies the Failure matching r, if any, and reports
+// whether the request was handled.
+func (s *Server) injectFailure(w http.ResponseWriter, r *http.Request) bool {
+	_, pattern := s.failures.Handler(r)
+	if pattern == "" {
+		return false
+	}
+
+	s.mu.Lock()
+	f, ok := s.failure[pattern]
+	if !ok || (f.Times > 0 && f.hits >= f.Times) {
+		s.mu.Unlock()
+		return false
+	}
+	f.hits++
+	failure := *f
+	s.mu.Unlock()
+
+	if failure.Delay > 0 {
+		select {
+		case <-time.After(failure.Delay):
+		case <-r.Context().Done():
+			return true
+		}
+	}
+
+	switch {
+	case failure.Drop:
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				_ = conn.Close()
+				return true
+			}
+		}
+		panic(http.ErrAbortHandler)
+	case failure.Status != 0:
+		message := failure.Message
+		if message == "" {
+			message = http.StatusText(failure.Status)
+		}
+		writeError(w, failure.Status, "%s", message)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package apitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// LogLine is a line of output of an app instance, streamed as one JSON
+// object per line.
+type LogLine struct {
+	Time     time.Time `json:"time"`
+	Instance string    `json:"instance"`
+	Region   string    `json:"region"`
+	Message  string    `json:"message"`
+}
+
+// AddLogs appends lines to the logs of an app, sending them to the
+// clients following them.
+func (s *Server) AddLogs(name string, lines ...LogLine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.apps[name]
+	if !ok {
+		return
+	}
+	for _, line := range lines {
+		if line.Time.IsZero() {
+			line.Time = time.Now().UTC()
+		}
+		a.logs = append(a.logs, line)
+		for _, tail := range a.tails {
+			select {
+			case tail <- line:
+			default:
+				// Slow followers miss lines rather than blocking tests.
+			}
+		}
+	}
+}
+
+// streamLogs writes the logs of the app. With follow=true, new lines are
+// streamed until the client disconnects or the server is closed.
+func (s *Server) streamLogs(w http.ResponseWriter, r *http.Request, a *app) {
+	follow := r.URL.Query().Get("follow") == "true"
+
+	s.mu.Lock()
+	lines := append([]LogLine(nil), a.logs...)
+	var tail chan LogLine
+	if follow {
+		tail = make(chan LogLine, 64)
+		a.tails = append(a.tails, tail)
+	}
+	s.mu.Unlock()
+	if follow {
+		defer s.untail(a, tail)
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	for _, line := range lines {
+		if err := enc.Encode(line); err != nil {
+			return
+		}
+	}
+	flush()
+	if !follow {
+		return
+	}
+
+	for {
+		select {
+		case line := <-tail:
+			if err := enc.Encode(line); err != nil {
+				return
+			}
+			flush()
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *Server) untail(a *app, tail chan LogLine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range a.tails {
+		if t == tail {
+			a.tails = append(a.tails[:i], a.tails[i+1:]...)
+			return
+		}
+	}
+}
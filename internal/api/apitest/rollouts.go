@@ -0,0 +1,187 @@
+package apitest
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+)
+
+// RolloutInstances is the number of instances apps run in the fake, which
+// deployments with a strategy replace.
+const RolloutInstances = 4
+
+// rollout is the progress of a deployment with a strategy: the states it
+// goes through, one more every time it is polled.
+type rollout struct {
+	steps []api.Deployment
+	// promoted are the states of a canary once promoted.
+	promoted []api.Deployment
+}
+
+func (s *Server) registerRolloutRoutes() {
+	s.routes.HandleFunc("GET /v1/apps/{app}/deploys", s.withApp(s.listDeploys))
+	s.routes.HandleFunc("GET /v1/apps/{app}/deploys/{id}", s.withApp(s.getDeploy))
+	s.routes.HandleFunc("POST /v1/apps/{app}/deploys/{id}/promote", s.withApp(s.promoteDeploy))
+	s.routes.HandleFunc("POST /v1/apps/{app}/deploys/{id}/abort", s.withApp(s.abortDeploy))
+}
+
+// FailHealthChecks makes the new instances of the next deployments of an
+// app fail their health checks, or pass them again.
+func (s *Server) FailHealthChecks(name string, fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a, ok := s.apps[name]; ok {
+		a.unhealthy = fail
+	}
+}
+
+// startRollout sets the first state of a deployment with a strategy, and
+// plans the next ones.
+func (a *app) startRollout(d *api.Deployment, strategy api.DeployStrategy) {
+	d.Status = api.DeploymentStatusDeploying
+	d.Strategy = strategy.Type
+	d.Rollout = &api.Rollout{OldInstances: RolloutInstances}
+
+	at := func(status string, oldCount, newCount, healthy, traffic int) api.Deployment {
+		state := *d
+		state.Status = status
+		state.Rollout = &api.Rollout{OldInstances: oldCount, NewInstances: newCount, HealthyNewInstances: healthy, TrafficPercent: traffic}
+		return state
+	}
+	const n = RolloutInstances
+
+	var r rollout
+	switch {
+	case a.unhealthy:
+		failed := at(api.DeploymentStatusFailed, n, 1, 0, 0)
+		failed.Message = "health checks of the new instances failed"
+		if strategy.AutoRollback {
+			failed = at(api.DeploymentStatusRolledBack, n, 0, 0, 0)
+			failed.Message = "health checks of the new instances failed, rolled back"
+		}
+		r.steps = []api.Deployment{at(api.DeploymentStatusDeploying, n, 1, 0, 0), failed}
+	case strategy.Type == api.StrategyCanary:
+		canaries := max(1, (n*strategy.CanaryPercent+99)/100)
+		r.steps = []api.Deployment{
+			at(api.DeploymentStatusDeploying, n, canaries, canaries, strategy.CanaryPercent),
+			at(api.DeploymentStatusPaused, n, canaries, canaries, strategy.CanaryPercent),
+		}
+		r.promoted = []api.Deployment{
+			at(api.DeploymentStatusDeploying, n, n, n, strategy.CanaryPercent),
+			at(api.DeploymentStatusSucceeded, 0, n, n, 100),
+		}
+	case strategy.Type == api.StrategyBlueGreen:
+		r.steps = []api.Deployment{
+			at(api.DeploymentStatusDeploying, n, n/2, 0, 0),
+			at(api.DeploymentStatusDeploying, n, n, n, 0),
+			at(api.DeploymentStatusSucceeded, 0, n, n, 100),
+		}
+	default:
+		surge := 1
+		if v, ok := strings.CutSuffix(strategy.MaxSurge, "%"); ok {
+			percent, _ := strconv.Atoi(v)
+			surge = max(1, (n*percent+99)/100)
+		} else if v, err := strconv.Atoi(strategy.MaxSurge); err == nil && v > 0 {
+			surge = v
+		}
+		for started := surge; ; started += surge {
+			started = min(started, n)
+			if started == n {
+				r.steps = append(r.steps, at(api.DeploymentStatusSucceeded, 0, n, n, 100))
+				break
+			}
+			r.steps = append(r.steps, at(api.DeploymentStatusDeploying, n-started, started, started, started*100/n))
+		}
+	}
+
+	if a.rollouts == nil {
+		a.rollouts = map[string]*rollout{}
+	}
+	a.rollouts[d.ID] = &r
+}
+
+// deployment returns the index of a deployment, writing an error if it
+// doesn't exist.
+func (a *app) deployment(w http.ResponseWriter, id string) (int, bool) {
+	for i, d := range a.deployments {
+		if d.ID == id {
+			return i, true
+		}
+	}
+	writeError(w, http.StatusNotFound, "deployment %s not found", id)
+	return 0, false
+}
+
+func (s *Server) listDeploys(w http.ResponseWriter, _ *http.Request, a *app) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deployments := append([]api.Deployment{}, a.deployments...)
+	slices.Reverse(deployments)
+	writeJSON(w, http.StatusOK, map[string][]api.Deployment{"deployments": deployments})
+}
+
+// getDeploy returns the next state of the rollout of the deployment.
+func (s *Server) getDeploy(w http.ResponseWriter, r *http.Request, a *app) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := a.deployment(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	if ro := a.rollouts[a.deployments[i].ID]; ro != nil && len(ro.steps) > 0 {
+		a.deployments[i], ro.steps = ro.steps[0], ro.steps[1:]
+	}
+	writeJSON(w, http.StatusOK, a.deployments[i])
+}
+
+func (s *Server) promoteDeploy(w http.ResponseWriter, r *http.Request, a *app) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := a.deployment(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	d := a.deployments[i]
+	if d.Status != api.DeploymentStatusPaused {
+		writeError(w, http.StatusConflict, "deployment %s is %s, only paused canaries can be promoted", d.ID, d.Status)
+		return
+	}
+	ro := a.rollouts[d.ID]
+	a.deployments[i], ro.steps = ro.promoted[0], ro.promoted[1:]
+	writeJSON(w, http.StatusOK, a.deployments[i])
+}
+
+func (s *Server) abortDeploy(w http.ResponseWriter, r *http.Request, a *app) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := a.deployment(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	d := a.deployments[i]
+	if d.Done() {
+		writeError(w, http.StatusConflict, "deployment %s is already %s", d.ID, d.Status)
+		return
+	}
+	// The traffic goes back to the old instances before the new ones stop.
+	d.Status = api.DeploymentStatusDeploying
+	d.Message = "aborting, rolling back"
+	rolledBack := &api.Rollout{OldInstances: RolloutInstances}
+	if d.Rollout != nil {
+		rolledBack.NewInstances, rolledBack.HealthyNewInstances = d.Rollout.NewInstances, d.Rollout.HealthyNewInstances
+	}
+	d.Rollout = rolledBack
+	a.deployments[i] = d
+	aborted := d
+	aborted.Status = api.DeploymentStatusAborted
+	aborted.Message = "aborted, rolled back"
+	aborted.Rollout = &api.Rollout{OldInstances: RolloutInstances}
+	if a.rollouts == nil {
+		a.rollouts = map[string]*rollout{}
+	}
+	a.rollouts[d.ID] = &rollout{steps: []api.Deployment{aborted}}
+	writeJSON(w, http.StatusOK, d)
+}
@@ -0,0 +1,209 @@
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+)
+
+// Release statuses set by the fake.
+const ReleaseStatusSucceeded = "succeeded"
+
+func (s *Server) registerRoutes() {
+	s.routes.HandleFunc("GET /v1/auth/validate", s.validate)
+	s.routes.HandleFunc("POST /v1/auth/invalidate", s.invalidate)
+	s.routes.HandleFunc("GET /v1/user", s.getUser)
+	s.routes.HandleFunc("GET /v1/apps", s.listApps)
+	s.routes.HandleFunc("GET /v1/apps/{app}/releases", s.withApp(s.listReleases))
+	s.routes.HandleFunc("GET /v1/apps/{app}/env", s.withApp(s.listEnv))
+	s.routes.HandleFunc("PATCH /v1/apps/{app}/env", s.withApp(s.updateEnv))
+	s.routes.HandleFunc("POST /v1/apps/{app}/env/deploy", s.withApp(s.deploy))
+	s.routes.HandleFunc("GET /v1/apps/{app}/logs", s.withApp(s.streamLogs))
+	s.routes.HandleFunc("GET /v1/orgs", s.listOrgs)
+	s.routes.HandleFunc("GET /v1/orgs/{org}", s.getOrg)
+	s.routes.HandleFunc("GET /v1/regions", s.listRegions)
+	s.routes.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "no route for %s %s", r.Method, r.URL.Path)
+	})
+}
+
+func (s *Server) validate(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.user(w, r); !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+// invalidate revokes all the tokens of the user.
+func (s *Server) invalidate(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.user(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	for token, u := range s.users {
+		if u.UserID == user.UserID {
+			delete(s.users, token)
+		}
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]int64{"validFrom": time.Now().Unix()})
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.user(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// listApps lists the apps of the organization of the request, or the
+// personal ones.
+func (s *Server) listApps(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.user(w, r); !ok {
+		return
+	}
+	org := r.Header.Get("a0org")
+
+	s.mu.Lock()
+	apps := []api.App{}
+	for _, name := range s.appNames {
+		if a := s.apps[name]; a.Org == org {
+			apps = append(apps, a.App)
+		}
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string][]api.App{"apps": apps})
+}
+
+// withApp authenticates the request and looks up the app of its path.
+// Handlers lock the server to access the app.
+func (s *Server) withApp(h func(w http.ResponseWriter, r *http.Request, a *app)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := s.user(w, r); !ok {
+			return
+		}
+		name := r.PathValue("app")
+
+		s.mu.Lock()
+		a, ok := s.apps[name]
+		s.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusNotFound, "app %s not found", name)
+			return
+		}
+		h(w, r, a)
+	}
+}
+
+func (s *Server) listReleases(w http.ResponseWriter, _ *http.Request, a *app) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	releases := append([]api.Release{}, a.releases...)
+	writeJSON(w, http.StatusOK, map[string][]api.Release{"releases": releases})
+}
+
+func (s *Server) listEnv(w http.ResponseWriter, _ *http.Request, a *app) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vars := []api.EnvVar{}
+	for name, value := range a.env {
+		vars = append(vars, api.EnvVar{Name: name, Value: value})
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	writeJSON(w, http.StatusOK, map[string][]api.EnvVar{"env": vars})
+}
+
+// updateEnv applies the change right away. Unless staged, it creates a
+// release like a redeploy would.
+func (s *Server) updateEnv(w http.ResponseWriter, r *http.Request, a *app) {
+	var change api.EnvChange
+	if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, value := range change.Set {
+		a.env[name] = value
+	}
+	for _, name := range change.Unset {
+		delete(a.env, name)
+	}
+
+	if change.Stage {
+		a.staged = true
+		writeJSON(w, http.StatusOK, api.EnvChangeResult{Staged: true})
+		return
+	}
+	release := a.release("Update env vars")
+	writeJSON(w, http.StatusOK, api.EnvChangeResult{ReleaseID: release.ID})
+}
+
+// deploy releases the staged env changes.
+func (s *Server) deploy(w http.ResponseWriter, _ *http.Request, a *app) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !a.staged {
+		writeError(w, http.StatusConflict, "no staged changes to deploy for %s", a.Name)
+		return
+	}
+	release := a.release("Deploy staged changes")
+	writeJSON(w, http.StatusOK, api.EnvChangeResult{ReleaseID: release.ID})
+}
+
+// release records a new release of the app.
+func (a *app) release(description string) api.Release {
+	version := len(a.releases) + 1
+	release := api.Release{
+		ID:          fmt.Sprintf("rel_%s_%d", a.Name, version),
+		Version:     version,
+		Description: description,
+		Status:      ReleaseStatusSucceeded,
+		CreatedAt:   time.Now().UTC(),
+	}
+	a.releases = slices.Insert(a.releases, 0, release)
+	a.staged = false
+	return release
+}
+
+func (s *Server) listOrgs(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.user(w, r); !ok {
+		return
+	}
+	s.mu.Lock()
+	orgs := append([]api.Org{}, s.orgs...)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string][]api.Org{"orgs": orgs})
+}
+
+func (s *Server) getOrg(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.user(w, r); !ok {
+		return
+	}
+	slug := r.PathValue("org")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, o := range s.orgs {
+		if o.Slug == slug {
+			writeJSON(w, http.StatusOK, o)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "organization %s not found", slug)
+}
+
+// listRegions doesn't need authentication.
+func (s *Server) listRegions(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	regions := append([]api.Region{}, s.regions...)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string][]api.Region{"regions": regions})
+}
@@ -0,0 +1,254 @@
+// Package apitest provides an in-process fake of the a0 API, to run the
+// client and commands against in tests and offline demos.
+//
+// The fake keeps users, apps, releases, env vars, logs, uploads, build
+// contexts and deployments in memory and serves them like the real API.
+// Exec sessions and proxy tunnels run against fake instances.
+// Routes it doesn't know about can be programmed with Handle, and any route
+// can be made to fail with Inject.
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+)
+
+// Credentials of the user every Server starts with.
+const (
+	DefaultUsername = "jane"
+	DefaultToken    = "test-token"
+)
+
+// Server is a fake a0 API listening on a local port.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	users    map[string]api.UserInfo // by token
+	apps     map[string]*app
+	appNames []string
+	orgs     []api.Org
+	regions  []api.Region
+	uploads  map[string]*upload
+	// uploadSeq numbers uploads, which can be deleted.
+	uploadSeq int
+	blobs     map[string][]byte // by digest
+	contexts  map[string]*buildContext
+	tunnels   int
+	requests  []Request
+
+	routes    *http.ServeMux
+	overrides *http.ServeMux
+	failures  *http.ServeMux
+	failure   map[string]*Failure // by pattern
+
+	// done is closed when the server shuts down, to end log streams.
+	done     chan struct{}
+	shutdown sync.Once
+}
+
+// app is the state of an app.
+type app struct {
+	api.App
+	env      map[string]string
+	releases []api.Release
+	// staged is set when env changes were staged without a release.
+	staged bool
+	logs   []LogLine
+	tails  []chan LogLine
+
+	deployments    []api.Deployment
+	deployRequests []api.DeployRequest
+	rollouts       map[string]*rollout // by deployment ID
+	// unhealthy fails the health checks of new instances.
+	unhealthy bool
+}
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   []byte
+}
+
+// New starts a Server which is closed at the end of the test.
+func New(t testing.TB) *Server {
+	t.Helper()
+	s := NewServer()
+	t.Cleanup(s.Close)
+	return s
+}
+
+// NewServer starts a Server with the default user. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		users:     map[string]api.UserInfo{},
+		apps:      map[string]*app{},
+		uploads:   map[string]*upload{},
+		blobs:     map[string][]byte{},
+		contexts:  map[string]*buildContext{},
+		routes:    http.NewServeMux(),
+		overrides: http.NewServeMux(),
+		failures:  http.NewServeMux(),
+		failure:   map[string]*Failure{},
+		done:      make(chan struct{}),
+	}
+	s.registerRoutes()
+	s.registerUploadRoutes()
+	s.registerDeployRoutes()
+	s.registerRolloutRoutes()
+	s.registerSessionRoutes()
+	s.AddUser(DefaultUsername, DefaultToken)
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close ends the log streams and shuts down the server.
+func (s *Server) Close() {
+	s.shutdown.Do(func() { close(s.done) })
+	s.Server.Close()
+}
+
+// AddUser registers a user authenticated by token.
+func (s *Server) AddUser(username, token string) api.UserInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := api.UserInfo{UserID: fmt.Sprintf("user_%d", len(s.users)+1), Username: username}
+	s.users[token] = user
+	return user
+}
+
+// AddApp creates an app with no release.
+func (s *Server) AddApp(a api.App) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now().UTC()
+	}
+	if _, ok := s.apps[a.Name]; !ok {
+		s.appNames = append(s.appNames, a.Name)
+	}
+	s.apps[a.Name] = &app{App: a, env: map[string]string{}}
+}
+
+// AddOrg adds an organization the users belong to.
+func (s *Server) AddOrg(org api.Org) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orgs = append(s.orgs, org)
+}
+
+// AddRegion adds a region apps can be placed in.
+func (s *Server) AddRegion(region api.Region) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.regions = append(s.regions, region)
+}
+
+// Env returns the env vars of an app.
+func (s *Server) Env(name string) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := map[string]string{}
+	if a, ok := s.apps[name]; ok {
+		for k, v := range a.env {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Releases returns the releases of an app, latest first.
+func (s *Server) Releases(name string) []api.Release {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.apps[name]
+	if !ok {
+		return nil
+	}
+	return append([]api.Release(nil), a.releases...)
+}
+
+// Requests returns the requests received so far, oldest first.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Handle serves requests matching pattern with h instead of the built-in
+// routes. Patterns are those of http.ServeMux, e.g. "GET /v1/apps/{app}".
+func (s *Server) Handle(pattern string, h http.HandlerFunc) {
+	s.overrides.HandleFunc(pattern, h)
+}
+
+// HandleJSON responds to requests matching pattern with body encoded as
+// JSON.
+func (s *Server) HandleJSON(pattern string, status int, body any) {
+	s.Handle(pattern, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, status, body)
+	})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	s.mu.Unlock()
+
+	if s.injectFailure(w, r) {
+		return
+	}
+	if h, pattern := s.overrides.Handler(r); pattern != "" {
+		h.ServeHTTP(w, r)
+		return
+	}
+	s.routes.ServeHTTP(w, r)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError responds with an error in the format of the API.
+func writeError(w http.ResponseWriter, status int, format string, a ...any) {
+	writeJSON(w, status, api.ErrorResponseDetails{Error: fmt.Sprintf(format, a...)})
+}
+
+// user returns the user authenticated by the request, or responds with
+// an error.
+func (s *Server) user(w http.ResponseWriter, r *http.Request) (api.UserInfo, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if ok {
+		s.mu.Lock()
+		user, found := s.users[token]
+		s.mu.Unlock()
+		if found {
+			return user, true
+		}
+	}
+	writeError(w, http.StatusUnauthorized, "invalid or expired token")
+	return api.UserInfo{}, false
+}
@@ -0,0 +1,207 @@
+package apitest_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/api/apitest"
+)
+
+func newClient(t *testing.T, s *apitest.Server, token string) *api.Client {
+	t.Helper()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api.NewClient(u, token, "")
+}
+
+func TestValidate(t *testing.T) {
+	s := apitest.New(t)
+	s.AddUser("bob", "bob-token")
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"default user", apitest.DefaultToken, true},
+		{"added user", "bob-token", true},
+		{"unknown token", "nope", false},
+		{"no token", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, _ := newClient(t, s, tt.token).Tokens.Validate()
+			if valid != tt.valid {
+				t.Errorf("Validate() = %v, want %v", valid, tt.valid)
+			}
+		})
+	}
+}
+
+func TestGetUserAndInvalidate(t *testing.T) {
+	s := apitest.New(t)
+	client := newClient(t, s, apitest.DefaultToken)
+
+	user, err := client.Users.GetUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != apitest.DefaultUsername {
+		t.Errorf("Username = %q, want %q", user.Username, apitest.DefaultUsername)
+	}
+
+	if _, err := client.Tokens.Invalidate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Users.GetUser(); err == nil {
+		t.Error("GetUser() succeeded with an invalidated token")
+	}
+}
+
+func TestAppsAreScopedToOrg(t *testing.T) {
+	s := apitest.New(t)
+	s.AddApp(api.App{Name: "mine"})
+	s.AddApp(api.App{Name: "theirs", Org: "acme"})
+
+	tests := []struct {
+		org  string
+		want string
+	}{
+		{"", "mine"},
+		{"acme", "theirs"},
+	}
+	for _, tt := range tests {
+		client := newClient(t, s, apitest.DefaultToken)
+		client.Org = tt.org
+		apps, err := client.Apps.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(apps) != 1 || apps[0].Name != tt.want {
+			t.Errorf("org %q: apps = %+v, want only %s", tt.org, apps, tt.want)
+		}
+	}
+}
+
+func TestEnvChangesCreateReleases(t *testing.T) {
+	s := apitest.New(t)
+	s.AddApp(api.App{Name: "web"})
+	client := newClient(t, s, apitest.DefaultToken)
+
+	res, err := client.Env.Update("web", api.EnvChange{Set: map[string]string{"A": "1"}, Stage: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Staged || len(s.Releases("web")) != 0 {
+		t.Fatalf("staged change created a release: %+v", res)
+	}
+
+	res, err = client.Env.Deploy("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	releases := s.Releases("web")
+	if len(releases) != 1 || releases[0].ID != res.ReleaseID {
+		t.Fatalf("releases = %+v, want one with ID %s", releases, res.ReleaseID)
+	}
+	if got := s.Env("web")["A"]; got != "1" {
+		t.Errorf("A = %q, want 1", got)
+	}
+
+	if _, err := client.Env.Deploy("web"); err == nil {
+		t.Error("Deploy() without staged changes succeeded")
+	}
+	if _, err := client.Env.List("missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("List() of missing app: err = %v", err)
+	}
+}
+
+func TestFollowLogs(t *testing.T) {
+	s := apitest.New(t)
+	s.AddApp(api.App{Name: "web"})
+	s.AddLogs("web", apitest.LogLine{Message: "first"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, s.URL+"/v1/apps/web/logs?follow=true", nil)
+	req.Header.Set("Authorization", "Bearer "+apitest.DefaultToken)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	lines := bufio.NewScanner(res.Body)
+	next := func() string {
+		t.Helper()
+		if !lines.Scan() {
+			t.Fatalf("stream ended: %v", lines.Err())
+		}
+		var line apitest.LogLine
+		if err := json.Unmarshal(lines.Bytes(), &line); err != nil {
+			t.Fatal(err)
+		}
+		return line.Message
+	}
+
+	if got := next(); got != "first" {
+		t.Errorf("first line = %q", got)
+	}
+	s.AddLogs("web", apitest.LogLine{Message: "second"})
+	if got := next(); got != "second" {
+		t.Errorf("followed line = %q", got)
+	}
+}
+
+func TestInject(t *testing.T) {
+	tests := []struct {
+		name    string
+		failure apitest.Failure
+		want    []bool // success of consecutive requests
+	}{
+		{"always", apitest.Failure{Status: http.StatusInternalServerError}, []bool{false, false}},
+		{"once", apitest.Failure{Status: http.StatusServiceUnavailable, Times: 1}, []bool{false, true}},
+		{"drop", apitest.Failure{Drop: true, Times: 1}, []bool{false, true}},
+		{"delay only", apitest.Failure{Delay: 10 * time.Millisecond}, []bool{true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := apitest.New(t)
+			s.Inject("GET /v1/user", tt.failure)
+			client := newClient(t, s, apitest.DefaultToken)
+			for i, want := range tt.want {
+				_, err := client.Users.GetUser()
+				if (err == nil) != want {
+					t.Errorf("request %d: err = %v, want success %v", i, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleOverridesRoutes(t *testing.T) {
+	s := apitest.New(t)
+	s.HandleJSON("GET /v1/user", http.StatusOK, api.UserInfo{Username: "programmed"})
+
+	user, err := newClient(t, s, "any").Users.GetUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "programmed" {
+		t.Errorf("Username = %q, want programmed", user.Username)
+	}
+
+	reqs := s.Requests()
+	if len(reqs) != 1 || reqs[0].Path != "/v1/user" || reqs[0].Header.Get("Authorization") != "Bearer any" {
+		t.Errorf("requests = %+v", reqs)
+	}
+}
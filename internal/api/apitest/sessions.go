@@ -0,0 +1,128 @@
+package apitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/coder/websocket"
+)
+
+// Channels of the messages of exec sessions, see api.ExecSession.
+const (
+	execStdin  byte = 0
+	execStdout byte = 1
+	execStderr byte = 2
+	execExit   byte = 4
+)
+
+func (s *Server) registerSessionRoutes() {
+	s.routes.HandleFunc("GET /v1/apps/{app}/exec", s.withApp(s.exec))
+	s.routes.HandleFunc("GET /v1/apps/{app}/proxy", s.withApp(s.proxy))
+}
+
+// OpenTunnels returns the number of proxy tunnels still connected.
+func (s *Server) OpenTunnels() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tunnels
+}
+
+// exec runs a few commands in a fake instance: echo prints its arguments,
+// cat copies its input to its output, and exit exits with its argument.
+// Other commands aren't found.
+func (s *Server) exec(w http.ResponseWriter, r *http.Request, _ *app) {
+	command := r.URL.Query()["command"]
+	if len(command) == 0 {
+		writeError(w, http.StatusBadRequest, "command is required")
+		return
+	}
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = conn.CloseNow()
+	}()
+	ctx := r.Context()
+
+	send := func(channel byte, payload []byte) bool {
+		return conn.Write(ctx, websocket.MessageBinary, append([]byte{channel}, payload...)) == nil
+	}
+	exit := func(code int) {
+		data, _ := json.Marshal(map[string]int{"code": code})
+		send(execExit, data)
+	}
+
+	switch command[0] {
+	case "echo":
+		if send(execStdout, []byte(strings.Join(command[1:], " ")+"\n")) {
+			exit(0)
+		}
+	case "cat":
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			if len(data) == 0 || data[0] != execStdin {
+				continue
+			}
+			// An empty message closes the input.
+			if len(data) == 1 {
+				exit(0)
+				return
+			}
+			if !send(execStdout, data[1:]) {
+				return
+			}
+		}
+	case "exit":
+		code := 0
+		if len(command) > 1 {
+			code, _ = strconv.Atoi(command[1])
+		}
+		exit(code)
+	default:
+		if send(execStderr, []byte(command[0]+": command not found\n")) {
+			exit(127)
+		}
+	}
+}
+
+// proxy tunnels to a fake service echoing what it receives, until the
+// client closes the tunnel.
+func (s *Server) proxy(w http.ResponseWriter, r *http.Request, _ *app) {
+	if _, err := strconv.Atoi(r.URL.Query().Get("port")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid port")
+		return
+	}
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = conn.CloseNow()
+	}()
+
+	s.mu.Lock()
+	s.tunnels++
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.tunnels--
+		s.mu.Unlock()
+	}()
+
+	ctx := r.Context()
+	for {
+		typ, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+		if err := conn.Write(ctx, typ, data); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package apitest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+)
+
+// uploadTTL is how long uploads can be resumed.
+const uploadTTL = 24 * time.Hour
+
+// upload is the state of an upload.
+type upload struct {
+	api.Upload
+	parts map[int][]byte
+	data  []byte
+}
+
+func (s *Server) registerUploadRoutes() {
+	s.routes.HandleFunc("POST /v1/uploads", s.initiateUpload)
+	s.routes.HandleFunc("GET /v1/uploads/{id}", s.withUpload(s.getUpload))
+	s.routes.HandleFunc("PUT /v1/uploads/{id}/parts/{n}", s.withUpload(s.putPart))
+	s.routes.HandleFunc("POST /v1/uploads/{id}/complete", s.withUpload(s.completeUpload))
+	s.routes.HandleFunc("DELETE /v1/uploads/{id}", s.withUpload(s.abortUpload))
+}
+
+// Uploaded returns the content of a completed upload.
+func (s *Server) Uploaded(id string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.uploads[id]
+	if !ok || u.State != api.UploadStateCompleted {
+		return nil, false
+	}
+	return bytes.Clone(u.data), true
+}
+
+// withUpload authenticates the request and looks up the upload of its
+// path. Handlers are called with the server locked.
+func (s *Server) withUpload(h func(w http.ResponseWriter, r *http.Request, u *upload)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := s.user(w, r); !ok {
+			return
+		}
+		id := r.PathValue("id")
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		u, ok := s.uploads[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, "upload %s not found", id)
+			return
+		}
+		h(w, r, u)
+	}
+}
+
+func (s *Server) initiateUpload(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.user(w, r); !ok {
+		return
+	}
+	var req api.InitiateUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body: %v", err)
+		return
+	}
+	if req.PartSize <= 0 || req.Size < 0 || req.SHA256 == "" {
+		writeError(w, http.StatusBadRequest, "size, part size and checksum are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploadSeq++
+	u := &upload{
+		Upload: api.Upload{
+			ID:        fmt.Sprintf("upl_%d", s.uploadSeq),
+			Name:      req.Name,
+			Size:      req.Size,
+			SHA256:    req.SHA256,
+			PartSize:  req.PartSize,
+			State:     api.UploadStatePending,
+			ExpiresAt: time.Now().UTC().Add(uploadTTL),
+		},
+		parts: map[int][]byte{},
+	}
+	s.uploads[u.ID] = u
+	writeJSON(w, http.StatusCreated, u.status())
+}
+
+func (s *Server) getUpload(w http.ResponseWriter, _ *http.Request, u *upload) {
+	writeJSON(w, http.StatusOK, u.status())
+}
+
+// putPart stores a part after verifying its checksum.
+func (s *Server) putPart(w http.ResponseWriter, r *http.Request, u *upload) {
+	n, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil || n < 1 {
+		writeError(w, http.StatusBadRequest, "invalid part number %q", r.PathValue("n"))
+		return
+	}
+	if u.State != api.UploadStatePending {
+		writeError(w, http.StatusConflict, "upload %s is %s", u.ID, u.State)
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "could not read part: %v", err)
+		return
+	}
+	if int64(len(data)) > u.PartSize {
+		writeError(w, http.StatusRequestEntityTooLarge, "part %d is larger than %d bytes", n, u.PartSize)
+		return
+	}
+	part := partOf(n, data)
+	if want := r.Header.Get(api.HeaderContentSHA256); want != "" && want != part.SHA256 {
+		// Corrupted in transit, the client sends it again.
+		writeError(w, http.StatusUnprocessableEntity, "checksum of part %d doesn't match", n)
+		return
+	}
+	u.parts[n] = data
+	writeJSON(w, http.StatusOK, part)
+}
+
+// completeUpload assembles the listed parts and verifies the checksum of
+// the whole upload.
+func (s *Server) completeUpload(w http.ResponseWriter, r *http.Request, u *upload) {
+	var req struct {
+		Parts []api.UploadPart `json:"parts"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body: %v", err)
+		return
+	}
+	if u.State == api.UploadStateCompleted {
+		writeJSON(w, http.StatusOK, u.status())
+		return
+	}
+
+	var data []byte
+	for i, p := range req.Parts {
+		part, ok := u.parts[p.Number]
+		if !ok || p.Number != i+1 {
+			writeError(w, http.StatusBadRequest, "part %d is missing", i+1)
+			return
+		}
+		if partOf(p.Number, part).SHA256 != p.SHA256 {
+			writeError(w, http.StatusBadRequest, "checksum of part %d doesn't match", p.Number)
+			return
+		}
+		data = append(data, part...)
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != u.SHA256 || int64(len(data)) != u.Size {
+		writeError(w, http.StatusUnprocessableEntity, "upload %s doesn't match its checksum", u.ID)
+		return
+	}
+
+	u.data = data
+	u.parts = map[int][]byte{}
+	u.State = api.UploadStateCompleted
+	writeJSON(w, http.StatusOK, u.status())
+}
+
+func (s *Server) abortUpload(w http.ResponseWriter, _ *http.Request, u *upload) {
+	delete(s.uploads, u.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// status returns the upload with the parts received so far.
+func (u *upload) status() api.Upload {
+	status := u.Upload
+	status.Parts = []api.UploadPart{}
+	for n, data := range u.parts {
+		status.Parts = append(status.Parts, partOf(n, data))
+	}
+	sort.Slice(status.Parts, func(i, j int) bool { return status.Parts[i].Number < status.Parts[j].Number })
+	return status
+}
+
+func partOf(n int, data []byte) api.UploadPart {
+	sum := sha256.Sum256(data)
+	return api.UploadPart{Number: n, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])}
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type AppsClient client
+
+// App is an application deployed on a0.
+type App struct {
+	Name      string    `json:"name"`
+	Org       string    `json:"org,omitempty"`
+	Regions   []string  `json:"regions"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (c *AppsClient) List() ([]App, error) {
+	res, err := c.client.Get("/v1/apps", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get apps: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get apps: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[struct{ Apps []App }](res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize apps response: %w", err)
+	}
+
+	return data.Apps, nil
+}
@@ -9,12 +9,12 @@ import (
 )
 
 // IsJWTTokenValid validates token.
-func IsJWTTokenValid(token string) bool {
+func IsJWTTokenValid(config *settings.Settings, token string) bool {
 	if len(token) == 0 {
 		return false
 	}
 
-	client, err := MakeClient(token)
+	client, err := MakeClient(config, token)
 	if err != nil {
 		return false
 	}
@@ -30,8 +30,10 @@ func IsJWTTokenValid(token string) bool {
 var ErrNotLoggedIn = fmt.Errorf(
 	"user not logged in, please login with %s", cli.Emph("a0ctl auth login"))
 
-func GetAccessToken() (string, error) {
-	token, err := envAccessToken()
+// GetAccessToken returns the token of the A0_API_TOKEN env var, or else the
+// one of the active profile.
+func GetAccessToken(config *settings.Settings) (string, error) {
+	token, err := envAccessToken(config)
 	if err != nil {
 		return "", err
 	}
@@ -41,13 +43,8 @@ func GetAccessToken() (string, error) {
 
 	// env has no token, read from config.
 	// env variable takes precedence over config file.
-	config, err := settings.ReadSettings()
-	if err != nil {
-		return "", fmt.Errorf("could not read token from settings file: %w", err)
-	}
-
 	token = config.GetToken()
-	if !IsJWTTokenValid(token) {
+	if !IsJWTTokenValid(config, token) {
 		return "", ErrNotLoggedIn
 	}
 
@@ -55,12 +52,12 @@ func GetAccessToken() (string, error) {
 }
 
 // envAccessToken retrieves the access token from the environment variable.
-func envAccessToken() (string, error) {
+func envAccessToken(config *settings.Settings) (string, error) {
 	token := os.Getenv(settings.EnvAccessToken)
 	if token == "" {
 		return "", nil
 	}
-	if !IsJWTTokenValid(token) {
+	if !IsJWTTokenValid(config, token) {
 		return "", fmt.Errorf("token in %s env var is invalid. Update the env var with a valid value, or unset it to use a token from the configuration file", settings.EnvAccessToken)
 	}
 	return token, nil
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+type BlobsClient client
+
+// BlobEncodingZstd is the Content-Encoding of blobs, compressed with zstd.
+const BlobEncodingZstd = "zstd"
+
+// MaxMissingBlobs is how many digests Missing accepts at once.
+const MaxMissingBlobs = 1000
+
+// Missing returns the digests, of the form sha256:<hex>, the API has no
+// blob for.
+func (c *BlobsClient) Missing(digests []string) ([]string, error) {
+	body, err := marshal(struct {
+		Digests []string `json:"digests"`
+	}{digests})
+	if err != nil {
+		return nil, fmt.Errorf("could not serialize request body: %w", err)
+	}
+
+	res, err := c.client.Post("/v1/blobs/missing", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check blobs: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to check blobs: %w", statusError(res))
+	}
+
+	data, err := unmarshal[struct{ Missing []string }](res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize blobs response: %w", err)
+	}
+
+	return data.Missing, nil
+}
+
+// Put uploads the blob with digest, reading its content compressed with
+// zstd from body until EOF. The API verifies the digest of the decompressed
+// content. Failed requests return a *StatusError telling whether to retry.
+func (c *BlobsClient) Put(ctx context.Context, digest string, body io.Reader) error {
+	req, err := c.client.newRequest("PUT", "/v1/blobs/"+url.PathEscape(digest), body, map[string]string{
+		"Content-Type":     "application/octet-stream",
+		"Content-Encoding": BlobEncodingZstd,
+	})
+	if err != nil {
+		return err
+	}
+
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to upload blob %s: %w", digest, err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to upload blob %s: %w", digest, statusError(res))
+	}
+
+	return nil
+}
+
+// FromUpload stores the content of a completed upload as the blob with
+// digest, for files too large to be sent with Put. The API verifies the
+// checksum of the upload matches the digest.
+func (c *BlobsClient) FromUpload(digest, uploadID string) error {
+	body, err := marshal(struct {
+		UploadID string `json:"uploadId"`
+	}{uploadID})
+	if err != nil {
+		return fmt.Errorf("could not serialize request body: %w", err)
+	}
+
+	res, err := c.client.Post("/v1/blobs/"+url.PathEscape(digest)+"/upload", body)
+	if err != nil {
+		return fmt.Errorf("failed to store blob %s: %w", digest, err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to store blob %s: %w", digest, statusError(res))
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,7 +11,9 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"runtime"
+	"strings"
 
+	"github.com/a0dotrun/a0ctl/internal/buildinfo"
 	"github.com/a0dotrun/a0ctl/internal/settings"
 
 	"github.com/a0dotrun/a0ctl/internal/flags"
@@ -27,12 +30,32 @@ type Client struct {
 	Token      string
 	Username   string
 	CLIVersion string
+	// Org scopes requests to an organization, the user's personal
+	// account if empty.
+	Org string
 
 	// Single instance to be reused by all clients
 	base *client
 
-	Tokens *TokensClient
-	Users  *UsersClient
+	Tokens    *TokensClient
+	Users     *UsersClient
+	Env       *EnvClient
+	Secrets   *SecretsClient
+	Domains   *DomainsClient
+	Scale     *ScaleClient
+	Instances *InstancesClient
+	Regions   *RegionsClient
+	Exec      *ExecClient
+	Proxy     *ProxyClient
+	Volumes   *VolumesClient
+	Orgs      *OrgsClient
+	Apps      *AppsClient
+	Releases  *ReleasesClient
+	Uploads   *UploadsClient
+	Blobs     *BlobsClient
+	Contexts  *ContextsClient
+	Deploys   *DeploysClient
+	Registry  *RegistryClient
 }
 
 // client struct that will be aliases by all other clients
@@ -45,7 +68,7 @@ func NewClient(baseURL *url.URL, token, username string) *Client {
 		BaseURL:    baseURL,
 		Token:      token,
 		Username:   username,
-		CLIVersion: "0.0.1", // FIXME: read from config
+		CLIVersion: buildinfo.Version,
 	}
 
 	c.base = &client{client: c}
@@ -55,39 +78,53 @@ func NewClient(baseURL *url.URL, token, username string) *Client {
 	// otherwise ends up with nil pointer deference panics
 	c.Tokens = (*TokensClient)(c.base)
 	c.Users = (*UsersClient)(c.base)
+	c.Env = (*EnvClient)(c.base)
+	c.Secrets = (*SecretsClient)(c.base)
+	c.Domains = (*DomainsClient)(c.base)
+	c.Scale = (*ScaleClient)(c.base)
+	c.Instances = (*InstancesClient)(c.base)
+	c.Regions = (*RegionsClient)(c.base)
+	c.Exec = (*ExecClient)(c.base)
+	c.Proxy = (*ProxyClient)(c.base)
+	c.Volumes = (*VolumesClient)(c.base)
+	c.Orgs = (*OrgsClient)(c.base)
+	c.Apps = (*AppsClient)(c.base)
+	c.Releases = (*ReleasesClient)(c.base)
+	c.Uploads = (*UploadsClient)(c.base)
+	c.Blobs = (*BlobsClient)(c.base)
+	c.Contexts = (*ContextsClient)(c.base)
+	c.Deploys = (*DeploysClient)(c.base)
+	c.Registry = (*RegistryClient)(c.base)
 
 	return c
 }
 
 // AuthedClient returns authenticated client
-func AuthedClient() (*Client, error) {
-	token, err := GetAccessToken()
+func AuthedClient(config *settings.Settings) (*Client, error) {
+	token, err := GetAccessToken(config)
 	if err != nil {
 		return nil, err
 	}
-	return MakeClient(token)
+	return MakeClient(config, token)
 }
 
 // UnAuthedClient returns un-authenticated client, a client without the token set
-func UnAuthedClient() (*Client, error) {
-	return MakeClient("")
+func UnAuthedClient(config *settings.Settings) (*Client, error) {
+	return MakeClient(config, "")
 }
 
-// MakeClient builds a new API client with the provided token.
-func MakeClient(token string) (*Client, error) {
-	urlStr := settings.GetA0URL()
+// MakeClient builds a new API client with the provided token, for the API
+// URL, user and organization of the settings.
+func MakeClient(config *settings.Settings, token string) (*Client, error) {
+	urlStr := config.A0URL()
 	a0URL, err := url.Parse(urlStr)
 	if err != nil {
 		return nil, fmt.Errorf("error creating a0ctl client: could not parse a0 URL %s: %w", urlStr, err)
 	}
 
-	config, err := settings.ReadSettings()
-	if err != nil {
-		return nil, fmt.Errorf("error creating a0ctl client: could not read settings: %w", err)
-	}
-
-	username := config.GetUsername()
-	return NewClient(a0URL, token, username), nil
+	client := NewClient(a0URL, token, config.GetUsername())
+	client.Org = config.GetOrg()
+	return client, nil
 }
 
 func (c *Client) newRequest(
@@ -108,24 +145,28 @@ func (c *Client) newRequest(
 	if err != nil {
 		return nil, err
 	}
-	if c.Token != "" {
-		req.Header.Add("Authorization", fmt.Sprint("Bearer ", c.Token))
+	c.addHeaders(req.Header)
+	for header, value := range extraHeaders {
+		req.Header.Add(header, value)
 	}
-	req.Header.Add("a0ctlversion", c.CLIVersion)
+	return req, nil
+}
 
-	parsedCliVersion := c.CLIVersion
-	if parsedCliVersion != "dev" {
-		parsedCliVersion = c.CLIVersion[1:] // strip the leading "v"
+// addHeaders adds the headers sent with every API request.
+func (c *Client) addHeaders(h http.Header) {
+	if c.Token != "" {
+		h.Add("Authorization", fmt.Sprint("Bearer ", c.Token))
+	}
+	h.Add("a0ctlversion", c.CLIVersion)
+	if c.Org != "" {
+		h.Add("a0org", c.Org)
 	}
-	req.Header.Add(
+
+	h.Add(
 		"User-Agent",
 		fmt.Sprintf("a0ctl/%s (%s/%s)",
-			parsedCliVersion, runtime.GOOS, runtime.GOARCH),
+			strings.TrimPrefix(c.CLIVersion, "v"), runtime.GOOS, runtime.GOARCH),
 	)
-	for header, value := range extraHeaders {
-		req.Header.Add(header, value)
-	}
-	return req, nil
 }
 
 func (c *Client) do(
@@ -199,9 +240,8 @@ func unmarshal[T any](r *http.Response) (T, error) {
 	return *t, err
 }
 
-// TODO: enable once required
-// func marshal(data any) (io.Reader, error) {
-// 	buf := &bytes.Buffer{}
-// 	err := json.NewEncoder(buf).Encode(data)
-// 	return buf, err
-// }
+func marshal(data any) (io.Reader, error) {
+	buf := &bytes.Buffer{}
+	err := json.NewEncoder(buf).Encode(data)
+	return buf, err
+}
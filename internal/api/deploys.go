@@ -0,0 +1,277 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type ContextsClient client
+
+type DeploysClient client
+
+// ContextManifest lists the files of a build context by content digest.
+// Root is the digest of the Merkle tree of the files, identifying the
+// whole context.
+type ContextManifest struct {
+	Root  string        `json:"root"`
+	Files []ContextFile `json:"files"`
+}
+
+// ContextFile is a file of a build context. Regular files are stored as
+// blobs by Digest, symbolic links only have a Target.
+type ContextFile struct {
+	Path   string `json:"path"`
+	Mode   uint32 `json:"mode"`
+	Size   int64  `json:"size"`
+	Digest string `json:"digest"`
+	Target string `json:"target,omitempty"`
+}
+
+// BuildContext is a build context stored by the API, ready to be built.
+type BuildContext struct {
+	ID    string `json:"id"`
+	Root  string `json:"root"`
+	Files int    `json:"files"`
+	Size  int64  `json:"size"`
+}
+
+// Deployment statuses.
+const (
+	DeploymentStatusPending   = "pending"
+	DeploymentStatusBuilding  = "building"
+	DeploymentStatusDeploying = "deploying"
+	DeploymentStatusSucceeded = "succeeded"
+	DeploymentStatusFailed    = "failed"
+	// DeploymentStatusPaused is a canary waiting to be promoted or aborted.
+	DeploymentStatusPaused     = "paused"
+	DeploymentStatusRolledBack = "rolled_back"
+	DeploymentStatusAborted    = "aborted"
+)
+
+// Deployment strategies.
+const (
+	StrategyRolling   = "rolling"
+	StrategyCanary    = "canary"
+	StrategyBlueGreen = "bluegreen"
+)
+
+// DeployRequest describes what to deploy: either a build context and the
+// path of its Dockerfile or the plan to build it with buildpacks, or an
+// image built beforehand, referenced by digest.
+type DeployRequest struct {
+	ContextID  string     `json:"contextId,omitempty"`
+	Dockerfile string     `json:"dockerfile,omitempty"`
+	Buildpack  *BuildPlan `json:"buildpack,omitempty"`
+	Image      string     `json:"image,omitempty"`
+	// RegistryAuth is set when Image is in a private registry.
+	RegistryAuth *RegistryAuth `json:"registryAuth,omitempty"`
+	// Strategy is the platform's default, rolling, if nil.
+	Strategy *DeployStrategy `json:"strategy,omitempty"`
+}
+
+// DeployStrategy is how the new instances of a deployment replace the
+// running ones. Rolling deploys replace them a few at a time, canaries get
+// CanaryPercent of the traffic until promoted, and blue-green deploys
+// start all the new instances before moving the traffic at once.
+type DeployStrategy struct {
+	Type string `json:"type"`
+	// MaxSurge is how many instances rolling deploys start above the
+	// count, as a number or a percentage like 25%.
+	MaxSurge      string `json:"maxSurge,omitempty"`
+	CanaryPercent int    `json:"canaryPercent,omitempty"`
+	// BakeTimeSeconds is how long new instances must stay healthy before
+	// the traffic moves on to them.
+	BakeTimeSeconds int `json:"bakeTimeSeconds,omitempty"`
+	// AutoRollback rolls back to the running instances when health checks
+	// of the new ones fail.
+	AutoRollback bool `json:"autoRollback"`
+}
+
+// BuildPlan is how to build and run an app without a Dockerfile, detected
+// from the files of its source.
+type BuildPlan struct {
+	Runtime string `json:"runtime"`
+	Version string `json:"version"`
+	// DetectedFrom is the file the runtime was detected from.
+	DetectedFrom string `json:"detectedFrom"`
+	Install      string `json:"install,omitempty"`
+	Build        string `json:"build,omitempty"`
+	Start        string `json:"start"`
+	Port         int    `json:"port"`
+}
+
+// RegistryAuth are the credentials the platform pulls a private image
+// with.
+type RegistryAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Deployment builds and releases a new version of an app.
+type Deployment struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	ReleaseID string    `json:"releaseId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	Strategy  string    `json:"strategy,omitempty"`
+	Rollout   *Rollout  `json:"rollout,omitempty"`
+	// Message tells why a deployment failed or was rolled back.
+	Message string `json:"message,omitempty"`
+}
+
+// Done tells whether the deployment is over, for better or worse.
+func (d Deployment) Done() bool {
+	switch d.Status {
+	case DeploymentStatusSucceeded, DeploymentStatusFailed, DeploymentStatusRolledBack, DeploymentStatusAborted:
+		return true
+	}
+	return false
+}
+
+// Rollout is the progress of a deployment replacing the running instances.
+type Rollout struct {
+	OldInstances        int `json:"oldInstances"`
+	NewInstances        int `json:"newInstances"`
+	HealthyNewInstances int `json:"healthyNewInstances"`
+	// TrafficPercent is the share of the traffic sent to new instances.
+	TrafficPercent int `json:"trafficPercent"`
+}
+
+// Create stores a build context whose blobs have all been uploaded.
+func (c *ContextsClient) Create(manifest ContextManifest) (BuildContext, error) {
+	body, err := marshal(manifest)
+	if err != nil {
+		return BuildContext{}, fmt.Errorf("could not serialize request body: %w", err)
+	}
+
+	res, err := c.client.Post("/v1/contexts", body)
+	if err != nil {
+		return BuildContext{}, fmt.Errorf("failed to create build context: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return BuildContext{}, fmt.Errorf("failed to create build context: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[BuildContext](res)
+	if err != nil {
+		return BuildContext{}, fmt.Errorf("failed to deserialize build context response: %w", err)
+	}
+
+	return data, nil
+}
+
+func (c *DeploysClient) Create(app string, req DeployRequest) (Deployment, error) {
+	body, err := marshal(req)
+	if err != nil {
+		return Deployment{}, fmt.Errorf("could not serialize request body: %w", err)
+	}
+
+	res, err := c.client.Post(appPath(app, "deploys"), body)
+	if err != nil {
+		return Deployment{}, fmt.Errorf("failed to deploy: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return Deployment{}, fmt.Errorf("failed to deploy: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[Deployment](res)
+	if err != nil {
+		return Deployment{}, fmt.Errorf("failed to deserialize deployment response: %w", err)
+	}
+
+	return data, nil
+}
+
+// List returns the deployments of an app, the latest first.
+func (c *DeploysClient) List(app string) ([]Deployment, error) {
+	res, err := c.client.Get(appPath(app, "deploys"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployments: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get deployments: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[struct{ Deployments []Deployment }](res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize deployments response: %w", err)
+	}
+
+	return data.Deployments, nil
+}
+
+func (c *DeploysClient) Get(app, id string) (Deployment, error) {
+	res, err := c.client.Get(appPath(app, "deploys", url.PathEscape(id)), nil)
+	if err != nil {
+		return Deployment{}, fmt.Errorf("failed to get deployment: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return Deployment{}, fmt.Errorf("failed to get deployment %s: %w", id, parseResponseError(res))
+	}
+
+	data, err := unmarshal[Deployment](res)
+	if err != nil {
+		return Deployment{}, fmt.Errorf("failed to deserialize deployment response: %w", err)
+	}
+
+	return data, nil
+}
+
+// Promote moves all the traffic to the canaries of a paused deployment.
+func (c *DeploysClient) Promote(app, id string) (Deployment, error) {
+	return c.action(app, id, "promote")
+}
+
+// Abort stops a deployment and rolls back to the instances it replaces.
+func (c *DeploysClient) Abort(app, id string) (Deployment, error) {
+	return c.action(app, id, "abort")
+}
+
+func (c *DeploysClient) action(app, id, action string) (Deployment, error) {
+	res, err := c.client.Post(appPath(app, "deploys", url.PathEscape(id), action), nil)
+	if err != nil {
+		return Deployment{}, fmt.Errorf("failed to %s deployment: %w", action, err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusAccepted {
+		return Deployment{}, fmt.Errorf("failed to %s deployment %s: %w", action, id, parseResponseError(res))
+	}
+
+	data, err := unmarshal[Deployment](res)
+	if err != nil {
+		return Deployment{}, fmt.Errorf("failed to deserialize deployment response: %w", err)
+	}
+
+	return data, nil
+}
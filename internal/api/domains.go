@@ -0,0 +1,165 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type DomainsClient client
+
+const (
+	DomainStatusPending  = "pending"
+	DomainStatusVerified = "verified"
+	DomainStatusFailed   = "failed"
+
+	CertificateStatusPending = "pending"
+	CertificateStatusIssued  = "issued"
+	CertificateStatusFailed  = "failed"
+)
+
+// Domain is a custom hostname attached to an app.
+type Domain struct {
+	Hostname    string      `json:"hostname"`
+	Status      string      `json:"status"`
+	DNSRecords  []DNSRecord `json:"dnsRecords"`
+	Certificate Certificate `json:"certificate"`
+	CreatedAt   time.Time   `json:"createdAt"`
+}
+
+// DNSRecord is a record that must exist for a domain to be verified.
+type DNSRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Certificate describes the TLS certificate issued for a domain.
+type Certificate struct {
+	Status    string     `json:"status"`
+	Issuer    string     `json:"issuer,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+func domainPath(app, hostname string) string {
+	return appPath(app, "domains", url.PathEscape(hostname))
+}
+
+func (c *DomainsClient) List(app string) ([]Domain, error) {
+	res, err := c.client.Get(appPath(app, "domains"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domains: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get domains: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[struct{ Domains []Domain }](res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize domains response: %w", err)
+	}
+
+	return data.Domains, nil
+}
+
+func (c *DomainsClient) Get(app, hostname string) (Domain, error) {
+	res, err := c.client.Get(domainPath(app, hostname), nil)
+	if err != nil {
+		return Domain{}, fmt.Errorf("failed to get domain: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return Domain{}, fmt.Errorf("failed to get domain %s: %w", hostname, parseResponseError(res))
+	}
+
+	data, err := unmarshal[Domain](res)
+	if err != nil {
+		return Domain{}, fmt.Errorf("failed to deserialize domain response: %w", err)
+	}
+
+	return data, nil
+}
+
+func (c *DomainsClient) Add(app, hostname string) (Domain, error) {
+	body, err := marshal(struct {
+		Hostname string `json:"hostname"`
+	}{hostname})
+	if err != nil {
+		return Domain{}, fmt.Errorf("could not serialize request body: %w", err)
+	}
+
+	res, err := c.client.Post(appPath(app, "domains"), body)
+	if err != nil {
+		return Domain{}, fmt.Errorf("failed to add domain: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return Domain{}, fmt.Errorf("failed to add domain %s: %w", hostname, parseResponseError(res))
+	}
+
+	data, err := unmarshal[Domain](res)
+	if err != nil {
+		return Domain{}, fmt.Errorf("failed to deserialize domain response: %w", err)
+	}
+
+	return data, nil
+}
+
+// Verify asks the API to check the DNS records of a domain again.
+func (c *DomainsClient) Verify(app, hostname string) (Domain, error) {
+	res, err := c.client.Post(domainPath(app, hostname)+"/verify", nil)
+	if err != nil {
+		return Domain{}, fmt.Errorf("failed to verify domain: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return Domain{}, fmt.Errorf("failed to verify domain %s: %w", hostname, parseResponseError(res))
+	}
+
+	data, err := unmarshal[Domain](res)
+	if err != nil {
+		return Domain{}, fmt.Errorf("failed to deserialize domain response: %w", err)
+	}
+
+	return data, nil
+}
+
+func (c *DomainsClient) Remove(app, hostname string) error {
+	res, err := c.client.Delete(domainPath(app, hostname), nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove domain: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("failed to remove domain %s: %w", hostname, parseResponseError(res))
+	}
+
+	return nil
+}
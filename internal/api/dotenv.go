@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ParseDotenv reads KEY=VALUE pairs in dotenv format. Blank lines, comments
+// and an optional leading "export" are ignored. Values may be single quoted
+// (taken literally) or double quoted (supporting \n, \t, \" and \\ escapes).
+func ParseDotenv(r io.Reader) (map[string]string, error) {
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !isValidEnvName(key) {
+			return nil, fmt.Errorf("invalid dotenv line %d: expected KEY=VALUE", lineNo)
+		}
+
+		value, err := parseDotenvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid dotenv line %d: %w", lineNo, err)
+		}
+		vars[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dotenv: %w", err)
+	}
+	return vars, nil
+}
+
+func parseDotenvValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	switch quote := value[0]; quote {
+	case '\'':
+		end := strings.IndexByte(value[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single quote")
+		}
+		return value[1 : end+1], nil
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(value); i++ {
+			switch c := value[i]; c {
+			case '"':
+				return b.String(), nil
+			case '\\':
+				i++
+				if i == len(value) {
+					return "", fmt.Errorf("unterminated double quote")
+				}
+				switch value[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				case 'r':
+					b.WriteByte('\r')
+				default:
+					b.WriteByte(value[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated double quote")
+	}
+
+	// unquoted values end at an inline comment
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// WriteDotenv writes vars in dotenv format, sorted by key. Values that are
+// not plain words are double quoted so that ParseDotenv reads them back.
+func WriteDotenv(w io.Writer, vars map[string]string) error {
+	for _, key := range slices.Sorted(maps.Keys(vars)) {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", key, quoteDotenvValue(vars[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func quoteDotenvValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\r\n\"'#\\=$") {
+		return value
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(value) + `"`
+}
+
+func isValidEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateEnvName reports whether name can be used as an environment
+// variable or secret name.
+func ValidateEnvName(name string) error {
+	if !isValidEnvName(name) {
+		return fmt.Errorf("invalid name %q: must contain only letters, digits and underscores and not start with a digit", name)
+	}
+	return nil
+}
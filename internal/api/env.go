@@ -0,0 +1,103 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type EnvClient client
+
+// EnvVar is a plain-text environment variable configured on an app.
+type EnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// EnvChange describes a batch of variables to set and unset on an app.
+// When Stage is true the change is recorded but no redeploy is triggered.
+type EnvChange struct {
+	Set   map[string]string `json:"set,omitempty"`
+	Unset []string          `json:"unset,omitempty"`
+	Stage bool              `json:"stage"`
+}
+
+// EnvChangeResult is returned after applying an EnvChange.
+type EnvChangeResult struct {
+	Staged    bool   `json:"staged"`
+	ReleaseID string `json:"releaseId,omitempty"`
+}
+
+func (c *EnvClient) List(app string) ([]EnvVar, error) {
+	res, err := c.client.Get(appPath(app, "env"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get env vars: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get env vars: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[struct{ Env []EnvVar }](res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize env vars response: %w", err)
+	}
+
+	return data.Env, nil
+}
+
+func (c *EnvClient) Update(app string, change EnvChange) (EnvChangeResult, error) {
+	body, err := marshal(change)
+	if err != nil {
+		return EnvChangeResult{}, fmt.Errorf("could not serialize request body: %w", err)
+	}
+
+	res, err := c.client.Patch(appPath(app, "env"), body)
+	if err != nil {
+		return EnvChangeResult{}, fmt.Errorf("failed to update env vars: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return EnvChangeResult{}, fmt.Errorf("failed to update env vars: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[EnvChangeResult](res)
+	if err != nil {
+		return EnvChangeResult{}, fmt.Errorf("failed to deserialize env vars response: %w", err)
+	}
+
+	return data, nil
+}
+
+// Deploy releases any staged env var and secret changes.
+func (c *EnvClient) Deploy(app string) (EnvChangeResult, error) {
+	res, err := c.client.Post(appPath(app, "env", "deploy"), nil)
+	if err != nil {
+		return EnvChangeResult{}, fmt.Errorf("failed to deploy staged changes: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return EnvChangeResult{}, fmt.Errorf("failed to deploy staged changes: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[EnvChangeResult](res)
+	if err != nil {
+		return EnvChangeResult{}, fmt.Errorf("failed to deserialize deploy response: %w", err)
+	}
+
+	return data, nil
+}
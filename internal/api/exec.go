@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/coder/websocket"
+)
+
+type ExecClient client
+
+// Exec sessions multiplex several streams over one WebSocket connection.
+// Every binary message starts with one of these channel bytes followed by
+// the payload.
+const (
+	execChannelStdin  byte = 0
+	execChannelStdout byte = 1
+	execChannelStderr byte = 2
+	execChannelResize byte = 3
+	execChannelExit   byte = 4
+)
+
+// ExecRequest describes a command to run in an app instance.
+type ExecRequest struct {
+	// Instance to run the command in, any instance if empty.
+	Instance string
+	Command  []string
+	TTY      bool
+	Size     TermSize
+}
+
+// TermSize is the size of a terminal in characters.
+type TermSize struct {
+	Cols int `json:"cols"`
+	Rows int `json:"rows"`
+}
+
+// ExecSession is a command running in an app instance.
+type ExecSession struct {
+	ctx  context.Context
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+type execExit struct {
+	Code  int    `json:"code"`
+	Error string `json:"error,omitempty"`
+}
+
+// Start runs a command in an instance of app.
+func (c *ExecClient) Start(ctx context.Context, app string, req ExecRequest) (*ExecSession, error) {
+	query := url.Values{"command": req.Command}
+	if req.Instance != "" {
+		query.Set("instance", req.Instance)
+	}
+	if req.TTY {
+		query.Set("tty", "true")
+		query.Set("cols", strconv.Itoa(req.Size.Cols))
+		query.Set("rows", strconv.Itoa(req.Size.Rows))
+	}
+
+	conn, err := c.client.dialWebSocket(ctx, appPath(app, "exec"), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start exec session: %w", err)
+	}
+	return &ExecSession{ctx: ctx, conn: conn}, nil
+}
+
+func (s *ExecSession) write(channel byte, payload []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn.Write(s.ctx, websocket.MessageBinary, append([]byte{channel}, payload...))
+}
+
+// Resize tells the remote terminal about a new window size.
+func (s *ExecSession) Resize(size TermSize) error {
+	data, err := json.Marshal(size)
+	if err != nil {
+		return err
+	}
+	return s.write(execChannelResize, data)
+}
+
+// Stream copies stdin to the remote command and its output to stdout and
+// stderr until it exits, then returns its exit code.
+func (s *ExecSession) Stream(stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	if stdin != nil {
+		go s.copyStdin(stdin)
+	}
+
+	for {
+		_, data, err := s.conn.Read(s.ctx)
+		if err != nil {
+			if websocket.CloseStatus(err) == websocket.StatusNormalClosure {
+				return 0, errors.New("connection closed before the command exited")
+			}
+			return 0, fmt.Errorf("exec session failed: %w", err)
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		channel, payload := data[0], data[1:]
+		switch channel {
+		case execChannelStdout:
+			_, err = stdout.Write(payload)
+		case execChannelStderr:
+			_, err = stderr.Write(payload)
+		case execChannelExit:
+			var exit execExit
+			if err := json.Unmarshal(payload, &exit); err != nil {
+				return 0, fmt.Errorf("failed to deserialize exit status: %w", err)
+			}
+			_ = s.conn.CloseNow()
+			if exit.Error != "" {
+				return exit.Code, errors.New(exit.Error)
+			}
+			return exit.Code, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// copyStdin forwards stdin to the session. An empty stdin message tells
+// the remote command that its input is closed.
+func (s *ExecSession) copyStdin(stdin io.Reader) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := stdin.Read(buf)
+		if n > 0 {
+			if err := s.write(execChannelStdin, buf[:n]); err != nil {
+				return
+			}
+		}
+		if err != nil {
+			_ = s.write(execChannelStdin, nil)
+			return
+		}
+	}
+}
+
+// Close ends the session.
+func (s *ExecSession) Close() error {
+	return s.conn.Close(websocket.StatusNormalClosure, "")
+}
@@ -2,9 +2,9 @@ package api
 
 import (
 	"io"
-	"mime/multipart"
 	"net/http"
-	"os"
+	"net/url"
+	"path"
 )
 
 func Header(key, value string) map[string]string {
@@ -13,6 +13,11 @@ func Header(key, value string) map[string]string {
 	}
 }
 
+// appPath builds the API path for a resource nested under an app.
+func appPath(app string, elem ...string) string {
+	return path.Join(append([]string{"/v1/apps", url.PathEscape(app)}, elem...)...)
+}
+
 func (c *Client) Get(path string, body io.Reader) (*http.Response, error) {
 	return c.do("GET", path, body, Header("Content-Type", "application/json"))
 }
@@ -40,41 +45,6 @@ func (c *Client) Put(path string, body io.Reader) (*http.Response, error) {
 	return c.do("PUT", path, body, Header("Content-Type", "application/json"))
 }
 
-func (c *Client) Upload(path string, fileData *os.File) (*http.Response, error) {
-	body, bodyWriter := io.Pipe()
-	writer := multipart.NewWriter(bodyWriter)
-	go func() {
-		formFile, err := writer.CreateFormFile("file", fileData.Name())
-		if err != nil {
-			err := bodyWriter.CloseWithError(err)
-			if err != nil {
-				return
-			}
-			return
-		}
-		if _, err := io.Copy(formFile, fileData); err != nil {
-			err := bodyWriter.CloseWithError(err)
-			if err != nil {
-				return
-			}
-			return
-		}
-		err = bodyWriter.CloseWithError(writer.Close())
-		if err != nil {
-			return
-		}
-	}()
-	req, err := c.newRequest("POST", path, body, Header("Content-Type", writer.FormDataContentType()))
-	if err != nil {
-		return nil, err
-	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	return resp, nil
-}
-
 func (c *Client) Delete(path string, body io.Reader) (*http.Response, error) {
 	return c.do("DELETE", path, body, Header("Content-Type", "application/json"))
 }
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type InstancesClient client
+
+const (
+	InstanceStateStarting = "starting"
+	InstanceStateRunning  = "running"
+	InstanceStateStopped  = "stopped"
+	InstanceStateCrashed  = "crashed"
+
+	InstanceHealthHealthy   = "healthy"
+	InstanceHealthUnhealthy = "unhealthy"
+	InstanceHealthUnknown   = "unknown"
+)
+
+// Instance is a running copy of an app.
+type Instance struct {
+	ID        string     `json:"id"`
+	Region    string     `json:"region"`
+	Size      string     `json:"size"`
+	State     string     `json:"state"`
+	Health    string     `json:"health"`
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+}
+
+// Uptime returns how long the instance has been running.
+func (i Instance) Uptime() time.Duration {
+	if i.StartedAt == nil || i.State != InstanceStateRunning {
+		return 0
+	}
+	return time.Since(*i.StartedAt)
+}
+
+// IsHealthy reports whether the instance is running and passing health checks.
+func (i Instance) IsHealthy() bool {
+	return i.State == InstanceStateRunning && i.Health == InstanceHealthHealthy
+}
+
+func instancePath(app, id string, elem ...string) string {
+	return appPath(app, append([]string{"instances", url.PathEscape(id)}, elem...)...)
+}
+
+func (c *InstancesClient) List(app string) ([]Instance, error) {
+	res, err := c.client.Get(appPath(app, "instances"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instances: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get instances: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[struct{ Instances []Instance }](res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize instances response: %w", err)
+	}
+
+	return data.Instances, nil
+}
+
+func (c *InstancesClient) Restart(app, id string) error {
+	return c.action(app, id, "restart")
+}
+
+func (c *InstancesClient) Stop(app, id string) error {
+	return c.action(app, id, "stop")
+}
+
+func (c *InstancesClient) action(app, id, action string) error {
+	res, err := c.client.Post(instancePath(app, id, action), nil)
+	if err != nil {
+		return fmt.Errorf("failed to %s instance: %w", action, err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("failed to %s instance %s: %w", action, id, parseResponseError(res))
+	}
+
+	return nil
+}
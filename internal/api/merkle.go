@@ -0,0 +1,62 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MerkleRoot returns the digest of the tree of files, the Root of their
+// ContextManifest. The digest of a directory covers the mode, name and
+// digest of each of its entries, in order, so that the root changes
+// whenever any file changes.
+func MerkleRoot(files []ContextFile) string {
+	root := &treeNode{}
+	for _, f := range files {
+		n := root
+		elems := strings.Split(f.Path, "/")
+		for _, dir := range elems[:len(elems)-1] {
+			n = n.dir(dir)
+		}
+		n.entries = append(n.entries, treeEntry{name: elems[len(elems)-1], mode: fmt.Sprintf("%o", f.Mode), digest: f.Digest})
+	}
+	return root.digest()
+}
+
+type treeEntry struct {
+	name   string
+	mode   string
+	digest string
+}
+
+// treeNode is a directory of a Merkle tree.
+type treeNode struct {
+	entries []treeEntry
+	dirs    map[string]*treeNode
+}
+
+func (n *treeNode) dir(name string) *treeNode {
+	if n.dirs == nil {
+		n.dirs = map[string]*treeNode{}
+	}
+	if n.dirs[name] == nil {
+		n.dirs[name] = &treeNode{}
+	}
+	return n.dirs[name]
+}
+
+func (n *treeNode) digest() string {
+	entries := slices.Clone(n.entries)
+	for name, dir := range n.dirs {
+		entries = append(entries, treeEntry{name: name, mode: "dir", digest: dir.digest()})
+	}
+	slices.SortFunc(entries, func(a, b treeEntry) int { return strings.Compare(a.name, b.name) })
+
+	h := sha256.New()
+	for _, e := range entries {
+		fmt.Fprintf(h, "%s %s %s\n", e.mode, e.name, e.digest)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
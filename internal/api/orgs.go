@@ -0,0 +1,209 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"time"
+)
+
+type OrgsClient client
+
+// Roles a member can have in an organization.
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// OrgRoles lists the valid organization roles.
+var OrgRoles = []string{OrgRoleOwner, OrgRoleAdmin, OrgRoleMember}
+
+// Org is an organization the current user belongs to.
+type Org struct {
+	ID   string `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+	// Role of the current user in the organization.
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Member is a user belonging to an organization.
+type Member struct {
+	UserID   string    `json:"userId"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+// Invitation is a pending invitation to join an organization.
+type Invitation struct {
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ValidateOrgRole checks that role is a known organization role.
+func ValidateOrgRole(role string) error {
+	if !slices.Contains(OrgRoles, role) {
+		return fmt.Errorf("invalid role %q, expected one of %v", role, OrgRoles)
+	}
+	return nil
+}
+
+func orgPath(slug string, elem ...string) string {
+	return path.Join(append([]string{"/v1/orgs", url.PathEscape(slug)}, elem...)...)
+}
+
+func (c *OrgsClient) List() ([]Org, error) {
+	res, err := c.client.Get("/v1/orgs", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organizations: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get organizations: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[struct{ Orgs []Org }](res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize organizations response: %w", err)
+	}
+
+	return data.Orgs, nil
+}
+
+func (c *OrgsClient) Get(slug string) (Org, error) {
+	res, err := c.client.Get(orgPath(slug), nil)
+	if err != nil {
+		return Org{}, fmt.Errorf("failed to get organization: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return Org{}, fmt.Errorf("failed to get organization %s: %w", slug, parseResponseError(res))
+	}
+
+	data, err := unmarshal[Org](res)
+	if err != nil {
+		return Org{}, fmt.Errorf("failed to deserialize organization response: %w", err)
+	}
+
+	return data, nil
+}
+
+func (c *OrgsClient) Members(slug string) ([]Member, error) {
+	res, err := c.client.Get(orgPath(slug, "members"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get members: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get members of %s: %w", slug, parseResponseError(res))
+	}
+
+	data, err := unmarshal[struct{ Members []Member }](res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize members response: %w", err)
+	}
+
+	return data.Members, nil
+}
+
+// Invite sends an invitation to join the organization to email.
+func (c *OrgsClient) Invite(slug, email, role string) (Invitation, error) {
+	body, err := marshal(struct {
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}{email, role})
+	if err != nil {
+		return Invitation{}, fmt.Errorf("could not serialize request body: %w", err)
+	}
+
+	res, err := c.client.Post(orgPath(slug, "invitations"), body)
+	if err != nil {
+		return Invitation{}, fmt.Errorf("failed to invite member: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return Invitation{}, fmt.Errorf("failed to invite %s: %w", email, parseResponseError(res))
+	}
+
+	data, err := unmarshal[Invitation](res)
+	if err != nil {
+		return Invitation{}, fmt.Errorf("failed to deserialize invitation response: %w", err)
+	}
+
+	return data, nil
+}
+
+func (c *OrgsClient) RemoveMember(slug, username string) error {
+	res, err := c.client.Delete(orgPath(slug, "members", url.PathEscape(username)), nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("failed to remove %s: %w", username, parseResponseError(res))
+	}
+
+	return nil
+}
+
+func (c *OrgsClient) SetRole(slug, username, role string) (Member, error) {
+	body, err := marshal(struct {
+		Role string `json:"role"`
+	}{role})
+	if err != nil {
+		return Member{}, fmt.Errorf("could not serialize request body: %w", err)
+	}
+
+	res, err := c.client.Patch(orgPath(slug, "members", url.PathEscape(username)), body)
+	if err != nil {
+		return Member{}, fmt.Errorf("failed to set role: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return Member{}, fmt.Errorf("failed to set role of %s: %w", username, parseResponseError(res))
+	}
+
+	data, err := unmarshal[Member](res)
+	if err != nil {
+		return Member{}, fmt.Errorf("failed to deserialize member response: %w", err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/coder/websocket"
+)
+
+type ProxyClient client
+
+// Dial opens a TCP tunnel to port of an app instance. The tunnel is carried
+// over an authenticated WebSocket connection. If instance is empty, the API
+// picks any instance of the app.
+func (c *ProxyClient) Dial(ctx context.Context, app, instance string, port int) (net.Conn, error) {
+	query := url.Values{"port": {strconv.Itoa(port)}}
+	if instance != "" {
+		query.Set("instance", instance)
+	}
+
+	conn, err := c.client.dialWebSocket(ctx, appPath(app, "proxy"), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tunnel to port %d: %w", port, err)
+	}
+	return websocket.NetConn(ctx, conn, websocket.MessageBinary), nil
+}
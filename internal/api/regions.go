@@ -0,0 +1,96 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type RegionsClient client
+
+// Region is a location apps can be placed in.
+type Region struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// PingURL is an endpoint in the region used to estimate latency.
+	PingURL string `json:"pingUrl"`
+}
+
+func (c *RegionsClient) List() ([]Region, error) {
+	res, err := c.client.Get("/v1/regions", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get regions: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get regions: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[struct{ Regions []Region }](res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize regions response: %w", err)
+	}
+
+	return data.Regions, nil
+}
+
+// AppRegions returns the codes of the regions an app is placed in.
+func (c *RegionsClient) AppRegions(app string) ([]string, error) {
+	res, err := c.client.Get(appPath(app, "regions"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app regions: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get app regions: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[struct{ Regions []string }](res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize app regions response: %w", err)
+	}
+
+	return data.Regions, nil
+}
+
+// UpdateAppRegions adds and removes regions of an app and returns the
+// resulting region codes.
+func (c *RegionsClient) UpdateAppRegions(app string, add, remove []string) ([]string, error) {
+	body, err := marshal(struct {
+		Add    []string `json:"add,omitempty"`
+		Remove []string `json:"remove,omitempty"`
+	}{add, remove})
+	if err != nil {
+		return nil, fmt.Errorf("could not serialize request body: %w", err)
+	}
+
+	res, err := c.client.Patch(appPath(app, "regions"), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update app regions: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to update app regions: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[struct{ Regions []string }](res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize app regions response: %w", err)
+	}
+
+	return data.Regions, nil
+}
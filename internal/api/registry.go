@@ -0,0 +1,51 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+)
+
+type RegistryClient client
+
+// RegistryCredentials are short-lived credentials to push images to the a0
+// registry, in the repositories under Namespace.
+type RegistryCredentials struct {
+	Registry  string    `json:"registry"`
+	Namespace string    `json:"namespace"`
+	Username  string    `json:"username"`
+	Password  string    `json:"password"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Repository returns the repository of the images of app, e.g.
+// registry.a0.run/jane/web.
+func (c RegistryCredentials) Repository(app string) string {
+	return path.Join(c.Registry, c.Namespace, app)
+}
+
+// Credentials returns new credentials for the registry, scoped to the
+// organization of the client.
+func (c *RegistryClient) Credentials() (RegistryCredentials, error) {
+	res, err := c.client.Post("/v1/registry/tokens", nil)
+	if err != nil {
+		return RegistryCredentials{}, fmt.Errorf("failed to get registry credentials: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return RegistryCredentials{}, fmt.Errorf("failed to get registry credentials: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[RegistryCredentials](res)
+	if err != nil {
+		return RegistryCredentials{}, fmt.Errorf("failed to deserialize registry credentials response: %w", err)
+	}
+
+	return data, nil
+}
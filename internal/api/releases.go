@@ -0,0 +1,43 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type ReleasesClient client
+
+// Release is a version of an app: its image together with its
+// configuration. Deploys and configuration changes create new releases.
+type Release struct {
+	ID          string    `json:"id"`
+	Version     int       `json:"version"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// List returns the releases of app, latest first.
+func (c *ReleasesClient) List(app string) ([]Release, error) {
+	res, err := c.client.Get(appPath(app, "releases"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get releases: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get releases: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[struct{ Releases []Release }](res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize releases response: %w", err)
+	}
+
+	return data.Releases, nil
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type ScaleClient client
+
+// Size is a CPU and memory preset that app instances can run with.
+type Size struct {
+	Name     string  `json:"name"`
+	CPUs     float64 `json:"cpus"`
+	MemoryMB int     `json:"memoryMb"`
+}
+
+// Scale is the number and size of instances an app runs with.
+type Scale struct {
+	Count int    `json:"count"`
+	Size  string `json:"size"`
+}
+
+// Sizes lists the instance size presets offered by the platform.
+func (c *ScaleClient) Sizes() ([]Size, error) {
+	res, err := c.client.Get("/v1/sizes", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sizes: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get sizes: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[struct{ Sizes []Size }](res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize sizes response: %w", err)
+	}
+
+	return data.Sizes, nil
+}
+
+func (c *ScaleClient) Get(app string) (Scale, error) {
+	res, err := c.client.Get(appPath(app, "scale"), nil)
+	if err != nil {
+		return Scale{}, fmt.Errorf("failed to get scale: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return Scale{}, fmt.Errorf("failed to get scale: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[Scale](res)
+	if err != nil {
+		return Scale{}, fmt.Errorf("failed to deserialize scale response: %w", err)
+	}
+
+	return data, nil
+}
+
+func (c *ScaleClient) Update(app string, scale Scale) (Scale, error) {
+	body, err := marshal(scale)
+	if err != nil {
+		return Scale{}, fmt.Errorf("could not serialize request body: %w", err)
+	}
+
+	res, err := c.client.Put(appPath(app, "scale"), body)
+	if err != nil {
+		return Scale{}, fmt.Errorf("failed to update scale: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return Scale{}, fmt.Errorf("failed to update scale: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[Scale](res)
+	if err != nil {
+		return Scale{}, fmt.Errorf("failed to deserialize scale response: %w", err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type SecretsClient client
+
+// Secret describes a secret configured on an app. The API never returns
+// secret values, only a digest that can be used to compare them.
+type Secret struct {
+	Name      string    `json:"name"`
+	Digest    string    `json:"digest"`
+	CreatedAt time.Time `json:"createdAt"`
+	Staged    bool      `json:"staged"`
+}
+
+// SecretsChangeResult is returned after applying a change to app secrets.
+type SecretsChangeResult struct {
+	EnvChangeResult
+	Secrets []Secret `json:"secrets"`
+}
+
+func (c *SecretsClient) List(app string) ([]Secret, error) {
+	res, err := c.client.Get(appPath(app, "secrets"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secrets: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get secrets: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[struct{ Secrets []Secret }](res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize secrets response: %w", err)
+	}
+
+	return data.Secrets, nil
+}
+
+func (c *SecretsClient) Update(app string, change EnvChange) (SecretsChangeResult, error) {
+	body, err := marshal(change)
+	if err != nil {
+		return SecretsChangeResult{}, fmt.Errorf("could not serialize request body: %w", err)
+	}
+
+	res, err := c.client.Patch(appPath(app, "secrets"), body)
+	if err != nil {
+		return SecretsChangeResult{}, fmt.Errorf("failed to update secrets: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return SecretsChangeResult{}, fmt.Errorf("failed to update secrets: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[SecretsChangeResult](res)
+	if err != nil {
+		return SecretsChangeResult{}, fmt.Errorf("failed to deserialize secrets response: %w", err)
+	}
+
+	return data, nil
+}
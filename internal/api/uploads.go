@@ -0,0 +1,219 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+)
+
+type UploadsClient client
+
+// Upload states.
+const (
+	UploadStatePending   = "pending"
+	UploadStateCompleted = "completed"
+)
+
+// HeaderContentSHA256 carries the hex SHA-256 of an uploaded part, which
+// the API verifies before storing it.
+const HeaderContentSHA256 = "Content-SHA256"
+
+// Upload is a file uploaded in parts. Parts can be sent in any order and
+// in parallel, and the upload is assembled once completed.
+type Upload struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+	PartSize int64  `json:"partSize"`
+	State    string `json:"state"`
+	// Parts are the parts received so far.
+	Parts     []UploadPart `json:"parts"`
+	ExpiresAt time.Time    `json:"expiresAt"`
+}
+
+// UploadPart is a part of an upload, numbered from 1.
+type UploadPart struct {
+	Number int    `json:"number"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// InitiateUploadRequest describes a file to upload. SHA256 is the checksum
+// of the whole file, verified when the upload completes.
+type InitiateUploadRequest struct {
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+	PartSize int64  `json:"partSize"`
+}
+
+// StatusError is an error response of the API, for callers that handle
+// some statuses differently.
+type StatusError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *StatusError) Error() string { return e.Err.Error() }
+
+func (e *StatusError) Unwrap() error { return e.Err }
+
+// Temporary reports whether the request may succeed if retried.
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode >= http.StatusInternalServerError || e.StatusCode == http.StatusTooManyRequests
+}
+
+func statusError(res *http.Response) error {
+	return &StatusError{StatusCode: res.StatusCode, Err: parseResponseError(res)}
+}
+
+func uploadPath(id string, elem ...string) string {
+	return path.Join(append([]string{"/v1/uploads", url.PathEscape(id)}, elem...)...)
+}
+
+func (c *UploadsClient) Initiate(req InitiateUploadRequest) (Upload, error) {
+	body, err := marshal(req)
+	if err != nil {
+		return Upload{}, fmt.Errorf("could not serialize request body: %w", err)
+	}
+
+	res, err := c.client.Post("/v1/uploads", body)
+	if err != nil {
+		return Upload{}, fmt.Errorf("failed to initiate upload: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return Upload{}, fmt.Errorf("failed to initiate upload: %w", statusError(res))
+	}
+
+	data, err := unmarshal[Upload](res)
+	if err != nil {
+		return Upload{}, fmt.Errorf("failed to deserialize upload response: %w", err)
+	}
+
+	return data, nil
+}
+
+// Get returns an upload with the parts received so far. Expired uploads
+// fail with a *StatusError of status 404.
+func (c *UploadsClient) Get(id string) (Upload, error) {
+	res, err := c.client.Get(uploadPath(id), nil)
+	if err != nil {
+		return Upload{}, fmt.Errorf("failed to get upload: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return Upload{}, fmt.Errorf("failed to get upload %s: %w", id, statusError(res))
+	}
+
+	data, err := unmarshal[Upload](res)
+	if err != nil {
+		return Upload{}, fmt.Errorf("failed to deserialize upload response: %w", err)
+	}
+
+	return data, nil
+}
+
+// PutPart uploads a part, replacing any previous one with the same number.
+// Failed requests return a *StatusError telling whether to retry.
+func (c *UploadsClient) PutPart(ctx context.Context, id string, number int, data []byte) (UploadPart, error) {
+	sum := sha256.Sum256(data)
+	req, err := c.client.newRequest("PUT", uploadPath(id, "parts", strconv.Itoa(number)), bytes.NewReader(data), map[string]string{
+		"Content-Type":      "application/octet-stream",
+		HeaderContentSHA256: hex.EncodeToString(sum[:]),
+	})
+	if err != nil {
+		return UploadPart{}, err
+	}
+	req.ContentLength = int64(len(data))
+
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return UploadPart{}, fmt.Errorf("failed to upload part %d: %w", number, err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return UploadPart{}, fmt.Errorf("failed to upload part %d: %w", number, statusError(res))
+	}
+
+	part, err := unmarshal[UploadPart](res)
+	if err != nil {
+		return UploadPart{}, fmt.Errorf("failed to deserialize upload part response: %w", err)
+	}
+
+	return part, nil
+}
+
+// Complete assembles the parts, in order, into the uploaded file. The API
+// rejects the upload if the file doesn't match its SHA-256.
+func (c *UploadsClient) Complete(id string, parts []UploadPart) (Upload, error) {
+	body, err := marshal(struct {
+		Parts []UploadPart `json:"parts"`
+	}{parts})
+	if err != nil {
+		return Upload{}, fmt.Errorf("could not serialize request body: %w", err)
+	}
+
+	res, err := c.client.Post(uploadPath(id, "complete"), body)
+	if err != nil {
+		return Upload{}, fmt.Errorf("failed to complete upload: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return Upload{}, fmt.Errorf("failed to complete upload %s: %w", id, statusError(res))
+	}
+
+	data, err := unmarshal[Upload](res)
+	if err != nil {
+		return Upload{}, fmt.Errorf("failed to deserialize upload response: %w", err)
+	}
+
+	return data, nil
+}
+
+// Abort discards an upload and its parts.
+func (c *UploadsClient) Abort(id string) error {
+	res, err := c.client.Delete(uploadPath(id), nil)
+	if err != nil {
+		return fmt.Errorf("failed to abort upload: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("failed to abort upload %s: %w", id, statusError(res))
+	}
+
+	return nil
+}
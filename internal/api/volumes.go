@@ -0,0 +1,211 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type VolumesClient client
+
+// Volume sizes accepted by the API, in gigabytes.
+const (
+	MinVolumeSizeGB = 1
+	MaxVolumeSizeGB = 500
+)
+
+// Volume is a persistent disk that can be attached to an app instance.
+type Volume struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	SizeGB    int       `json:"sizeGb"`
+	Region    string    `json:"region"`
+	State     string    `json:"state"`
+	CreatedAt time.Time `json:"createdAt"`
+	// AttachedTo is the ID of the instance using the volume, if any.
+	AttachedTo string `json:"attachedTo,omitempty"`
+}
+
+// Snapshot is a point-in-time copy of a volume.
+type Snapshot struct {
+	ID        string    `json:"id"`
+	SizeGB    int       `json:"sizeGb"`
+	State     string    `json:"state"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CreateVolumeRequest describes a volume to create. If SnapshotID is set the
+// volume is restored from that snapshot.
+type CreateVolumeRequest struct {
+	Name       string `json:"name"`
+	SizeGB     int    `json:"sizeGb"`
+	Region     string `json:"region,omitempty"`
+	SnapshotID string `json:"snapshotId,omitempty"`
+}
+
+// ValidateVolumeSize checks that size is within the limits of the API.
+func ValidateVolumeSize(sizeGB int) error {
+	if sizeGB < MinVolumeSizeGB || sizeGB > MaxVolumeSizeGB {
+		return fmt.Errorf("volume size must be between %dGB and %dGB, got %dGB", MinVolumeSizeGB, MaxVolumeSizeGB, sizeGB)
+	}
+	return nil
+}
+
+func volumePath(app, id string, elem ...string) string {
+	return appPath(app, append([]string{"volumes", url.PathEscape(id)}, elem...)...)
+}
+
+func (c *VolumesClient) List(app string) ([]Volume, error) {
+	res, err := c.client.Get(appPath(app, "volumes"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get volumes: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get volumes: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[struct{ Volumes []Volume }](res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize volumes response: %w", err)
+	}
+
+	return data.Volumes, nil
+}
+
+func (c *VolumesClient) Get(app, id string) (Volume, error) {
+	res, err := c.client.Get(volumePath(app, id), nil)
+	if err != nil {
+		return Volume{}, fmt.Errorf("failed to get volume: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return Volume{}, fmt.Errorf("failed to get volume %s: %w", id, parseResponseError(res))
+	}
+
+	data, err := unmarshal[Volume](res)
+	if err != nil {
+		return Volume{}, fmt.Errorf("failed to deserialize volume response: %w", err)
+	}
+
+	return data, nil
+}
+
+func (c *VolumesClient) Create(app string, req CreateVolumeRequest) (Volume, error) {
+	if err := ValidateVolumeSize(req.SizeGB); err != nil {
+		return Volume{}, err
+	}
+
+	body, err := marshal(req)
+	if err != nil {
+		return Volume{}, fmt.Errorf("could not serialize request body: %w", err)
+	}
+
+	res, err := c.client.Post(appPath(app, "volumes"), body)
+	if err != nil {
+		return Volume{}, fmt.Errorf("failed to create volume: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return Volume{}, fmt.Errorf("failed to create volume: %w", parseResponseError(res))
+	}
+
+	data, err := unmarshal[Volume](res)
+	if err != nil {
+		return Volume{}, fmt.Errorf("failed to deserialize volume response: %w", err)
+	}
+
+	return data, nil
+}
+
+// Extend grows a volume to sizeGB. Volumes cannot shrink.
+func (c *VolumesClient) Extend(app, id string, sizeGB int) (Volume, error) {
+	if err := ValidateVolumeSize(sizeGB); err != nil {
+		return Volume{}, err
+	}
+
+	body, err := marshal(struct {
+		SizeGB int `json:"sizeGb"`
+	}{sizeGB})
+	if err != nil {
+		return Volume{}, fmt.Errorf("could not serialize request body: %w", err)
+	}
+
+	res, err := c.client.Post(volumePath(app, id, "extend"), body)
+	if err != nil {
+		return Volume{}, fmt.Errorf("failed to extend volume: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return Volume{}, fmt.Errorf("failed to extend volume %s: %w", id, parseResponseError(res))
+	}
+
+	data, err := unmarshal[Volume](res)
+	if err != nil {
+		return Volume{}, fmt.Errorf("failed to deserialize volume response: %w", err)
+	}
+
+	return data, nil
+}
+
+func (c *VolumesClient) Delete(app, id string) error {
+	res, err := c.client.Delete(volumePath(app, id), nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete volume: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("failed to delete volume %s: %w", id, parseResponseError(res))
+	}
+
+	return nil
+}
+
+func (c *VolumesClient) Snapshots(app, id string) ([]Snapshot, error) {
+	res, err := c.client.Get(volumePath(app, id, "snapshots"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshots: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get snapshots of volume %s: %w", id, parseResponseError(res))
+	}
+
+	data, err := unmarshal[struct{ Snapshots []Snapshot }](res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize snapshots response: %w", err)
+	}
+
+	return data.Snapshots, nil
+}
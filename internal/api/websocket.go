@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/coder/websocket"
+)
+
+// dialWebSocket opens a WebSocket connection to an API path, authenticated
+// the same way as regular requests.
+func (c *Client) dialWebSocket(ctx context.Context, path string, query url.Values) (*websocket.Conn, error) {
+	u, err := c.BaseURL.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+	u.RawQuery = query.Encode()
+
+	header := http.Header{}
+	c.addHeaders(header)
+
+	conn, res, err := websocket.Dial(ctx, u.String(), &websocket.DialOptions{HTTPHeader: header})
+	if err != nil {
+		if res != nil && res.StatusCode != http.StatusSwitchingProtocols {
+			return nil, fmt.Errorf("could not connect: %w", parseResponseError(res))
+		}
+		return nil, fmt.Errorf("could not connect: %w", err)
+	}
+	conn.SetReadLimit(-1)
+	return conn, nil
+}
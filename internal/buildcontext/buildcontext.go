@@ -0,0 +1,144 @@
+// Package buildcontext uploads the source directory of an app as a build
+// context, incrementally.
+//
+// Files are addressed by the SHA-256 of their content. Scan builds a
+// manifest of the files of a directory, with the root of their Merkle tree
+// identifying the whole context, and Upload only sends the files the API
+// doesn't have yet, compressed with zstd. Deploying again after changing a
+// few files only uploads those files.
+package buildcontext
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+)
+
+// Modes of the files of a manifest. Permissions other than the executable
+// bit aren't kept, like in container images built from a context.
+const (
+	ModeFile       uint32 = 0o644
+	ModeExecutable uint32 = 0o755
+	ModeSymlink    uint32 = 0o120000
+)
+
+// DefaultDockerfile is the path of the Dockerfile in a context, unless
+// given otherwise.
+const DefaultDockerfile = "Dockerfile"
+
+// Scan builds the manifest of the files of dir, leaving out those matched
+// by its ignore file except the ignore file itself and the Dockerfile,
+// whose path is relative to dir. Like for docker build, they are needed
+// to build the context.
+func Scan(dir, dockerfile string) (api.ContextManifest, error) {
+	if dockerfile == "" {
+		dockerfile = DefaultDockerfile
+	}
+	dockerfile = filepath.ToSlash(filepath.Clean(dockerfile))
+
+	ig, err := readIgnoreFile(dir)
+	if err != nil {
+		return api.ContextManifest{}, fmt.Errorf("could not read %s: %w", IgnoreFile, err)
+	}
+
+	var files []api.ContextFile
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if ig.ignored(rel) && rel != dockerfile && rel != IgnoreFile {
+			if d.IsDir() && !ig.negations {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		file, err := scanFile(p, rel, d)
+		if err != nil {
+			return err
+		}
+		if file != nil {
+			files = append(files, *file)
+		}
+		return nil
+	})
+	if err != nil {
+		return api.ContextManifest{}, err
+	}
+
+	slices.SortFunc(files, func(a, b api.ContextFile) int { return strings.Compare(a.Path, b.Path) })
+	return api.ContextManifest{Root: api.MerkleRoot(files), Files: files}, nil
+}
+
+// scanFile returns the manifest entry of the file at p, nil for files that
+// can't be part of a context like sockets.
+func scanFile(p, rel string, d fs.DirEntry) (*api.ContextFile, error) {
+	info, err := d.Info()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(p)
+		if err != nil {
+			return nil, err
+		}
+		return &api.ContextFile{Path: rel, Mode: ModeSymlink, Digest: digestBytes([]byte(target)), Target: filepath.ToSlash(target)}, nil
+	case info.Mode().IsRegular():
+		digest, err := digestFile(p)
+		if err != nil {
+			return nil, err
+		}
+		mode := ModeFile
+		if info.Mode()&0o111 != 0 {
+			mode = ModeExecutable
+		}
+		return &api.ContextFile{Path: rel, Mode: mode, Size: info.Size(), Digest: digest}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func digestFile(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			return
+		}
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func digestBytes(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,309 @@
+package buildcontext_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/api/apitest"
+	"github.com/a0dotrun/a0ctl/internal/buildcontext"
+)
+
+// writeTree creates files, by slash-separated path, in a new directory.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func paths(m api.ContextManifest) []string {
+	var out []string
+	for _, f := range m.Files {
+		out = append(out, f.Path)
+	}
+	return out
+}
+
+func TestScanIgnore(t *testing.T) {
+	tests := []struct {
+		name   string
+		ignore string
+		want   []string
+	}{
+		{
+			name: "no ignore file",
+			want: []string{"Dockerfile", "README.md", "node_modules/left-pad/index.js", "src/app.js", "src/app_test.js", "src/vendor/lib.js"},
+		},
+		{
+			name:   "directory",
+			ignore: "node_modules\n# comment\n\nsrc/vendor/",
+			want:   []string{".dockerignore", "Dockerfile", "README.md", "src/app.js", "src/app_test.js"},
+		},
+		{
+			name:   "wildcards",
+			ignore: "**/*_test.js\n*.md",
+			want:   []string{".dockerignore", "Dockerfile", "node_modules/left-pad/index.js", "src/app.js", "src/vendor/lib.js"},
+		},
+		{
+			name:   "negation",
+			ignore: "src\n!src/app.js",
+			want:   []string{".dockerignore", "Dockerfile", "README.md", "node_modules/left-pad/index.js", "src/app.js"},
+		},
+		{
+			name:   "dockerfile is kept",
+			ignore: "*\n",
+			want:   []string{".dockerignore", "Dockerfile"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{
+				"Dockerfile":                     "FROM scratch",
+				"README.md":                      "# app",
+				"src/app.js":                     "app",
+				"src/app_test.js":                "test",
+				"src/vendor/lib.js":              "lib",
+				"node_modules/left-pad/index.js": "pad",
+				".git/HEAD":                      "ref: refs/heads/main",
+			}
+			if tt.ignore != "" {
+				files[buildcontext.IgnoreFile] = tt.ignore
+			}
+			m, err := buildcontext.Scan(writeTree(t, files), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := paths(m); !slices.Equal(got, tt.want) {
+				t.Errorf("files = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanModes(t *testing.T) {
+	dir := writeTree(t, map[string]string{"Dockerfile": "FROM scratch", "run.sh": "#!/bin/sh"})
+	if err := os.Chmod(filepath.Join(dir, "run.sh"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("run.sh", filepath.Join(dir, "start")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+
+	m, err := buildcontext.Scan(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	modes := map[string]uint32{}
+	for _, f := range m.Files {
+		modes[f.Path] = f.Mode
+	}
+	want := map[string]uint32{"Dockerfile": buildcontext.ModeFile, "run.sh": buildcontext.ModeExecutable, "start": buildcontext.ModeSymlink}
+	for path, mode := range want {
+		if modes[path] != mode {
+			t.Errorf("mode of %s = %o, want %o", path, modes[path], mode)
+		}
+	}
+}
+
+func TestMerkleRoot(t *testing.T) {
+	files := map[string]string{"Dockerfile": "FROM scratch", "src/a.js": "a", "src/lib/b.js": "b"}
+	root := func(files map[string]string) string {
+		t.Helper()
+		m, err := buildcontext.Scan(writeTree(t, files), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m.Root
+	}
+
+	base := root(files)
+	if again := root(files); again != base {
+		t.Errorf("root changed between scans: %s, %s", base, again)
+	}
+	for name, change := range map[string]map[string]string{
+		"content": {"src/lib/b.js": "B"},
+		"new":     {"src/lib/c.js": "c"},
+		"moved":   {"src/lib/b.js": "", "src/b.js": "b"},
+	} {
+		changed := map[string]string{}
+		for k, v := range files {
+			changed[k] = v
+		}
+		for k, v := range change {
+			if v == "" {
+				delete(changed, k)
+			} else {
+				changed[k] = v
+			}
+		}
+		if root(changed) == base {
+			t.Errorf("%s: root didn't change", name)
+		}
+	}
+}
+
+func newClient(t *testing.T) (*api.Client, *apitest.Server) {
+	t.Helper()
+	s := apitest.New(t)
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api.NewClient(u, apitest.DefaultToken, apitest.DefaultUsername), s
+}
+
+func blobRequests(s *apitest.Server) int {
+	n := 0
+	for _, r := range s.Requests() {
+		if r.Method == http.MethodPut && strings.HasPrefix(r.Path, "/v1/blobs/") {
+			n++
+		}
+	}
+	return n
+}
+
+func TestUploadIsIncremental(t *testing.T) {
+	client, s := newClient(t)
+	dir := writeTree(t, map[string]string{
+		"Dockerfile": "FROM scratch",
+		"a.txt":      strings.Repeat("a", 10000),
+		"copy/a.txt": strings.Repeat("a", 10000),
+		"b.txt":      "b",
+		"lib/c.txt":  "c",
+	})
+
+	upload := func() (api.BuildContext, buildcontext.Stats) {
+		t.Helper()
+		m, err := buildcontext.Scan(dir, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		bc, stats, err := buildcontext.Upload(context.Background(), client, dir, m, buildcontext.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bc, stats
+	}
+
+	bc, stats := upload()
+	// The copy of a.txt is only sent once.
+	if stats.Files != 5 || stats.Uploaded != 4 || blobRequests(s) != 4 {
+		t.Errorf("stats = %+v with %d blob requests", stats, blobRequests(s))
+	}
+	if stats.CompressedSize >= stats.UploadedSize {
+		t.Errorf("%d bytes sent for %d bytes of files", stats.CompressedSize, stats.UploadedSize)
+	}
+	manifest, ok := s.Context(bc.ID)
+	if !ok || len(manifest.Files) != 5 {
+		t.Fatalf("context %s = %+v", bc.ID, manifest)
+	}
+	for _, f := range manifest.Files {
+		data, _ := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		if blob, ok := s.Blob(f.Digest); !ok || string(blob) != string(data) {
+			t.Errorf("blob of %s doesn't match the file", f.Path)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "b.txt"), []byte("changed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sent := blobRequests(s)
+	bc2, stats := upload()
+	if stats.Uploaded != 1 || blobRequests(s)-sent != 1 {
+		t.Errorf("stats = %+v after changing a file", stats)
+	}
+	if bc2.Root == bc.Root {
+		t.Error("root didn't change")
+	}
+
+	sent = blobRequests(s)
+	if _, stats := upload(); stats.Uploaded != 0 || blobRequests(s) != sent {
+		t.Errorf("stats = %+v without changes", stats)
+	}
+}
+
+func TestUploadRetries(t *testing.T) {
+	client, s := newClient(t)
+	dir := writeTree(t, map[string]string{"Dockerfile": "FROM scratch"})
+	s.Inject("PUT /v1/blobs/{digest}", apitest.Failure{Status: http.StatusServiceUnavailable, Times: 1})
+
+	m, err := buildcontext.Scan(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := buildcontext.Upload(context.Background(), client, dir, m, buildcontext.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := blobRequests(s); n != 2 {
+		t.Errorf("%d blob requests, want 2", n)
+	}
+}
+
+func TestUploadLargeFiles(t *testing.T) {
+	client, s := newClient(t)
+	large := strings.Repeat("large", 1000)
+	dir := writeTree(t, map[string]string{
+		"Dockerfile": "FROM scratch",
+		"data.bin":   large,
+	})
+
+	m, err := buildcontext.Scan(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := buildcontext.Options{LargeFileSize: 1024, JournalDir: t.TempDir()}
+	bc, stats, err := buildcontext.Upload(context.Background(), client, dir, m, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the Dockerfile is sent as a single request.
+	if stats.Uploaded != 2 || blobRequests(s) != 1 {
+		t.Errorf("stats = %+v with %d blob requests", stats, blobRequests(s))
+	}
+	manifest, ok := s.Context(bc.ID)
+	if !ok {
+		t.Fatalf("context %s not found", bc.ID)
+	}
+	for _, f := range manifest.Files {
+		if blob, ok := s.Blob(f.Digest); f.Path == "data.bin" && (!ok || string(blob) != large) {
+			t.Error("blob of data.bin doesn't match the file")
+		}
+	}
+}
+
+func TestUploadChangedFile(t *testing.T) {
+	client, s := newClient(t)
+	dir := writeTree(t, map[string]string{"Dockerfile": "FROM scratch"})
+
+	m, err := buildcontext.Scan(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = buildcontext.Upload(context.Background(), client, dir, m, buildcontext.Options{})
+	if err == nil || !strings.Contains(err.Error(), "changed during the deploy") {
+		t.Fatalf("err = %v", err)
+	}
+	// The request is cut when the digest doesn't match, and sending the
+	// file again wouldn't help.
+	if n := blobRequests(s); n > 1 {
+		t.Errorf("%d blob requests, want at most 1", n)
+	}
+}
@@ -0,0 +1,122 @@
+package buildcontext
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile lists the files left out of the build context, like for
+// docker build.
+const IgnoreFile = ".dockerignore"
+
+// alwaysIgnored are never part of a build context.
+var alwaysIgnored = []string{".git"}
+
+// ignoreRule is a line of an ignore file.
+type ignoreRule struct {
+	segments []string
+	// negate re-includes files matched by earlier rules.
+	negate bool
+}
+
+// ignorer decides which files to leave out of a build context. Rules are
+// applied in order, the last matching one wins, and a rule matching a
+// directory matches everything in it.
+type ignorer struct {
+	rules []ignoreRule
+	// negations tells whether a directory excluded by a rule may have
+	// files included again by a later one.
+	negations bool
+}
+
+// readIgnoreFile reads the ignore file of dir, if any.
+func readIgnoreFile(dir string) (*ignorer, error) {
+	ig := &ignorer{}
+	for _, p := range alwaysIgnored {
+		ig.add(p)
+	}
+
+	f, err := os.Open(filepath.Join(dir, IgnoreFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return ig, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			return
+		}
+	}()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		ig.add(scanner.Text())
+	}
+	return ig, scanner.Err()
+}
+
+func (ig *ignorer) add(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+	rule := ignoreRule{}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		ig.negations = true
+		line = strings.TrimSpace(line[1:])
+	}
+	line = strings.Trim(path.Clean(filepath.ToSlash(line)), "/")
+	if line == "" || line == "." {
+		return
+	}
+	rule.segments = strings.Split(line, "/")
+	ig.rules = append(ig.rules, rule)
+}
+
+// ignored reports whether the file at the slash-separated path relative to
+// the context directory is left out.
+func (ig *ignorer) ignored(p string) bool {
+	segments := strings.Split(p, "/")
+	ignored := false
+	for _, rule := range ig.rules {
+		if rule.negate == !ignored {
+			continue
+		}
+		for n := 1; n <= len(segments); n++ {
+			if matchSegments(rule.segments, segments[:n]) {
+				ignored = !rule.negate
+				break
+			}
+		}
+	}
+	return ignored
+}
+
+// matchSegments matches a path against a pattern, segment by segment. A **
+// segment matches any number of segments, others are path.Match patterns.
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], name[1:])
+}
@@ -0,0 +1,296 @@
+package buildcontext
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/upload"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Defaults of Options.
+const (
+	// DefaultConcurrency is how many files are uploaded at once.
+	DefaultConcurrency = 8
+	// DefaultLargeFileSize is the size from which files are uploaded in
+	// parts.
+	DefaultLargeFileSize = 64 << 20
+)
+
+// Options configures an Upload. The zero value uses the defaults.
+type Options struct {
+	Concurrency int
+	// Attempts is how many times a file is sent before giving up.
+	Attempts int
+	// LargeFileSize is the size from which files are sent one at a time
+	// with resumable uploads in parts, instead of as a single request.
+	LargeFileSize int64
+	// JournalDir is where the progress of uploads in parts is recorded,
+	// see upload.Options.
+	JournalDir string
+	// Progress is where a progress bar is shown, none if nil.
+	Progress io.Writer
+}
+
+// Stats tells how much of a context was uploaded.
+type Stats struct {
+	Files int   `json:"files"`
+	Size  int64 `json:"size"`
+	// Uploaded is the number of files the API didn't have, of
+	// UploadedSize bytes sent as CompressedSize bytes. Large files are
+	// sent uncompressed.
+	Uploaded       int   `json:"uploaded"`
+	UploadedSize   int64 `json:"uploadedSize"`
+	CompressedSize int64 `json:"compressedSize"`
+}
+
+// Upload sends the files of the manifest of dir the API doesn't have yet,
+// then stores the context.
+func Upload(ctx context.Context, client *api.Client, dir string, manifest api.ContextManifest, opts Options) (api.BuildContext, Stats, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.Attempts <= 0 {
+		opts.Attempts = upload.DefaultAttempts
+	}
+	if opts.LargeFileSize <= 0 {
+		opts.LargeFileSize = DefaultLargeFileSize
+	}
+
+	stats := Stats{Files: len(manifest.Files)}
+	// Files with the same content are sent once.
+	byDigest := map[string]api.ContextFile{}
+	var digests []string
+	for _, f := range manifest.Files {
+		stats.Size += f.Size
+		if f.Target != "" {
+			continue
+		}
+		if _, ok := byDigest[f.Digest]; !ok {
+			byDigest[f.Digest] = f
+			digests = append(digests, f.Digest)
+		}
+	}
+
+	var missing, large []api.ContextFile
+	var missingSize int64
+	for start := 0; start < len(digests); start += api.MaxMissingBlobs {
+		batch, err := client.Blobs.Missing(digests[start:min(start+api.MaxMissingBlobs, len(digests))])
+		if err != nil {
+			return api.BuildContext{}, stats, err
+		}
+		for _, digest := range batch {
+			f, ok := byDigest[digest]
+			if !ok {
+				continue
+			}
+			if f.Size >= opts.LargeFileSize {
+				large = append(large, f)
+			} else {
+				missing = append(missing, f)
+				missingSize += f.Size
+			}
+			stats.Uploaded++
+			stats.UploadedSize += f.Size
+		}
+	}
+
+	u := &uploader{client: client, dir: dir, opts: opts}
+	for _, f := range large {
+		if err := u.sendLargeFile(ctx, f); err != nil {
+			return api.BuildContext{}, stats, err
+		}
+		stats.CompressedSize += f.Size
+	}
+	if len(missing) > 0 {
+		if opts.Progress != nil {
+			u.progress = cli.NewProgressBar(opts.Progress, fmt.Sprintf("Uploading %d file(s)", len(missing)), missingSize)
+		}
+		compressed, err := u.sendFiles(ctx, missing)
+		if err != nil {
+			return api.BuildContext{}, stats, err
+		}
+		if u.progress != nil {
+			u.progress.Finish()
+		}
+		stats.CompressedSize += compressed
+	}
+
+	bc, err := client.Contexts.Create(manifest)
+	if err != nil {
+		return api.BuildContext{}, stats, err
+	}
+	return bc, stats, nil
+}
+
+type uploader struct {
+	client   *api.Client
+	dir      string
+	opts     Options
+	progress *cli.ProgressBar
+}
+
+// sendFiles sends files in parallel, stopping at the first one that fails
+// after all its attempts. It returns the number of bytes sent.
+func (u *uploader) sendFiles(ctx context.Context, files []api.ContextFile) (int64, error) {
+	// Each worker streams files through its own encoder.
+	encoders := make([]*zstd.Encoder, min(u.opts.Concurrency, len(files)))
+	for i := range encoders {
+		encoder, err := zstd.NewWriter(nil)
+		if err != nil {
+			return 0, err
+		}
+		defer func() {
+			if err := encoder.Close(); err != nil {
+				return
+			}
+		}()
+		encoders[i] = encoder
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	todo := make(chan api.ContextFile)
+	go func() {
+		defer close(todo)
+		for _, f := range files {
+			select {
+			case todo <- f:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		sent     int64
+		firstErr error
+	)
+	for _, encoder := range encoders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range todo {
+				n, err := u.sendFile(ctx, encoder, f)
+				mu.Lock()
+				sent += n
+				if err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+				if err != nil {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr == nil {
+		// The parent context was canceled.
+		firstErr = ctx.Err()
+	}
+	return sent, firstErr
+}
+
+// sendFile streams a file compressed with encoder, checking that it didn't
+// change since it was scanned. It returns the number of bytes sent by the
+// last attempt.
+func (u *uploader) sendFile(ctx context.Context, encoder *zstd.Encoder, f api.ContextFile) (int64, error) {
+	var sent int64
+	err := upload.Retry(ctx, u.opts.Attempts, func(ctx context.Context) error {
+		r, w := io.Pipe()
+		counter := &countingWriter{w: w}
+		done := make(chan error, 1)
+		go func() {
+			err := u.compress(encoder, f, counter)
+			w.CloseWithError(err)
+			done <- err
+		}()
+
+		err := u.client.Blobs.Put(ctx, f.Digest, r)
+		// Unblock the compression if the request stopped reading.
+		r.CloseWithError(io.ErrClosedPipe)
+		compressErr := <-done
+		sent = counter.n
+		if compressErr != nil && !errors.Is(compressErr, io.ErrClosedPipe) {
+			return upload.Permanent(compressErr)
+		}
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	if u.progress != nil {
+		u.progress.Add(int(f.Size))
+	}
+	return sent, nil
+}
+
+// compress writes the file compressed to w, failing if its digest doesn't
+// match the scanned one.
+func (u *uploader) compress(encoder *zstd.Encoder, f api.ContextFile, w io.Writer) error {
+	file, err := os.Open(filepath.Join(u.dir, filepath.FromSlash(f.Path)))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			return
+		}
+	}()
+
+	encoder.Reset(w)
+	hash := sha256.New()
+	if _, err := io.Copy(encoder, io.TeeReader(file, hash)); err != nil {
+		return err
+	}
+	if "sha256:"+hex.EncodeToString(hash.Sum(nil)) != f.Digest {
+		return fmt.Errorf("%s changed during the deploy, try again", f.Path)
+	}
+	return encoder.Close()
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// sendLargeFile sends a file with a resumable upload in parts, then stores
+// it as a blob, checking that it didn't change since it was scanned.
+func (u *uploader) sendLargeFile(ctx context.Context, f api.ContextFile) error {
+	up, err := upload.File(ctx, u.client, filepath.Join(u.dir, filepath.FromSlash(f.Path)), upload.Options{
+		Name:       f.Path,
+		Attempts:   u.opts.Attempts,
+		JournalDir: u.opts.JournalDir,
+		Progress:   u.opts.Progress,
+	})
+	if err != nil {
+		return err
+	}
+	if "sha256:"+up.SHA256 != f.Digest {
+		return fmt.Errorf("%s changed during the deploy, try again", f.Path)
+	}
+	return upload.Retry(ctx, u.opts.Attempts, func(context.Context) error {
+		return u.client.Blobs.FromUpload(f.Digest, up.ID)
+	})
+}
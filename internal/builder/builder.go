@@ -0,0 +1,279 @@
+// Package builder builds container images locally and pushes them to the
+// a0 registry, with Docker, Podman or a BuildKit daemon.
+//
+// Builds run with the user's own registry credentials, so that base images
+// and dependencies can come from private registries. Pushes to the a0
+// registry use a temporary copy of the user's configuration with the a0
+// registry credentials, removed afterwards: the user's Docker
+// configuration is never modified. Docker pushes also keep the user's
+// contexts, to push from the daemon the image was built with.
+package builder
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+)
+
+// Names of the supported engines.
+const (
+	EngineDocker   = "docker"
+	EnginePodman   = "podman"
+	EngineBuildKit = "buildkit"
+)
+
+// Engines lists the supported engines, in the order they are detected.
+var Engines = []string{EngineDocker, EnginePodman, EngineBuildKit}
+
+// EnvBuildKitHost is the address of the BuildKit daemon, read by buildctl.
+const EnvBuildKitHost = "BUILDKIT_HOST"
+
+// DefaultPlatform is the platform apps run on.
+const DefaultPlatform = "linux/amd64"
+
+// ErrNoEngine is returned when no engine is installed.
+var ErrNoEngine = errors.New("no container engine found, install Docker or Podman, or set " + EnvBuildKitHost + " to the address of a BuildKit daemon")
+
+// Engine is a container engine images are built with.
+type Engine struct {
+	Name string
+	// path is the path of its command line tool.
+	path string
+}
+
+// binaries are the command line tools of the engines.
+var binaries = map[string]string{
+	EngineDocker:   "docker",
+	EnginePodman:   "podman",
+	EngineBuildKit: "buildctl",
+}
+
+// Detect returns the engine called name, or the first one installed if
+// name is empty. BuildKit is only detected when its address is set.
+func Detect(name string) (*Engine, error) {
+	if name != "" {
+		bin, ok := binaries[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown builder %q, expected one of %s", name, strings.Join(Engines, ", "))
+		}
+		path, err := exec.LookPath(bin)
+		if err != nil {
+			return nil, fmt.Errorf("%s not found: %w", bin, err)
+		}
+		if name == EngineBuildKit && os.Getenv(EnvBuildKitHost) == "" {
+			return nil, fmt.Errorf("set %s to the address of the BuildKit daemon", EnvBuildKitHost)
+		}
+		return &Engine{Name: name, path: path}, nil
+	}
+
+	for _, name := range Engines {
+		if name == EngineBuildKit && os.Getenv(EnvBuildKitHost) == "" {
+			continue
+		}
+		if path, err := exec.LookPath(binaries[name]); err == nil {
+			return &Engine{Name: name, path: path}, nil
+		}
+	}
+	return nil, ErrNoEngine
+}
+
+// BuildOptions describes an image to build and push.
+type BuildOptions struct {
+	// Dir is the build context, and Dockerfile the path of the Dockerfile
+	// relative to it.
+	Dir        string
+	Dockerfile string
+	// Image is the reference to push to, with a tag.
+	Image    string
+	Platform string
+	// Credentials authenticate the push to the registry of Image.
+	Credentials api.RegistryCredentials
+	// Output receives the output of the engine.
+	Output io.Writer
+}
+
+// BuildAndPush builds the image and pushes it, returning its digest.
+func (e *Engine) BuildAndPush(ctx context.Context, opts BuildOptions) (string, error) {
+	if opts.Platform == "" {
+		opts.Platform = DefaultPlatform
+	}
+	if opts.Output == nil {
+		opts.Output = io.Discard
+	}
+
+	authDir, err := os.MkdirTemp("", "a0ctl-push-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := os.RemoveAll(authDir); err != nil {
+			return
+		}
+	}()
+	// BuildKit pulls with the configuration it pushes with, and Docker
+	// pushes from the daemon of the current context. Podman only reads the
+	// credentials of its auth file.
+	config := map[string]any{}
+	if e.Name != EnginePodman {
+		path, err := DockerConfigPath()
+		if err != nil {
+			return "", err
+		}
+		if config, err = readDockerConfig(path); err != nil {
+			return "", err
+		}
+	}
+	if e.Name == EngineDocker {
+		if err := copyDockerContexts(authDir); err != nil {
+			return "", err
+		}
+	}
+	if err := writeAuthConfig(authDir, config, opts.Credentials); err != nil {
+		return "", fmt.Errorf("could not write registry credentials: %w", err)
+	}
+
+	switch e.Name {
+	case EngineDocker:
+		return e.dockerBuildAndPush(ctx, opts, authDir)
+	case EnginePodman:
+		return e.podmanBuildAndPush(ctx, opts, authDir)
+	default:
+		return e.buildKitBuildAndPush(ctx, opts, authDir)
+	}
+}
+
+func (e *Engine) dockerBuildAndPush(ctx context.Context, opts BuildOptions, authDir string) (string, error) {
+	err := e.run(ctx, opts.Output, nil, "build",
+		"--platform", opts.Platform,
+		"--file", filepath.Join(opts.Dir, opts.Dockerfile),
+		"--tag", opts.Image,
+		opts.Dir)
+	if err != nil {
+		return "", fmt.Errorf("build failed: %w", err)
+	}
+
+	var out bytes.Buffer
+	if err := e.run(ctx, io.MultiWriter(opts.Output, &out), nil, "--config", authDir, "push", opts.Image); err != nil {
+		return "", fmt.Errorf("push failed: %w", err)
+	}
+	return parsePushDigest(out.String())
+}
+
+func (e *Engine) podmanBuildAndPush(ctx context.Context, opts BuildOptions, authDir string) (string, error) {
+	err := e.run(ctx, opts.Output, nil, "build",
+		"--platform", opts.Platform,
+		"--file", filepath.Join(opts.Dir, opts.Dockerfile),
+		"--tag", opts.Image,
+		opts.Dir)
+	if err != nil {
+		return "", fmt.Errorf("build failed: %w", err)
+	}
+
+	digestFile := filepath.Join(authDir, "digest")
+	err = e.run(ctx, opts.Output, nil, "push",
+		"--authfile", filepath.Join(authDir, "config.json"),
+		"--digestfile", digestFile,
+		opts.Image)
+	if err != nil {
+		return "", fmt.Errorf("push failed: %w", err)
+	}
+	digest, err := os.ReadFile(digestFile)
+	if err != nil {
+		return "", fmt.Errorf("could not read the digest of the pushed image: %w", err)
+	}
+	return strings.TrimSpace(string(digest)), nil
+}
+
+// buildKitBuildAndPush builds and pushes in one step. buildctl finds the
+// registry credentials in the Docker configuration of DOCKER_CONFIG, the
+// user's one with the a0 registry credentials.
+func (e *Engine) buildKitBuildAndPush(ctx context.Context, opts BuildOptions, authDir string) (string, error) {
+	metadata := filepath.Join(authDir, "metadata.json")
+	dockerfile := filepath.Join(opts.Dir, opts.Dockerfile)
+	err := e.run(ctx, opts.Output, []string{"DOCKER_CONFIG=" + authDir}, "build",
+		"--frontend", "dockerfile.v0",
+		"--local", "context="+opts.Dir,
+		"--local", "dockerfile="+filepath.Dir(dockerfile),
+		"--opt", "filename="+filepath.Base(dockerfile),
+		"--opt", "platform="+opts.Platform,
+		"--output", "type=image,name="+opts.Image+",push=true",
+		"--metadata-file", metadata)
+	if err != nil {
+		return "", fmt.Errorf("build failed: %w", err)
+	}
+
+	data, err := os.ReadFile(metadata)
+	if err != nil {
+		return "", fmt.Errorf("could not read the digest of the pushed image: %w", err)
+	}
+	var meta struct {
+		Digest string `json:"containerimage.digest"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil || meta.Digest == "" {
+		return "", fmt.Errorf("no image digest in the build metadata")
+	}
+	return meta.Digest, nil
+}
+
+// run runs the engine with args, writing its output to w.
+func (e *Engine) run(ctx context.Context, w io.Writer, env []string, args ...string) error {
+	cmd := exec.CommandContext(ctx, e.path, args...)
+	cmd.Stdout = w
+	cmd.Stderr = w
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	return cmd.Run()
+}
+
+// writeAuthConfig writes the Docker configuration config in dir with the
+// credentials of the registry, also readable by Podman as an auth file.
+func writeAuthConfig(dir string, config map[string]any, creds api.RegistryCredentials) error {
+	auths, _ := config["auths"].(map[string]any)
+	if auths == nil {
+		auths = map[string]any{}
+	}
+	auths[creds.Registry] = map[string]string{
+		"auth": base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password)),
+	}
+	config["auths"] = auths
+	// An empty helper makes Docker read the credentials of the registry
+	// from the file, even when the user stores the others with credsStore.
+	helpers, _ := config["credHelpers"].(map[string]any)
+	if helpers != nil || config["credsStore"] != nil {
+		if helpers == nil {
+			helpers = map[string]any{}
+		}
+		helpers[creds.Registry] = ""
+		config["credHelpers"] = helpers
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "config.json"), data, 0o600)
+}
+
+var pushDigest = regexp.MustCompile(`digest: (sha256:[0-9a-f]{64})`)
+
+// parsePushDigest finds the digest of the image in the output of docker
+// push.
+func parsePushDigest(output string) (string, error) {
+	m := pushDigest.FindStringSubmatch(output)
+	if m == nil {
+		return "", errors.New("no image digest in the output of docker push")
+	}
+	return m[1], nil
+}
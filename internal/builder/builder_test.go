@@ -0,0 +1,333 @@
+package builder
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+)
+
+const testDigest = "sha256:3f8a2b1c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8"
+
+var testCredentials = api.RegistryCredentials{
+	Registry:  "registry.a0.test",
+	Namespace: "jane",
+	Username:  "jane",
+	Password:  "secret",
+}
+
+// fakeEngine installs an executable called name in a new directory that
+// is the only one in PATH. It runs script with sh, which can only use
+// builtins.
+func fakeEngine(t *testing.T, name, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake engines are shell scripts")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestDetect(t *testing.T) {
+	fakeEngine(t, "podman", "")
+	t.Setenv(EnvBuildKitHost, "")
+
+	e, err := Detect("")
+	if err != nil || e.Name != EnginePodman {
+		t.Fatalf("Detect() = %+v, %v, want podman", e, err)
+	}
+	if _, err := Detect(EngineDocker); err == nil || !strings.Contains(err.Error(), "docker not found") {
+		t.Errorf("Detect(docker) error = %v", err)
+	}
+	if _, err := Detect("kaniko"); err == nil || !strings.Contains(err.Error(), "unknown builder") {
+		t.Errorf("Detect(kaniko) error = %v", err)
+	}
+}
+
+func TestDetectBuildKit(t *testing.T) {
+	fakeEngine(t, "buildctl", "")
+	t.Setenv(EnvBuildKitHost, "")
+
+	if _, err := Detect(""); !errors.Is(err, ErrNoEngine) {
+		t.Errorf("Detect() without %s error = %v, want ErrNoEngine", EnvBuildKitHost, err)
+	}
+	if _, err := Detect(EngineBuildKit); err == nil || !strings.Contains(err.Error(), EnvBuildKitHost) {
+		t.Errorf("Detect(buildkit) without %s error = %v", EnvBuildKitHost, err)
+	}
+
+	t.Setenv(EnvBuildKitHost, "tcp://127.0.0.1:1234")
+	if e, err := Detect(""); err != nil || e.Name != EngineBuildKit {
+		t.Errorf("Detect() = %+v, %v, want buildkit", e, err)
+	}
+}
+
+func TestBuildAndPushDocker(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "log")
+	fakeEngine(t, "docker", `
+echo "$@" >> `+log+`
+if [ "$1" = "--config" ]; then
+	read -r auth < "$2/config.json"
+	echo "$auth" > `+log+`.auth
+	echo "latest: digest: `+testDigest+` size: 1234"
+fi
+`)
+	t.Setenv(EnvDockerConfig, t.TempDir())
+	e, err := Detect(EngineDocker)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	digest, err := e.BuildAndPush(context.Background(), BuildOptions{
+		Dir:         dir,
+		Dockerfile:  "Dockerfile",
+		Image:       "registry.a0.test/jane/web:v1",
+		Credentials: testCredentials,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != testDigest {
+		t.Errorf("digest = %q, want %q", digest, testDigest)
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	wantBuild := "build --platform " + DefaultPlatform + " --file " + filepath.Join(dir, "Dockerfile") + " --tag registry.a0.test/jane/web:v1 " + dir
+	if len(lines) != 2 || lines[0] != wantBuild || !strings.HasSuffix(lines[1], " push registry.a0.test/jane/web:v1") {
+		t.Errorf("docker runs = %q", lines)
+	}
+
+	var auth struct {
+		Auths map[string]struct{ Auth string } `json:"auths"`
+	}
+	data, err = os.ReadFile(log + ".auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &auth); err != nil {
+		t.Fatal(err)
+	}
+	if got := auth.Auths["registry.a0.test"].Auth; got != base64.StdEncoding.EncodeToString([]byte("jane:secret")) {
+		t.Errorf("push auth = %q", got)
+	}
+}
+
+func TestBuildAndPushDockerContext(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "log")
+	fakeEngine(t, "docker", `
+if [ "$1" = "--config" ]; then
+	read -r config < "$2/config.json"
+	echo "$config" > `+log+`
+	read -r meta < "$2/contexts/meta/colima/meta.json"
+	echo "$meta" > `+log+`.context
+	echo "latest: digest: `+testDigest+` size: 1234"
+fi
+`)
+	user := t.TempDir()
+	t.Setenv(EnvDockerConfig, user)
+	userConfig := `{"currentContext": "colima"}`
+	if err := os.WriteFile(filepath.Join(user, "config.json"), []byte(userConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	meta := `{"Name": "colima", "Endpoints": {"docker": {"Host": "unix:///colima/docker.sock"}}}`
+	if err := os.MkdirAll(filepath.Join(user, "contexts", "meta", "colima"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(user, "contexts", "meta", "colima", "meta.json"), []byte(meta), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := Detect(EngineDocker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.BuildAndPush(context.Background(), BuildOptions{
+		Dir:         t.TempDir(),
+		Dockerfile:  "Dockerfile",
+		Image:       "registry.a0.test/jane/web:v1",
+		Credentials: testCredentials,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The push uses the daemon of the context the image was built with.
+	var config struct {
+		CurrentContext string                           `json:"currentContext"`
+		Auths          map[string]struct{ Auth string } `json:"auths"`
+	}
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.CurrentContext != "colima" || config.Auths["registry.a0.test"].Auth == "" {
+		t.Errorf("push config = %s", data)
+	}
+	if data, err := os.ReadFile(log + ".context"); err != nil || strings.TrimSpace(string(data)) != meta {
+		t.Errorf("context of the push = %q, %v", data, err)
+	}
+}
+
+func TestBuildAndPushBuildKit(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "log")
+	fakeEngine(t, "buildctl", `
+while [ $# -gt 0 ]; do
+	if [ "$1" = "--metadata-file" ]; then
+		echo '{"containerimage.digest": "`+testDigest+`"}' > "$2"
+	fi
+	shift
+done
+read -r config < "$DOCKER_CONFIG/config.json"
+echo "$config" > `+log+`
+`)
+	t.Setenv(EnvBuildKitHost, "tcp://127.0.0.1:1234")
+	user := t.TempDir()
+	t.Setenv(EnvDockerConfig, user)
+	userConfig := `{"auths": {"ghcr.io": {"auth": "dXNlcjpwYXNz"}}, "credsStore": "desktop"}`
+	if err := os.WriteFile(filepath.Join(user, "config.json"), []byte(userConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := Detect(EngineBuildKit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := e.BuildAndPush(context.Background(), BuildOptions{
+		Dir:         t.TempDir(),
+		Dockerfile:  "Dockerfile",
+		Image:       "registry.a0.test/jane/web:v1",
+		Credentials: testCredentials,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != testDigest {
+		t.Errorf("digest = %q, want %q", digest, testDigest)
+	}
+
+	var config struct {
+		Auths       map[string]struct{ Auth string } `json:"auths"`
+		CredHelpers map[string]string                `json:"credHelpers"`
+		CredsStore  string                           `json:"credsStore"`
+	}
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	// The user's credentials are kept for pulls.
+	if config.Auths["ghcr.io"].Auth != "dXNlcjpwYXNz" || config.CredsStore != "desktop" {
+		t.Errorf("user credentials missing from %s", data)
+	}
+	if got := config.Auths["registry.a0.test"].Auth; got != base64.StdEncoding.EncodeToString([]byte("jane:secret")) {
+		t.Errorf("push auth = %q", got)
+	}
+	if helper, ok := config.CredHelpers["registry.a0.test"]; !ok || helper != "" {
+		t.Errorf("credHelpers = %v, want an empty helper for the a0 registry", config.CredHelpers)
+	}
+
+	// The user's configuration is left as is.
+	if data, _ := os.ReadFile(filepath.Join(user, "config.json")); string(data) != userConfig {
+		t.Errorf("user config = %s", data)
+	}
+}
+
+func TestBuildAndPushFailure(t *testing.T) {
+	fakeEngine(t, "docker", `echo "failed to solve" >&2; exit 1`)
+	e, err := Detect(EngineDocker)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	_, err = e.BuildAndPush(context.Background(), BuildOptions{Dir: t.TempDir(), Dockerfile: "Dockerfile", Image: "x:v1", Output: &out})
+	if err == nil || !strings.Contains(err.Error(), "build failed") {
+		t.Errorf("err = %v", err)
+	}
+	if !strings.Contains(out.String(), "failed to solve") {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func TestParsePushDigest(t *testing.T) {
+	out := "The push refers to repository [registry.a0.test/jane/web]\n" +
+		"5f70bf18a086: Pushed\n" +
+		"v1: digest: " + testDigest + " size: 528\n"
+	if digest, err := parsePushDigest(out); err != nil || digest != testDigest {
+		t.Errorf("parsePushDigest() = %q, %v", digest, err)
+	}
+	if _, err := parsePushDigest("denied: requested access to the resource is denied\n"); err == nil {
+		t.Error("parsePushDigest() without digest succeeded")
+	}
+}
+
+func TestUseCredentialHelper(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docker", "config.json")
+
+	// A new configuration is created.
+	if err := UseCredentialHelper(path, "registry.a0.test"); err != nil {
+		t.Fatal(err)
+	}
+
+	// An existing one is kept, apart from stale credentials.
+	existing := `{
+	"auths": {"registry.a0.test": {"auth": "c3RhbGU="}, "ghcr.io": {"auth": "Z2g="}},
+	"credHelpers": {"gcr.io": "gcloud"},
+	"currentContext": "colima"
+}`
+	if err := os.WriteFile(path, []byte(existing), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := UseCredentialHelper(path, "registry.a0.test"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		Auths          map[string]any    `json:"auths"`
+		CredHelpers    map[string]string `json:"credHelpers"`
+		CurrentContext string            `json:"currentContext"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := config.Auths["registry.a0.test"]; ok || len(config.Auths) != 1 {
+		t.Errorf("auths = %v", config.Auths)
+	}
+	if config.CredHelpers["registry.a0.test"] != CredentialHelper || config.CredHelpers["gcr.io"] != "gcloud" {
+		t.Errorf("credHelpers = %v", config.CredHelpers)
+	}
+	if config.CurrentContext != "colima" {
+		t.Errorf("currentContext = %q", config.CurrentContext)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := UseCredentialHelper(path, "registry.a0.test"); err == nil {
+		t.Error("UseCredentialHelper() with an invalid configuration succeeded")
+	}
+}
@@ -0,0 +1,102 @@
+package builder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CredentialHelper is the name of the Docker credential helper of a0ctl.
+// Docker runs it as docker-credential-a0ctl, a link to a0ctl.
+const CredentialHelper = "a0ctl"
+
+// CredentialHelperPrefix starts the names of Docker credential helpers.
+const CredentialHelperPrefix = "docker-credential-"
+
+// EnvDockerConfig is the directory of the Docker configuration, read by
+// Docker itself.
+const EnvDockerConfig = "DOCKER_CONFIG"
+
+// DockerConfigPath returns the path of the Docker configuration file of
+// the user.
+func DockerConfigPath() (string, error) {
+	if dir := os.Getenv(EnvDockerConfig); dir != "" {
+		return filepath.Join(dir, "config.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".docker", "config.json"), nil
+}
+
+// UseCredentialHelper configures Docker to get the credentials of registry
+// from the a0ctl credential helper, keeping the rest of the configuration
+// file at path as is.
+func UseCredentialHelper(path, registry string) error {
+	config, err := readDockerConfig(path)
+	if err != nil {
+		return err
+	}
+
+	helpers, _ := config["credHelpers"].(map[string]any)
+	if helpers == nil {
+		helpers = map[string]any{}
+	}
+	helpers[registry] = CredentialHelper
+	config["credHelpers"] = helpers
+	// Credentials stored by a previous docker login would be used instead.
+	if auths, ok := config["auths"].(map[string]any); ok {
+		delete(auths, registry)
+	}
+
+	data, err := json.MarshalIndent(config, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readDockerConfig returns the Docker configuration file at path, empty if
+// it doesn't exist.
+func readDockerConfig(path string) (map[string]any, error) {
+	config := map[string]any{}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", path, err)
+		}
+	}
+	return config, nil
+}
+
+// copyDockerContexts copies the contexts of the user's Docker
+// configuration to the configuration directory dir, so that Docker keeps
+// using the daemon of the current context.
+func copyDockerContexts(dir string) error {
+	path, err := DockerConfigPath()
+	if err != nil {
+		return err
+	}
+	contexts := filepath.Join(filepath.Dir(path), "contexts")
+	if _, err := os.Stat(contexts); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err := os.CopyFS(filepath.Join(dir, "contexts"), os.DirFS(contexts)); err != nil {
+		return fmt.Errorf("could not copy the Docker contexts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+// Package buildinfo describes the running a0ctl build. Release builds set
+// the variables at link time, e.g.
+//
+//	go build -ldflags "-X github.com/a0dotrun/a0ctl/internal/buildinfo.Version=v1.2.3"
+package buildinfo
+
+// Version is the released version of a0ctl, "dev" for local builds.
+var Version = "dev"
+
+// IsDev reports whether this is a local build rather than a release.
+func IsDev() bool {
+	return Version == "dev"
+}
@@ -0,0 +1,13 @@
+package cli
+
+import "fmt"
+
+// ExitError makes the CLI exit with Code without printing an error, e.g. to
+// pass on the exit code of a remote command.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
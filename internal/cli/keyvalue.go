@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ParseKeyValues parses KEY=VALUE arguments. A value of "-" is read from
+// stdin and a value starting with "@" is read from the named file, so that
+// sensitive values don't have to be passed on the command line.
+func ParseKeyValues(args []string, stdin io.Reader) (map[string]string, error) {
+	vars := make(map[string]string, len(args))
+	stdinUsed := false
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid argument %q: expected KEY=VALUE", arg)
+		}
+
+		switch {
+		case value == "-":
+			if stdinUsed {
+				return nil, fmt.Errorf("only one value can be read from stdin")
+			}
+			stdinUsed = true
+			data, err := io.ReadAll(stdin)
+			if err != nil {
+				return nil, fmt.Errorf("could not read value of %s from stdin: %w", key, err)
+			}
+			value = strings.TrimSuffix(string(data), "\n")
+		case strings.HasPrefix(value, "@"):
+			data, err := os.ReadFile(value[1:])
+			if err != nil {
+				return nil, fmt.Errorf("could not read value of %s: %w", key, err)
+			}
+			value = string(data)
+		}
+
+		vars[key] = value
+	}
+	return vars, nil
+}
@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	spinnerInterval = 100 * time.Millisecond
+	// plainInterval is how often progress is reported when the output is
+	// not a terminal, e.g. in CI logs.
+	plainInterval = 10 * time.Second
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Spinner shows that a long operation is in progress. On terminals it
+// animates on a single line, otherwise it prints the message periodically.
+type Spinner struct {
+	w           io.Writer
+	interactive bool
+
+	mu      sync.Mutex
+	message string
+	start   time.Time
+	done    chan struct{}
+	stopped sync.WaitGroup
+}
+
+// NewSpinner creates a spinner writing to w. The animation is only used
+// when w is a terminal.
+func NewSpinner(w io.Writer, message string) *Spinner {
+	return &Spinner{w: w, interactive: isTerminalWriter(w), message: message}
+}
+
+// Start starts the spinner.
+func (s *Spinner) Start() {
+	s.start = time.Now()
+	s.done = make(chan struct{})
+	s.stopped.Add(1)
+
+	if !s.interactive {
+		fmt.Fprintf(s.w, "%s...\n", s.message)
+	}
+	go s.run()
+}
+
+// Update changes the message shown by the spinner.
+func (s *Spinner) Update(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.message = message
+}
+
+// Stop stops the spinner and prints a final message, if not empty.
+func (s *Spinner) Stop(final string) {
+	close(s.done)
+	s.stopped.Wait()
+
+	if s.interactive {
+		fmt.Fprint(s.w, "\r\033[K")
+	}
+	if final != "" {
+		fmt.Fprintln(s.w, final)
+	}
+}
+
+func (s *Spinner) run() {
+	defer s.stopped.Done()
+
+	interval := plainInterval
+	if s.interactive {
+		interval = spinnerInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for frame := 0; ; frame++ {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		s.mu.Lock()
+		message := s.message
+		s.mu.Unlock()
+
+		elapsed := time.Since(s.start).Round(time.Second)
+		if s.interactive {
+			fmt.Fprintf(s.w, "\r\033[K%s %s (%s)", spinnerFrames[frame%len(spinnerFrames)], message, elapsed)
+		} else {
+			fmt.Fprintf(s.w, "%s... (%s)\n", message, elapsed)
+		}
+	}
+}
+
+// ProgressBar tracks a transfer of a known number of bytes, showing the
+// rate and estimated time left.
+type ProgressBar struct {
+	w           io.Writer
+	interactive bool
+	label       string
+	total       int64
+
+	mu         sync.Mutex
+	current    int64
+	start      time.Time
+	lastRender time.Time
+	// rendered is the byte count last shown.
+	rendered int64
+}
+
+// NewProgressBar creates a progress bar for total bytes writing to w. A
+// total of zero or less means the size is unknown.
+func NewProgressBar(w io.Writer, label string, total int64) *ProgressBar {
+	return &ProgressBar{
+		w:           w,
+		interactive: isTerminalWriter(w),
+		label:       label,
+		total:       total,
+		start:       time.Now(),
+	}
+}
+
+// Add records n more bytes as transferred.
+func (p *ProgressBar) Add(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.current += int64(n)
+	interval := plainInterval
+	if p.interactive {
+		interval = spinnerInterval
+	}
+	if time.Since(p.lastRender) >= interval || (p.total > 0 && p.current >= p.total) {
+		p.render()
+	}
+}
+
+// Set resets the number of transferred bytes, e.g. when a transfer resumes.
+func (p *ProgressBar) Set(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = n
+	p.start = time.Now()
+}
+
+// Finish prints the final state of the progress bar.
+func (p *ProgressBar) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.interactive || p.rendered != p.current || p.lastRender.IsZero() {
+		p.render()
+	}
+	if p.interactive {
+		fmt.Fprintln(p.w)
+	}
+}
+
+// Reader wraps r so that reads are reported to the progress bar.
+func (p *ProgressBar) Reader(r io.Reader) io.Reader {
+	return &progressReader{r: r, bar: p}
+}
+
+func (p *ProgressBar) render() {
+	p.lastRender = time.Now()
+	p.rendered = p.current
+
+	percent := 100.0
+	if p.total > 0 {
+		percent = float64(p.current) / float64(p.total) * 100
+	}
+
+	elapsed := time.Since(p.start).Seconds()
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(p.current) / elapsed
+	}
+	eta := "-"
+	if rate > 0 && p.current < p.total {
+		eta = (time.Duration(float64(p.total-p.current)/rate) * time.Second).Round(time.Second).String()
+	}
+
+	stats := fmt.Sprintf("%5.1f%% %s/%s %s/s ETA %s",
+		percent, FormatBytes(p.current), FormatBytes(p.total), FormatBytes(int64(rate)), eta)
+	if p.total <= 0 {
+		// The size is unknown, e.g. for responses without Content-Length.
+		stats = fmt.Sprintf("%s %s/s", FormatBytes(p.current), FormatBytes(int64(rate)))
+	}
+	if !p.interactive {
+		fmt.Fprintf(p.w, "%s: %s\n", p.label, stats)
+		return
+	}
+
+	const width = 30
+	filled := int(percent / 100 * width)
+	filled = min(max(filled, 0), width)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", width-filled)
+	fmt.Fprintf(p.w, "\r\033[K%s [%s] %s", p.label, bar, stats)
+}
+
+type progressReader struct {
+	r   io.Reader
+	bar *ProgressBar
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.bar.Add(n)
+	return n, err
+}
+
+// FormatBytes formats n bytes with a binary unit, e.g. 1.5MiB.
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func isTerminalWriter(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && IsTerminal(f)
+}
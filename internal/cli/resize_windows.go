@@ -0,0 +1,39 @@
+//go:build windows
+
+package cli
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// NotifyResize signals on the returned channel whenever the terminal window
+// is resized, until ctx is done. Windows has no resize signal, so the size
+// is polled.
+func NotifyResize(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	go func() {
+		ticker := time.NewTicker(250 * time.Millisecond)
+		defer ticker.Stop()
+
+		cols, rows, _ := TerminalSize(os.Stdout)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c, r, err := TerminalSize(os.Stdout)
+				if err != nil || (c == cols && r == rows) {
+					continue
+				}
+				cols, rows = c, r
+				select {
+				case ch <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return ch
+}
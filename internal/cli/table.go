@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Table writes aligned columns of text.
+type Table struct {
+	w *tabwriter.Writer
+}
+
+// NewTable creates a table writing to w with the given column headers.
+func NewTable(w io.Writer, header ...string) *Table {
+	t := &Table{w: tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)}
+	if len(header) > 0 {
+		t.Row(header...)
+	}
+	return t
+}
+
+// Row adds a row of cells to the table.
+func (t *Table) Row(cells ...string) {
+	_, _ = fmt.Fprintln(t.w, strings.Join(cells, "\t"))
+}
+
+// Flush writes the table to the underlying writer.
+func (t *Table) Flush() error {
+	return t.w.Flush()
+}
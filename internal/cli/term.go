@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// IsTerminal reports whether f is connected to a terminal.
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// MakeRaw puts the terminal f into raw mode and returns a function that
+// restores its previous state.
+func MakeRaw(f *os.File) (func(), error) {
+	state, err := term.MakeRaw(int(f.Fd()))
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		_ = term.Restore(int(f.Fd()), state)
+	}, nil
+}
+
+// TerminalSize returns the width and height of the terminal f.
+func TerminalSize(f *os.File) (cols, rows int, err error) {
+	return term.GetSize(int(f.Fd()))
+}
+
+// Terminal returns the file behind stream if it is a terminal, or nil, e.g.
+// for the streams of a command run in tests.
+func Terminal(stream any) *os.File {
+	if f, ok := stream.(*os.File); ok && IsTerminal(f) {
+		return f
+	}
+	return nil
+}
@@ -48,8 +48,8 @@ func checkEnvAuth(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func validateToken(token string) (string, error) {
-	client, err := api.MakeClient(token)
+func validateToken(config *settings.Settings, token string) (string, error) {
+	client, err := api.MakeClient(config, token)
 	if err != nil {
 		return "", fmt.Errorf("could not create client to validate token: %w", err)
 	}
@@ -0,0 +1,99 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api/apitest"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdtest"
+	"github.com/a0dotrun/a0ctl/internal/settings"
+)
+
+func TestWhoAmI(t *testing.T) {
+	tests := []struct {
+		name     string
+		login    bool
+		envToken string
+		want     string
+		wantErr  string
+	}{
+		{name: "logged in", login: true, want: apitest.DefaultUsername + "\n"},
+		{name: "logged out", wantErr: "not logged in"},
+		{name: "env token", envToken: "ci-token", want: "ci\n"},
+		{name: "env token wins", login: true, envToken: "ci-token", want: "ci\n"},
+		{name: "invalid env token", login: true, envToken: "bad", wantErr: "A0_API_TOKEN env var is invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := cmdtest.New(t)
+			e.Server.AddUser("ci", "ci-token")
+			if tt.login {
+				e.Login()
+			}
+			t.Setenv(settings.EnvAccessToken, tt.envToken)
+
+			res := e.Run("auth", "whoami")
+			if tt.wantErr != "" {
+				if res.Err == nil || !strings.Contains(res.Err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", res.Err, tt.wantErr)
+				}
+				return
+			}
+			if res.Err != nil {
+				t.Fatal(res.Err)
+			}
+			if res.Stdout != tt.want {
+				t.Errorf("stdout = %q, want %q", res.Stdout, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+
+	res := e.MustRun("auth", "logout", "--yes")
+	if !strings.Contains(res.Stdout, "Logged out.") {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+	for _, key := range []string{"token", "username"} {
+		if got := e.Setting("profiles.default." + key); got != "" {
+			t.Errorf("%s = %v after logout", key, got)
+		}
+	}
+
+	res = e.MustRun("auth", "logout")
+	if !strings.Contains(res.Stdout, "No user logged in.") {
+		t.Errorf("second logout stdout = %q", res.Stdout)
+	}
+}
+
+func TestLogoutRefusesEnvToken(t *testing.T) {
+	e := cmdtest.New(t)
+	t.Setenv(settings.EnvAccessToken, apitest.DefaultToken)
+
+	res := e.Run("auth", "logout", "--yes")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "please unset it") {
+		t.Errorf("err = %v", res.Err)
+	}
+}
+
+func TestLoginWithValidToken(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+
+	res := e.MustRun("auth", "login", "-o", "json")
+	if !strings.Contains(res.Stdout, `"username": "`+apitest.DefaultUsername+`"`) {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+}
+
+func TestLoginHeadless(t *testing.T) {
+	e := cmdtest.New(t)
+
+	res := e.MustRun("auth", "login", "--headless")
+	if !strings.Contains(res.Stdout, e.Server.URL+"/auth/cli") {
+		t.Errorf("stdout doesn't point at the auth URL of the settings: %q", res.Stdout)
+	}
+}
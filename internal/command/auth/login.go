@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"html/template"
+	"io"
 	"log"
 	"math/rand"
 	"net"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
 	"github.com/a0dotrun/a0ctl/internal/flags"
 	"github.com/a0dotrun/a0ctl/internal/settings"
 
@@ -43,30 +45,37 @@ func newLogin() *cobra.Command {
 	return cmd
 }
 
-func exitOnValidAuth(settings *settings.Settings) {
+// loginResult is the output of a successful login.
+type loginResult struct {
+	Username string `json:"username"`
+}
+
+func exitOnValidAuth(cmd *cobra.Command, settings *settings.Settings) error {
 	username := settings.GetUsername()
-	if len(username) <= 0 {
-		fmt.Println("✔  Success! Existing JWT still valid")
-		return
-	}
-	fmt.Printf("Already signed in as %s. Use %s to log out of this account\n", username, cli.Emph("a0ctl auth logout"))
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), loginResult{Username: username}, func(w io.Writer) error {
+		if len(username) <= 0 {
+			_, err := fmt.Fprintln(w, "✔  Success! Existing JWT still valid")
+			return err
+		}
+		_, err := fmt.Fprintf(w, "Already signed in as %s. Use %s to log out of this account\n", username, cli.Emph("a0ctl auth logout"))
+		return err
+	})
 }
 
 func login(cmd *cobra.Command, args []string) error {
 	cmd.SilenceUsage = true
 
-	config, err := settings.ReadSettings()
+	config, err := cmdutil.Settings(cmd)
 	if err != nil {
 		return fmt.Errorf("could not retrieve local config: %w", err)
 	}
 
-	if api.IsJWTTokenValid(config.GetToken()) {
-		exitOnValidAuth(config)
-		return nil
+	if api.IsJWTTokenValid(config, config.GetToken()) {
+		return exitOnValidAuth(cmd, config)
 	}
 
 	if flags.Headless() {
-		return printHeadlessLoginInstructions(authURLPath)
+		return printHeadlessLoginInstructions(cmd, config.A0HomeURL(), authURLPath)
 	}
 
 	state := randString(32)
@@ -78,7 +87,7 @@ func login(cmd *cobra.Command, args []string) error {
 	// Now, that we got the callback server, let's get the auth URL
 	// for making the auth request
 
-	url, err := authURL(callbackServer.Port, authURLPath, state)
+	url, err := authURL(config.A0HomeURL(), callbackServer.Port, authURLPath, state)
 	if err != nil {
 		return fmt.Errorf("failed to get auth URL: %w", err)
 	}
@@ -88,16 +97,19 @@ func login(cmd *cobra.Command, args []string) error {
 		return suggestHeadless(cmd, err)
 	}
 
-	fmt.Println("Opening your browser at:")
-	fmt.Println(url)
-	fmt.Println("Waiting for authentication...")
+	messages := cmdutil.Messages(cmd)
+	fmt.Fprintln(messages, "Opening your browser at:")
+	fmt.Fprintln(messages, url)
 
+	spinner := cli.NewSpinner(messages, "Waiting for authentication")
+	spinner.Start()
 	jwt, err := callbackServer.Result()
+	spinner.Stop("")
 	if err != nil {
 		return suggestHeadless(cmd, err)
 	}
 
-	username, err := validateToken(jwt)
+	username, err := validateToken(config, jwt)
 	if err != nil {
 		return suggestHeadless(cmd, err)
 	}
@@ -105,11 +117,14 @@ func login(cmd *cobra.Command, args []string) error {
 	config.SetToken(jwt)
 	config.SetUsername(username)
 
-	settings.PersistChanges()
-
-	fmt.Printf("✔  Success! Logged in as %s\n", username)
+	if err := config.Persist(); err != nil {
+		fmt.Fprintln(cmd.ErrOrStderr(), err.Error())
+	}
 
-	return nil
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), loginResult{Username: username}, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "✔  Success! Logged in as %s\n", username)
+		return err
+	})
 }
 
 func randString(n int) string {
@@ -121,8 +136,8 @@ func randString(n int) string {
 	return string(b)
 }
 
-func authURL(port int, path, state string) (string, error) {
-	base, err := url.Parse(settings.GetA0HomeURL())
+func authURL(homeURL string, port int, path, state string) (string, error) {
+	base, err := url.Parse(homeURL)
 	if err != nil {
 		return "", fmt.Errorf("error parsing auth URL: %w", err)
 	}
@@ -228,12 +243,16 @@ func suggestHeadless(cmd *cobra.Command, err error) error {
 	return fmt.Errorf("%w\nIf the issue persists, try running %s", err, cli.Emph(cmdWithFlag))
 }
 
-func printHeadlessLoginInstructions(path string) error {
-	url, err := authURL(0, path, "")
+func printHeadlessLoginInstructions(cmd *cobra.Command, homeURL, path string) error {
+	url, err := authURL(homeURL, 0, path, "")
 	if err != nil {
 		return err
 	}
-	fmt.Println("Visit the following URL to login:")
-	fmt.Println(url)
-	return nil
+	result := struct {
+		URL string `json:"url"`
+	}{url}
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), result, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "Visit the following URL to login:\n%s\n", url)
+		return err
+	})
 }
@@ -4,7 +4,8 @@ import (
 	"fmt"
 
 	"github.com/a0dotrun/a0ctl/internal/cli"
-	"github.com/a0dotrun/a0ctl/internal/settings"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/prompt"
 
 	"github.com/spf13/cobra"
 
@@ -29,25 +30,35 @@ func newLogout() *cobra.Command {
 
 func logout(cmd *cobra.Command, args []string) error {
 	cmd.SilenceUsage = true
-	settings, err := settings.ReadSettings()
+	config, err := cmdutil.Settings(cmd)
 	if err != nil {
 		return fmt.Errorf("could not retrieve local config: %w", err)
 	}
 
-	if token := settings.GetToken(); len(token) == 0 {
-		fmt.Println("No user logged in.")
-		return nil
+	if token := config.GetToken(); len(token) == 0 {
+		return cmdutil.Output(cmd).PrintMessage(cmd.OutOrStdout(), "No user logged in.")
+	}
+
+	question := "Log out?"
+	if username := config.GetUsername(); username != "" {
+		question = fmt.Sprintf("Log out %s?", username)
+	}
+	if err := prompt.ConfirmAction(cmd, question); err != nil {
+		return err
 	}
 
 	// if err := invalidateSessionsIfRequested(); err != nil {
 	// 	return err
 	// }
 
-	settings.SetToken("")
-	settings.SetUsername("")
-	fmt.Println("Logged out.")
+	config.SetToken("")
+	config.SetUsername("")
+	config.SetOrg("")
+	if err := config.Persist(); err != nil {
+		return err
+	}
 
-	return nil
+	return cmdutil.Output(cmd).PrintMessage(cmd.OutOrStdout(), "Logged out.")
 }
 
 // func invalidateSessionsIfRequested() error {
@@ -55,7 +66,7 @@ func logout(cmd *cobra.Command, args []string) error {
 // 		return nil
 // 	}
 
-// 	client, err := api.AuthedClient()
+// 	client, err := cmdutil.Client(cmd)
 // 	if err != nil {
 // 		return err
 // 	}
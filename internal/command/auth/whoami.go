@@ -2,9 +2,10 @@ package auth
 
 import (
 	"fmt"
+	"io"
 
-	"github.com/a0dotrun/a0ctl/internal/api"
 	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
 	"github.com/spf13/cobra"
 )
 
@@ -25,7 +26,7 @@ func newWhoAMI() *cobra.Command {
 
 func whoAmI(cmd *cobra.Command, _ []string) error {
 	cmd.SilenceUsage = true
-	client, err := api.AuthedClient()
+	client, err := cmdutil.Client(cmd)
 	if err != nil {
 		return err
 	}
@@ -35,6 +36,8 @@ func whoAmI(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	fmt.Printf("%s\n", user.Username)
-	return nil
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), user, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "%s\n", user.Username)
+		return err
+	})
 }
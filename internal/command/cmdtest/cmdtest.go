@@ -0,0 +1,151 @@
+// Package cmdtest runs a0ctl commands in tests, against a fake API and a
+// temporary settings directory.
+package cmdtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api/apitest"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/command/root"
+	"github.com/a0dotrun/a0ctl/internal/settings"
+)
+
+// Env is an isolated environment to run commands in. Commands share
+// global flag state, so tests using an Env must not run in parallel.
+type Env struct {
+	t testing.TB
+	// Server is the fake API the commands talk to.
+	Server *apitest.Server
+	// ConfigDir holds the settings file.
+	ConfigDir string
+}
+
+// Result is the outcome of a command.
+type Result struct {
+	Stdout string
+	Stderr string
+	Err    error
+}
+
+// New returns an Env whose settings point at a new fake API, logged out.
+// The environment variables read by a0ctl are cleared for the test.
+func New(t testing.TB) *Env {
+	t.Helper()
+	for _, name := range []string{
+		settings.EnvAccessToken, settings.EnvConfigPath, settings.EnvProfile,
+		settings.EnvBaseURL, settings.EnvHomeURL,
+	} {
+		t.Setenv(name, "")
+	}
+	// No update notices, and no completion cache shared between tests.
+	t.Setenv("CI", "1")
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	// The settings directory is private, like a0ctl creates it.
+	configDir := filepath.Join(t.TempDir(), "a0")
+	if err := os.Mkdir(configDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	e := &Env{t: t, Server: apitest.New(t), ConfigDir: configDir}
+	e.WriteSettings(map[string]any{
+		"version": 1,
+		"profiles": map[string]any{
+			settings.DefaultProfile: map[string]any{
+				"baseurl": e.Server.URL,
+				"homeurl": e.Server.URL,
+			},
+		},
+	})
+	return e
+}
+
+// Login stores the token of the fake API's default user in the settings.
+func (e *Env) Login() {
+	e.t.Helper()
+	values := e.ReadSettings()
+	profile := values["profiles"].(map[string]any)[settings.DefaultProfile].(map[string]any)
+	profile["token"] = apitest.DefaultToken
+	profile["username"] = apitest.DefaultUsername
+	e.WriteSettings(values)
+}
+
+// Run runs a0ctl with args.
+func (e *Env) Run(args ...string) Result {
+	e.t.Helper()
+	return e.RunInput("", args...)
+}
+
+// RunInput runs a0ctl with args, reading input from stdin.
+func (e *Env) RunInput(input string, args ...string) Result {
+	e.t.Helper()
+	var stdout, stderr bytes.Buffer
+	cmd := root.New(cmdutil.New(e.ConfigDir, &stderr))
+	cmd.SetArgs(args)
+	cmd.SetIn(strings.NewReader(input))
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	err := cmd.Execute()
+	return Result{Stdout: stdout.String(), Stderr: stderr.String(), Err: err}
+}
+
+// MustRun runs a0ctl with args and fails the test if the command fails.
+func (e *Env) MustRun(args ...string) Result {
+	e.t.Helper()
+	res := e.Run(args...)
+	if res.Err != nil {
+		e.t.Fatalf("a0ctl %v: %v\nstderr:\n%s", args, res.Err, res.Stderr)
+	}
+	return res
+}
+
+// SettingsPath returns the path of the settings file.
+func (e *Env) SettingsPath() string {
+	return filepath.Join(e.ConfigDir, "settings.json")
+}
+
+// ReadSettings returns the content of the settings file.
+func (e *Env) ReadSettings() map[string]any {
+	e.t.Helper()
+	data, err := os.ReadFile(e.SettingsPath())
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		e.t.Fatalf("invalid settings file: %v\n%s", err, data)
+	}
+	return values
+}
+
+// WriteSettings replaces the settings file.
+func (e *Env) WriteSettings(values map[string]any) {
+	e.t.Helper()
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	if err := os.WriteFile(e.SettingsPath(), data, 0o600); err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+// Setting returns the value at a dotted key of the settings file, like
+// profiles.default.token, or nil if unset.
+func (e *Env) Setting(key string) any {
+	e.t.Helper()
+	var v any = e.ReadSettings()
+	for _, part := range strings.Split(key, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[part]
+	}
+	return v
+}
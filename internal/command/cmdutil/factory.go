@@ -0,0 +1,146 @@
+// Package cmdutil provides the dependencies shared by commands.
+package cmdutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/a0dotrun/a0ctl/internal/output"
+	"github.com/a0dotrun/a0ctl/internal/settings"
+	"github.com/spf13/cobra"
+)
+
+// Factory gives commands their settings and API clients, so that they can
+// be run against a temporary config dir and a fake API in tests.
+type Factory struct {
+	// ConfigDir is the directory of the settings file.
+	ConfigDir string
+	// Stderr receives warnings about the settings file.
+	Stderr io.Writer
+	// Output is the format selected with --output, set by the root command
+	// before running its subcommands.
+	Output output.Format
+
+	// Settings returns the settings, read once per Factory.
+	Settings func() (*settings.Settings, error)
+	// Client returns a client authenticated with the token of the
+	// A0_API_TOKEN env var or of the active profile.
+	Client func() (*api.Client, error)
+	// UnauthedClient returns a client without a token.
+	UnauthedClient func() (*api.Client, error)
+}
+
+// New returns a Factory reading the settings from dir, the default
+// settings directory if empty. The global flags are only looked at when
+// the settings are first needed, once they are parsed.
+func New(dir string, stderr io.Writer) *Factory {
+	if dir == "" {
+		dir = settings.DefaultDir()
+	}
+	f := &Factory{ConfigDir: dir, Stderr: stderr, Output: output.Format{Kind: output.Table}}
+
+	var (
+		once   sync.Once
+		config *settings.Settings
+		err    error
+	)
+	f.Settings = func() (*settings.Settings, error) {
+		once.Do(func() {
+			config, err = settings.Load(settings.Options{
+				Dir:         f.ConfigDir,
+				Profile:     flags.Profile(),
+				ResetConfig: flags.ResetConfig(),
+				Stderr:      f.Stderr,
+			})
+		})
+		return config, err
+	}
+	f.Client = func() (*api.Client, error) {
+		config, err := f.settings()
+		if err != nil {
+			return nil, err
+		}
+		return withOrg(api.AuthedClient(config))
+	}
+	f.UnauthedClient = func() (*api.Client, error) {
+		config, err := f.settings()
+		if err != nil {
+			return nil, err
+		}
+		return withOrg(api.UnAuthedClient(config))
+	}
+	return f
+}
+
+// settings wraps the error of Settings for the clients.
+func (f *Factory) settings() (*settings.Settings, error) {
+	config, err := f.Settings()
+	if err != nil {
+		return nil, fmt.Errorf("error creating a0ctl client: could not read settings: %w", err)
+	}
+	return config, nil
+}
+
+// withOrg scopes the client to the organization given with --org.
+func withOrg(client *api.Client, err error) (*api.Client, error) {
+	if err != nil {
+		return nil, err
+	}
+	if org := flags.Org(); org != "" {
+		client.Org = org
+	}
+	return client, nil
+}
+
+type factoryKey struct{}
+
+// NewContext returns a copy of ctx carrying f.
+func NewContext(ctx context.Context, f *Factory) context.Context {
+	return context.WithValue(ctx, factoryKey{}, f)
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultFactory *Factory
+)
+
+// FromCommand returns the Factory of the context the command runs with,
+// set by the root command. Commands run on their own get one for the
+// default settings directory.
+func FromCommand(cmd *cobra.Command) *Factory {
+	if ctx := cmd.Context(); ctx != nil {
+		if f, ok := ctx.Value(factoryKey{}).(*Factory); ok {
+			return f
+		}
+	}
+	defaultOnce.Do(func() {
+		defaultFactory = New("", os.Stderr)
+	})
+	return defaultFactory
+}
+
+// Settings returns the settings of the command's Factory.
+func Settings(cmd *cobra.Command) (*settings.Settings, error) {
+	return FromCommand(cmd).Settings()
+}
+
+// Client returns an authenticated client from the command's Factory.
+func Client(cmd *cobra.Command) (*api.Client, error) {
+	return FromCommand(cmd).Client()
+}
+
+// Output returns the output format of the command's Factory.
+func Output(cmd *cobra.Command) output.Format {
+	return FromCommand(cmd).Output
+}
+
+// Messages returns where the command writes progress and informational
+// messages, see output.Format.Messages.
+func Messages(cmd *cobra.Command) io.Writer {
+	return Output(cmd).Messages(cmd.OutOrStdout(), cmd.ErrOrStderr())
+}
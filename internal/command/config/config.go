@@ -2,6 +2,7 @@
 package config
 
 import (
+	"github.com/a0dotrun/a0ctl/internal/command/config/profile"
 	"github.com/a0dotrun/a0ctl/internal/command/config/setconfig"
 	"github.com/spf13/cobra"
 )
@@ -18,6 +19,7 @@ func New() *cobra.Command {
 
 	cmd.AddCommand(
 		setconfig.NewConfig(),
+		profile.New(),
 	)
 
 	return cmd
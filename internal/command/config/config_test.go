@@ -0,0 +1,127 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api/apitest"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdtest"
+)
+
+func TestSetToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "valid", token: apitest.DefaultToken},
+		{name: "invalid", token: "bad", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := cmdtest.New(t)
+
+			res := e.Run("config", "set", "token", tt.token)
+			if (res.Err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", res.Err, tt.wantErr)
+			}
+
+			want := any(tt.token)
+			if tt.wantErr {
+				want = nil
+			}
+			if got := e.Setting("profiles.default.token"); got != want {
+				t.Errorf("token = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSetTokenWithoutTerminal(t *testing.T) {
+	e := cmdtest.New(t)
+
+	res := e.RunInput(apitest.DefaultToken+"\n", "config", "set", "token")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "pass the value as an argument") {
+		t.Errorf("err = %v", res.Err)
+	}
+}
+
+func TestSetTokenThenWhoAmI(t *testing.T) {
+	e := cmdtest.New(t)
+	e.MustRun("config", "set", "token", apitest.DefaultToken)
+
+	res := e.MustRun("auth", "whoami")
+	if res.Stdout != apitest.DefaultUsername+"\n" {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+}
+
+func TestSetOutput(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{format: "json"},
+		{format: "yaml"},
+		{format: "table"},
+		{format: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			e := cmdtest.New(t)
+			res := e.Run("config", "set", "output", tt.format)
+			if (res.Err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", res.Err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := e.Setting("output"); got != tt.format {
+				t.Errorf("output = %v, want %s", got, tt.format)
+			}
+		})
+	}
+}
+
+func TestSetUpdateNotice(t *testing.T) {
+	e := cmdtest.New(t)
+	e.MustRun("config", "set", "update-notice", "false")
+	if got := e.Setting("updatenotice"); got != false {
+		t.Errorf("updatenotice = %v, want false", got)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+
+	e.MustRun("config", "profile", "use", "staging")
+	if got := e.Setting("profile"); got != "staging" {
+		t.Fatalf("profile = %v, want staging", got)
+	}
+
+	// The staging profile has no credentials, nor the API URL of the fake.
+	res := e.Run("auth", "whoami", "--profile", "default")
+	if res.Err != nil {
+		t.Fatalf("whoami with --profile: %v", res.Err)
+	}
+
+	res = e.MustRun("config", "profile", "list")
+	for _, want := range []string{"default", "* ", "staging"} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("list doesn't contain %q:\n%s", want, res.Stdout)
+		}
+	}
+
+	if res := e.Run("config", "profile", "use", "Bad Name"); res.Err == nil {
+		t.Error("invalid profile name accepted")
+	}
+	// Names from the flag are checked too, or the token would be stored
+	// under a nested key.
+	if res := e.Run("config", "set", "token", apitest.DefaultToken, "--profile", "prod.eu"); res.Err == nil || !strings.Contains(res.Err.Error(), "invalid profile name") {
+		t.Errorf("err = %v with --profile prod.eu", res.Err)
+	}
+	if e.Setting("profiles.prod") != nil {
+		t.Error("token stored under profiles.prod")
+	}
+}
@@ -0,0 +1,74 @@
+package profile
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/spf13/cobra"
+)
+
+func newList() *cobra.Command {
+	const (
+		short = "List settings profiles"
+	)
+	cmd := &cobra.Command{
+		Use:               "list",
+		Aliases:           []string{"ls"},
+		Short:             short,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              list,
+	}
+	return cmd
+}
+
+// profileInfo describes a profile without its credentials.
+type profileInfo struct {
+	Name     string `json:"name"`
+	Active   bool   `json:"active"`
+	Username string `json:"username"`
+	Org      string `json:"org"`
+	BaseURL  string `json:"baseURL,omitempty"`
+}
+
+func list(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	config, err := cmdutil.Settings(cmd)
+	if err != nil {
+		return fmt.Errorf("could not retrieve local config: %w", err)
+	}
+
+	active := config.Profile()
+	names := config.Profiles()
+	profiles := make([]profileInfo, len(names))
+	for i, name := range names {
+		profiles[i] = profileInfo{
+			Name:     name,
+			Active:   name == active,
+			Username: config.ProfileValue(name, "username"),
+			Org:      config.ProfileValue(name, "org"),
+			BaseURL:  config.ProfileValue(name, "baseURL"),
+		}
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), profiles, func(w io.Writer) error {
+		t := cli.NewTable(w, "", "NAME", "USERNAME", "ORG", "API")
+		for _, p := range profiles {
+			marker := ""
+			if p.Active {
+				marker = "*"
+			}
+			t.Row(marker, p.Name, orDash(p.Username), orDash(p.Org), orDash(p.BaseURL))
+		}
+		return t.Flush()
+	})
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,30 @@
+// Package profile provides commands to manage settings profiles, e.g. to
+// switch between accounts or API environments.
+package profile
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func New() *cobra.Command {
+	const (
+		short = "Manage settings profiles"
+		long  = "Profiles keep separate credentials, organizations and API URLs, e.g. " +
+			"for different accounts. Select one for a single command with --profile " +
+			"or the A0_PROFILE env var."
+	)
+
+	cmd := &cobra.Command{
+		Use:     "profile",
+		Aliases: []string{"profiles"},
+		Short:   short,
+		Long:    long,
+	}
+
+	cmd.AddCommand(
+		newList(),
+		newUse(),
+	)
+
+	return cmd
+}
@@ -0,0 +1,48 @@
+package profile
+
+import (
+	"fmt"
+
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/completion"
+	"github.com/a0dotrun/a0ctl/internal/settings"
+	"github.com/spf13/cobra"
+)
+
+func newUse() *cobra.Command {
+	const (
+		use   = "use <name>"
+		short = "Select the default settings profile"
+		long  = "Select the profile used when --profile and A0_PROFILE aren't given. " +
+			"Selecting a new profile creates it, log in to add credentials to it."
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Long:              long,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completion.FirstArg(completion.Profiles),
+		RunE:              useProfile,
+	}
+	return cmd
+}
+
+func useProfile(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	name := args[0]
+	if err := settings.ValidateProfile(name); err != nil {
+		return err
+	}
+
+	config, err := cmdutil.Settings(cmd)
+	if err != nil {
+		return fmt.Errorf("could not retrieve local config: %w", err)
+	}
+
+	config.SetProfile(name)
+	if err := config.Persist(); err != nil {
+		return err
+	}
+	return cmdutil.Output(cmd).PrintMessage(cmd.OutOrStdout(), "Switched to profile %s.", cli.Emph(name))
+}
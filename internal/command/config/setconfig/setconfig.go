@@ -17,6 +17,8 @@ func NewConfig() *cobra.Command {
 
 	cmd.AddCommand(
 		newSetToken(),
+		newSetOutput(),
+		newSetUpdateNotice(),
 	)
 
 	return cmd
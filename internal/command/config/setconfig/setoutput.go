@@ -0,0 +1,48 @@
+package setconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func newSetOutput() *cobra.Command {
+	const (
+		use   = "output <format>"
+		short = "Configure the default output format"
+	)
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long:  fmt.Sprintf("Configure the output format used when --output isn't given, one of %s.", strings.Join(output.Formats, ", ")),
+		Args:  cobra.ExactArgs(1),
+		ValidArgsFunction: func(
+			cmd *cobra.Command, args []string, toComplete string,
+		) ([]string, cobra.ShellCompDirective) {
+			return []string{output.Table, output.JSON, output.YAML}, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: setOutput,
+	}
+	return cmd
+}
+
+func setOutput(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	config, err := cmdutil.Settings(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	if _, err := output.Parse(args[0]); err != nil {
+		return err
+	}
+
+	config.SetOutput(args[0])
+	if err := config.Persist(); err != nil {
+		return err
+	}
+	return cmdutil.Output(cmd).PrintMessage(cmd.OutOrStdout(), "Default output format set to %s.", args[0])
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/prompt"
 	"github.com/a0dotrun/a0ctl/internal/settings"
 
 	"github.com/a0dotrun/a0ctl/internal/api"
@@ -13,13 +15,14 @@ import (
 
 func newSetToken() *cobra.Command {
 	const (
-		use   = "token <jwt>"
+		use   = "token [jwt]"
 		short = "Configure the token used by a0ctl"
 	)
 	cmd := &cobra.Command{
 		Use:   use,
 		Short: short,
-		Args:  cobra.ExactArgs(1),
+		Long:  "Configure the token used by a0ctl. When no token is given it is prompted for, which keeps it out of your shell history.",
+		Args:  cobra.MaximumNArgs(1),
 		ValidArgsFunction: func(
 			cmd *cobra.Command, args []string, toComplete string,
 		) ([]string, cobra.ShellCompDirective) {
@@ -32,20 +35,24 @@ func newSetToken() *cobra.Command {
 
 func setToken(cmd *cobra.Command, args []string) error {
 	cmd.SilenceUsage = true
-	config, err := settings.ReadSettings()
+	config, err := cmdutil.Settings(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to read settings: %w", err)
 	}
 
-	token := args[0]
-	if !api.IsJWTTokenValid(token) {
+	var token string
+	if len(args) == 1 {
+		token = args[0]
+	} else if token, err = prompt.Password(cmd, "Token"); err != nil {
+		return err
+	}
+	if !api.IsJWTTokenValid(config, token) {
 		return errors.New("invalid token")
 	}
 
 	config.SetToken(token)
-	if err := settings.TryToPersistChanges(); err != nil {
+	if err := config.Persist(); err != nil {
 		return fmt.Errorf("%w\nIf the issue persists, set your token to the %s environment variable instead", err, cli.Emph(settings.EnvAccessToken))
 	}
-	fmt.Println("Token set succesfully.")
-	return nil
+	return cmdutil.Output(cmd).PrintMessage(cmd.OutOrStdout(), "Token set succesfully.")
 }
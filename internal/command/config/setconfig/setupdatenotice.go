@@ -0,0 +1,53 @@
+package setconfig
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/spf13/cobra"
+)
+
+func newSetUpdateNotice() *cobra.Command {
+	const (
+		use   = "update-notice <true|false>"
+		short = "Configure whether to tell about new versions of a0ctl"
+		long  = "Configure whether commands tell when a new version of a0ctl is " +
+			"available. The latest version is looked up at most once a day."
+	)
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long:  long,
+		Args:  cobra.ExactArgs(1),
+		ValidArgsFunction: func(
+			cmd *cobra.Command, args []string, toComplete string,
+		) ([]string, cobra.ShellCompDirective) {
+			return []string{"true", "false"}, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: setUpdateNotice,
+	}
+	return cmd
+}
+
+func setUpdateNotice(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	config, err := cmdutil.Settings(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	enabled, err := strconv.ParseBool(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid value %q, expected true or false", args[0])
+	}
+
+	config.SetUpdateNotice(enabled)
+	if err := config.Persist(); err != nil {
+		return err
+	}
+	if enabled {
+		return cmdutil.Output(cmd).PrintMessage(cmd.OutOrStdout(), "Update notices enabled.")
+	}
+	return cmdutil.Output(cmd).PrintMessage(cmd.OutOrStdout(), "Update notices disabled.")
+}
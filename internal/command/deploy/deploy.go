@@ -0,0 +1,327 @@
+// Package deploy provides the command to deploy an app from its source.
+package deploy
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/buildcontext"
+	"github.com/a0dotrun/a0ctl/internal/builder"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/detect"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/a0dotrun/a0ctl/internal/oci"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dockerfile  string
+	localBuild  bool
+	builderName string
+	platform    string
+
+	image                 string
+	registryUsername      string
+	registryPasswordStdin bool
+)
+
+// result is what a deploy prints: the deployment with the build context
+// uploaded, or the image built locally or given.
+type result struct {
+	api.Deployment
+	Context   *api.BuildContext   `json:"context,omitempty"`
+	Upload    *buildcontext.Stats `json:"upload,omitempty"`
+	Image     string              `json:"image,omitempty"`
+	Buildpack *api.BuildPlan      `json:"buildpack,omitempty"`
+}
+
+func New() *cobra.Command {
+	const (
+		use   = "deploy [dir]"
+		short = "Build and deploy an app from its source"
+		long  = "Build the Dockerfile of a directory, the current one by default, and " +
+			"release it as a new version of the app.\n\n" +
+			"Only the files that changed since the previous deploy are uploaded. Files " +
+			"matched by " + buildcontext.IgnoreFile + " are left out.\n\n" +
+			"With --local-build, the image is built with the local Docker or Podman " +
+			"daemon, or the BuildKit daemon at $" + builder.EnvBuildKitHost + ", and pushed " +
+			"to the a0 registry. Use it when the build needs private dependencies " +
+			"only reachable from your machine.\n\n" +
+			"Without a Dockerfile, the runtime of the app is detected from its files " +
+			"(package.json, go.mod, requirements.txt or index.html) and the app is " +
+			"built with buildpacks. Run `a0ctl init --dockerfile` to write the " +
+			"Dockerfile instead.\n\n" +
+			"With --image, nothing is built: the image is checked to exist for the " +
+			"platform and deployed by digest, so that the release never changes even " +
+			"if its tag is pushed again. Images in private registries are pulled with " +
+			"the credentials of --registry-username and --registry-password-stdin.\n\n" +
+			"--strategy chooses how the new instances replace the running ones. The " +
+			"rollout is followed until it ends, unless --detach is given. Canaries " +
+			"pause for `a0ctl deploy promote` or `a0ctl deploy abort`."
+		example = "  a0ctl deploy --app web\n" +
+			"  a0ctl deploy ./services/api --dockerfile docker/Dockerfile.prod\n" +
+			"  a0ctl deploy --local-build --builder podman\n" +
+			"  a0ctl deploy --strategy canary --canary-percent 20\n" +
+			"  a0ctl deploy --image ghcr.io/acme/web:v1.2.0\n" +
+			"  echo \"$TOKEN\" | a0ctl deploy --image ghcr.io/acme/api:v3 --registry-username bot --registry-password-stdin"
+	)
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   short,
+		Long:    long,
+		Example: example,
+		Args:    cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveFilterDirs
+		},
+		RunE: deploy,
+	}
+
+	flags.AddApp(cmd)
+	cmd.Flags().StringVar(&dockerfile, "dockerfile", buildcontext.DefaultDockerfile, "Path of the Dockerfile, relative to the directory")
+	cmd.Flags().BoolVar(&localBuild, "local-build", false, "Build the image locally and push it to the a0 registry")
+	cmd.Flags().StringVar(&builderName, "builder", "", "Engine to build with locally: "+strings.Join(builder.Engines, ", ")+". Detected if empty")
+	cmd.Flags().StringVar(&platform, "platform", builder.DefaultPlatform, "Platform the image is built for")
+	cmd.Flags().StringVar(&image, "image", "", "Deploy an image built beforehand, like registry/repo:tag or registry/repo@sha256:...")
+	cmd.Flags().StringVar(&registryUsername, "registry-username", "", "Username to pull the image from a private registry with")
+	cmd.Flags().BoolVar(&registryPasswordStdin, "registry-password-stdin", false, "Read the password of the private registry from stdin")
+	_ = cmd.RegisterFlagCompletionFunc("builder", cobra.FixedCompletions(builder.Engines, cobra.ShellCompDirectiveNoFileComp))
+	cmd.MarkFlagsMutuallyExclusive("image", "local-build")
+	cmd.MarkFlagsMutuallyExclusive("image", "dockerfile")
+	cmd.MarkFlagsRequiredTogether("registry-username", "registry-password-stdin")
+	addStrategyFlags(cmd)
+
+	cmd.AddCommand(newPromote(), newAbort())
+	return cmd
+}
+
+func deploy(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+	switch {
+	case image != "" && len(args) == 1:
+		return errors.New("a directory can't be deployed with --image")
+	case image == "" && registryUsername != "":
+		return errors.New("--registry-username only applies to --image")
+	}
+
+	// Without a Dockerfile, the app is built with buildpacks, as planned
+	// from its files.
+	var plan *api.BuildPlan
+	if image == "" {
+		if _, err := os.Stat(filepath.Join(dir, dockerfile)); errors.Is(err, os.ErrNotExist) {
+			if cmd.Flags().Changed("dockerfile") || localBuild {
+				return fmt.Errorf("no %s found in %s, generate one with `a0ctl init --dockerfile`", dockerfile, dir)
+			}
+			p, err := detect.Detect(dir)
+			if err != nil {
+				return fmt.Errorf("no %s found in %s: %w", dockerfile, dir, err)
+			}
+			plan = &p
+		}
+	}
+
+	strategy, err := deployStrategy(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	var res result
+	switch {
+	case image != "":
+		res, err = deployImage(cmd, client, app, strategy)
+	case localBuild:
+		res, err = deployLocalBuild(cmd, client, app, dir, strategy)
+	default:
+		res, err = deploySource(cmd, client, app, dir, plan, strategy)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !detach && !res.Done() {
+		if res.Deployment, err = watchRollout(cmd, cmdutil.Messages(cmd), client, app, res.Deployment); err != nil {
+			return err
+		}
+	}
+	if err := rolloutError(res.Deployment); err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), res, func(w io.Writer) error {
+		if res.Status == api.DeploymentStatusPaused {
+			_, err := fmt.Fprintf(w, "✔  Canary of release %s is live. Promote it with `a0ctl deploy promote %s --app %s`, or abort it with `a0ctl deploy abort %s --app %s`\n",
+				res.ReleaseID, res.ID, app, res.ID, app)
+			return err
+		}
+		_, err := fmt.Fprintf(w, "✔  Success! Deploying release %s\n", res.ReleaseID)
+		return err
+	})
+}
+
+// deploySource uploads the build context for the platform to build it,
+// with its Dockerfile or with buildpacks following plan if not nil.
+func deploySource(cmd *cobra.Command, client *api.Client, app, dir string, plan *api.BuildPlan, strategy *api.DeployStrategy) (result, error) {
+	messages := cmdutil.Messages(cmd)
+
+	if plan != nil {
+		fmt.Fprintf(messages, "No %s found, building with buildpacks:\n", dockerfile)
+		if err := detect.WritePlan(messages, *plan); err != nil {
+			return result{}, err
+		}
+	}
+
+	spinner := cli.NewSpinner(messages, "Scanning build context")
+	spinner.Start()
+	manifest, err := buildcontext.Scan(dir, dockerfile)
+	spinner.Stop("")
+	if err != nil {
+		return result{}, fmt.Errorf("could not scan %s: %w", dir, err)
+	}
+
+	bc, stats, err := buildcontext.Upload(cmd.Context(), client, dir, manifest, buildcontext.Options{Progress: cmd.ErrOrStderr()})
+	if err != nil {
+		return result{}, err
+	}
+	fmt.Fprintf(messages, "Uploaded %d of %d file(s), %s of %s (%s compressed)\n",
+		stats.Uploaded, stats.Files, cli.FormatBytes(stats.UploadedSize), cli.FormatBytes(stats.Size), cli.FormatBytes(stats.CompressedSize))
+
+	req := api.DeployRequest{ContextID: bc.ID, Buildpack: plan, Strategy: strategy}
+	if plan == nil {
+		req.Dockerfile = filepath.ToSlash(dockerfile)
+	}
+	deployment, err := client.Deploys.Create(app, req)
+	if err != nil {
+		return result{}, err
+	}
+	return result{Deployment: deployment, Context: &bc, Upload: &stats, Buildpack: plan}, nil
+}
+
+// deployLocalBuild builds the image locally and pushes it to the registry
+// before deploying it.
+func deployLocalBuild(cmd *cobra.Command, client *api.Client, app, dir string, strategy *api.DeployStrategy) (result, error) {
+	messages := cmdutil.Messages(cmd)
+
+	engine, err := builder.Detect(builderName)
+	if err != nil {
+		return result{}, err
+	}
+	creds, err := client.Registry.Credentials()
+	if err != nil {
+		return result{}, err
+	}
+
+	image := creds.Repository(app) + ":deploy-" + time.Now().UTC().Format("20060102150405")
+	fmt.Fprintf(messages, "Building %s with %s\n", image, engine.Name)
+	digest, err := engine.BuildAndPush(cmd.Context(), builder.BuildOptions{
+		Dir:         dir,
+		Dockerfile:  dockerfile,
+		Image:       image,
+		Platform:    platform,
+		Credentials: creds,
+		Output:      cmd.ErrOrStderr(),
+	})
+	if err != nil {
+		return result{}, err
+	}
+
+	// Pin the digest, the tag could be pushed again.
+	ref := creds.Repository(app) + "@" + digest
+	fmt.Fprintf(messages, "Pushed %s\n", ref)
+	deployment, err := client.Deploys.Create(app, api.DeployRequest{Image: ref, Strategy: strategy})
+	if err != nil {
+		return result{}, err
+	}
+	return result{Deployment: deployment, Image: ref}, nil
+}
+
+// deployImage deploys an image built beforehand, pinned to the digest it
+// has now.
+func deployImage(cmd *cobra.Command, client *api.Client, app string, strategy *api.DeployStrategy) (result, error) {
+	messages := cmdutil.Messages(cmd)
+
+	ref, err := oci.ParseReference(image)
+	if err != nil {
+		return result{}, err
+	}
+	p, err := oci.ParsePlatform(platform)
+	if err != nil {
+		return result{}, err
+	}
+
+	// Credentials of private registries are passed on for the platform to
+	// pull the image. It has its own for the a0 registry.
+	var auth oci.Auth
+	var registryAuth *api.RegistryAuth
+	if registryUsername != "" {
+		password, err := readPassword(cmd.InOrStdin())
+		if err != nil {
+			return result{}, err
+		}
+		auth = oci.Auth{Username: registryUsername, Password: password}
+		registryAuth = &api.RegistryAuth{Username: registryUsername, Password: password}
+	}
+
+	spinner := cli.NewSpinner(messages, "Checking "+ref.String())
+	spinner.Start()
+	img, err := oci.Resolve(cmd.Context(), ref, p, auth)
+	// Images of the a0 registry are checked with the user's credentials,
+	// only fetched once the registry asks for some: public images don't
+	// need a registry token.
+	if errors.Is(err, oci.ErrUnauthorized) && registryUsername == "" {
+		if creds, credsErr := client.Registry.Credentials(); credsErr == nil && creds.Registry == ref.Registry {
+			img, err = oci.Resolve(cmd.Context(), ref, p, oci.Auth{Username: creds.Username, Password: creds.Password})
+		}
+	}
+	spinner.Stop("")
+	if errors.Is(err, oci.ErrUnauthorized) && registryUsername == "" {
+		return result{}, fmt.Errorf("%w, pass the credentials of the registry with --registry-username and --registry-password-stdin", err)
+	}
+	if err != nil {
+		return result{}, err
+	}
+	if ref.Digest == "" {
+		fmt.Fprintf(messages, "Pinned %s to %s\n", ref, img.Digest)
+	}
+
+	deployment, err := client.Deploys.Create(app, api.DeployRequest{Image: img.Pinned(), RegistryAuth: registryAuth, Strategy: strategy})
+	if err != nil {
+		return result{}, err
+	}
+	return result{Deployment: deployment, Image: img.Pinned()}, nil
+}
+
+// readPassword reads a password from the first line of r.
+func readPassword(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("could not read the registry password: %w", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("no registry password on stdin")
+	}
+	return password, nil
+}
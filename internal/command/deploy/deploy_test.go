@@ -0,0 +1,389 @@
+package deploy_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/api/apitest"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdtest"
+	"github.com/a0dotrun/a0ctl/internal/oci/ocitest"
+)
+
+func newEnv(t *testing.T) (*cmdtest.Env, string) {
+	t.Helper()
+	e := cmdtest.New(t)
+	e.Login()
+	e.Server.AddApp(api.App{Name: "web"})
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"Dockerfile": "FROM node:18-alpine\nCOPY server.js .\n",
+		"server.js":  "console.log('hello')\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return e, dir
+}
+
+func TestDeploy(t *testing.T) {
+	e, dir := newEnv(t)
+
+	res := e.MustRun("deploy", dir, "--app", "web")
+	if !strings.Contains(res.Stdout, "Uploaded 2 of 2 file(s)") || !strings.Contains(res.Stdout, "Deploying release rel_web_1") {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+	reqs := e.Server.DeployRequests("web")
+	if len(reqs) != 1 || reqs[0].Dockerfile != "Dockerfile" {
+		t.Fatalf("deploy requests = %+v", reqs)
+	}
+	if m, ok := e.Server.Context(reqs[0].ContextID); !ok || len(m.Files) != 2 {
+		t.Errorf("context = %+v", m)
+	}
+
+	// Only the changed file is uploaded again.
+	if err := os.WriteFile(filepath.Join(dir, "server.js"), []byte("console.log('bye')\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res = e.MustRun("deploy", dir, "--app", "web", "-o", "json")
+	var out struct {
+		ReleaseID string `json:"releaseId"`
+		Upload    struct {
+			Files    int `json:"files"`
+			Uploaded int `json:"uploaded"`
+		} `json:"upload"`
+	}
+	if err := json.Unmarshal([]byte(res.Stdout), &out); err != nil {
+		t.Fatalf("%v\n%s", err, res.Stdout)
+	}
+	if out.ReleaseID != "rel_web_2" || out.Upload.Files != 2 || out.Upload.Uploaded != 1 {
+		t.Errorf("output = %+v", out)
+	}
+}
+
+func TestDeployDockerfile(t *testing.T) {
+	e, dir := newEnv(t)
+	if err := os.MkdirAll(filepath.Join(dir, "docker"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, "Dockerfile"), filepath.Join(dir, "docker", "Dockerfile.prod")); err != nil {
+		t.Fatal(err)
+	}
+
+	res := e.Run("deploy", dir, "--app", "web")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "no Dockerfile found") {
+		t.Errorf("err = %v", res.Err)
+	}
+
+	e.MustRun("deploy", dir, "--app", "web", "--dockerfile", filepath.Join("docker", "Dockerfile.prod"))
+	if reqs := e.Server.DeployRequests("web"); len(reqs) != 1 || reqs[0].Dockerfile != "docker/Dockerfile.prod" {
+		t.Errorf("deploy requests = %+v", reqs)
+	}
+}
+
+const testDigest = "sha256:3f8a2b1c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8"
+
+func TestDeployLocalBuild(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake docker is a shell script")
+	}
+	e, dir := newEnv(t)
+	bin := t.TempDir()
+	docker := "#!/bin/sh\nif [ \"$3\" = push ]; then echo \"v1: digest: " + testDigest + " size: 528\"; fi\n"
+	if err := os.WriteFile(filepath.Join(bin, "docker"), []byte(docker), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	res := e.MustRun("deploy", dir, "--app", "web", "--local-build")
+	ref := apitest.Registry + "/jane/web@" + testDigest
+	if !strings.Contains(res.Stdout, "Pushed "+ref) || !strings.Contains(res.Stdout, "Deploying release rel_web_1") {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+	if reqs := e.Server.DeployRequests("web"); len(reqs) != 1 || reqs[0].Image != ref || reqs[0].ContextID != "" {
+		t.Errorf("deploy requests = %+v", reqs)
+	}
+
+	res = e.Run("deploy", dir, "--app", "web", "--local-build", "--builder", "podman")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "podman not found") {
+		t.Errorf("err = %v", res.Err)
+	}
+}
+
+func TestDeployImage(t *testing.T) {
+	e, _ := newEnv(t)
+	registry := ocitest.New(t)
+	digest := registry.Push("acme/web", "v1", "linux/amd64", "linux/arm64")
+	pinned := registry.Host() + "/acme/web@" + digest
+
+	res := e.MustRun("deploy", "--app", "web", "--image", registry.Host()+"/acme/web:v1")
+	if !strings.Contains(res.Stdout, "Pinned "+registry.Host()+"/acme/web:v1 to "+digest) || !strings.Contains(res.Stdout, "Deploying release rel_web_1") {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+	reqs := e.Server.DeployRequests("web")
+	if len(reqs) != 1 || reqs[0].Image != pinned || reqs[0].RegistryAuth != nil {
+		t.Fatalf("deploy requests = %+v", reqs)
+	}
+	// Public images don't need a token of the a0 registry.
+	if n := tokenRequests(e); n != 0 {
+		t.Errorf("%d registry token request(s)", n)
+	}
+
+	// Pinned references are deployed as is.
+	res = e.MustRun("deploy", "--app", "web", "--image", pinned, "--platform", "linux/arm64")
+	if strings.Contains(res.Stdout, "Pinned") {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+	if reqs := e.Server.DeployRequests("web"); len(reqs) != 2 || reqs[0].Image != pinned {
+		t.Errorf("deploy requests = %+v", reqs)
+	}
+}
+
+func TestDeployImageInvalid(t *testing.T) {
+	e, dir := newEnv(t)
+	registry := ocitest.New(t)
+	registry.Push("acme/web", "arm", "linux/arm64")
+
+	for _, tt := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--image", registry.Host() + "/acme/web:arm"}, "has no image for linux/amd64, only for linux/arm64"},
+		{[]string{"--image", registry.Host() + "/acme/web:v2"}, "image not found"},
+		{[]string{"--image", "ghcr.io/acme/web@sha256:abc"}, "invalid image reference"},
+		{[]string{"--image", "nginx", dir}, "a directory can't be deployed with --image"},
+		{[]string{"--image", "nginx", "--local-build"}, "none of the others can be"},
+		{[]string{"--registry-username", "bot", "--registry-password-stdin"}, "only applies to --image"},
+	} {
+		res := e.Run(append([]string{"deploy", "--app", "web"}, tt.args...)...)
+		if res.Err == nil || !strings.Contains(res.Err.Error(), tt.err) {
+			t.Errorf("deploy %v: err = %v, want %q", tt.args, res.Err, tt.err)
+		}
+	}
+	if reqs := e.Server.DeployRequests("web"); len(reqs) != 0 {
+		t.Errorf("deploy requests = %+v", reqs)
+	}
+}
+
+func TestDeployImagePrivate(t *testing.T) {
+	e, _ := newEnv(t)
+	registry := ocitest.New(t)
+	registry.RequireAuth("bot", "s3cret")
+	digest := registry.Push("acme/api", "v3", "linux/amd64")
+	ref := registry.Host() + "/acme/api:v3"
+
+	res := e.Run("deploy", "--app", "web", "--image", ref)
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "--registry-password-stdin") {
+		t.Errorf("err = %v", res.Err)
+	}
+
+	res = e.RunInput("s3cret\n", "deploy", "--app", "web", "--image", ref, "--registry-username", "bot", "--registry-password-stdin")
+	if res.Err != nil {
+		t.Fatalf("%v\n%s", res.Err, res.Stdout)
+	}
+	reqs := e.Server.DeployRequests("web")
+	want := api.RegistryAuth{Username: "bot", Password: "s3cret"}
+	if len(reqs) != 1 || reqs[0].Image != registry.Host()+"/acme/api@"+digest || reqs[0].RegistryAuth == nil || *reqs[0].RegistryAuth != want {
+		t.Errorf("deploy requests = %+v", reqs)
+	}
+}
+
+func TestDeployImageA0Registry(t *testing.T) {
+	e, _ := newEnv(t)
+	registry := ocitest.New(t)
+	registry.RequireAuth("alice", "registry-alice")
+	digest := registry.Push("alice/api", "v1", "linux/amd64")
+	e.Server.HandleJSON("POST /v1/registry/tokens", http.StatusCreated, api.RegistryCredentials{
+		Registry: registry.Host(), Namespace: "alice", Username: "alice", Password: "registry-alice",
+	})
+
+	e.MustRun("deploy", "--app", "web", "--image", registry.Host()+"/alice/api:v1")
+	reqs := e.Server.DeployRequests("web")
+	// The platform pulls from the a0 registry with its own credentials.
+	if len(reqs) != 1 || reqs[0].Image != registry.Host()+"/alice/api@"+digest || reqs[0].RegistryAuth != nil {
+		t.Errorf("deploy requests = %+v", reqs)
+	}
+	if n := tokenRequests(e); n != 1 {
+		t.Errorf("%d registry token request(s), want 1", n)
+	}
+}
+
+// tokenRequests returns the number of registry tokens requested.
+func tokenRequests(e *cmdtest.Env) int {
+	n := 0
+	for _, r := range e.Server.Requests() {
+		if r.Method == http.MethodPost && r.Path == "/v1/registry/tokens" {
+			n++
+		}
+	}
+	return n
+}
+
+func TestDeployBuildpack(t *testing.T) {
+	e, dir := newEnv(t)
+	if err := os.Remove(filepath.Join(dir, "Dockerfile")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"scripts": {"start": "node server.js"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res := e.MustRun("deploy", dir, "--app", "web")
+	if !strings.Contains(res.Stdout, "No Dockerfile found, building with buildpacks") || !strings.Contains(res.Stdout, "npm start") {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+	reqs := e.Server.DeployRequests("web")
+	if len(reqs) != 1 || reqs[0].Dockerfile != "" || reqs[0].Buildpack == nil || reqs[0].Buildpack.Runtime != "node" {
+		t.Fatalf("deploy requests = %+v", reqs)
+	}
+
+	res = e.Run("deploy", dir, "--app", "web", "--local-build")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "a0ctl init --dockerfile") {
+		t.Errorf("err = %v", res.Err)
+	}
+}
+
+func TestDeployRolling(t *testing.T) {
+	e, dir := newEnv(t)
+
+	res := e.MustRun("deploy", dir, "--app", "web", "--strategy", "rolling", "--max-surge", "2", "--bake-time", "30s")
+	for _, want := range []string{
+		"Deploying: 4 old instance(s), 0/0 new healthy, 0% of the traffic on the new ones",
+		"Deploying: 2 old instance(s), 2/2 new healthy, 50% of the traffic on the new ones",
+		"Succeeded: 0 old instance(s), 4/4 new healthy, 100% of the traffic on the new ones",
+		"Success! Deploying release rel_web_1",
+	} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("stdout = %q, want %q", res.Stdout, want)
+		}
+	}
+	reqs := e.Server.DeployRequests("web")
+	want := api.DeployStrategy{Type: "rolling", MaxSurge: "2", BakeTimeSeconds: 30, AutoRollback: true}
+	if len(reqs) != 1 || reqs[0].Strategy == nil || *reqs[0].Strategy != want {
+		t.Fatalf("deploy requests = %+v", reqs)
+	}
+
+	// Without --strategy, the platform's default is used.
+	e.MustRun("deploy", dir, "--app", "web")
+	if reqs := e.Server.DeployRequests("web"); reqs[0].Strategy != nil {
+		t.Errorf("strategy = %+v", reqs[0].Strategy)
+	}
+}
+
+func TestDeployBlueGreen(t *testing.T) {
+	e, dir := newEnv(t)
+
+	res := e.MustRun("deploy", dir, "--app", "web", "--strategy", "bluegreen")
+	if !strings.Contains(res.Stdout, "Deploying: 4 old instance(s), 4/4 new healthy, 0% of the traffic") ||
+		!strings.Contains(res.Stdout, "Succeeded: 0 old instance(s), 4/4 new healthy, 100% of the traffic") {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+}
+
+func TestDeployCanary(t *testing.T) {
+	e, dir := newEnv(t)
+
+	res := e.MustRun("deploy", dir, "--app", "web", "--strategy", "canary", "--canary-percent", "25")
+	if !strings.Contains(res.Stdout, "Paused: 4 old instance(s), 1/1 new healthy, 25% of the traffic") ||
+		!strings.Contains(res.Stdout, "a0ctl deploy promote dep_web_1 --app web") {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+	if reqs := e.Server.DeployRequests("web"); reqs[0].Strategy.CanaryPercent != 25 {
+		t.Errorf("strategy = %+v", reqs[0].Strategy)
+	}
+
+	res = e.MustRun("deploy", "promote", "--app", "web")
+	if !strings.Contains(res.Stdout, "Succeeded: 0 old instance(s), 4/4 new healthy, 100% of the traffic") ||
+		!strings.Contains(res.Stdout, "Promoted release rel_web_1") {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+
+	res = e.Run("deploy", "promote", "--app", "web")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "the latest deployment dep_web_1 is succeeded") {
+		t.Errorf("err = %v", res.Err)
+	}
+}
+
+func TestDeployAbort(t *testing.T) {
+	e, dir := newEnv(t)
+
+	e.MustRun("deploy", dir, "--app", "web", "--strategy", "canary")
+	res := e.Run("deploy", "abort", "dep_web_1", "--app", "web", "--no-input")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "--yes") {
+		t.Fatalf("err = %v, want a confirmation", res.Err)
+	}
+
+	res = e.MustRun("deploy", "abort", "dep_web_1", "--app", "web", "--yes")
+	// The previous release is only back once the rollback is over.
+	if !strings.Contains(res.Stdout, "Deploying: 4 old instance(s)") || !strings.Contains(res.Stdout, "Aborted deployment dep_web_1") {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+
+	res = e.Run("deploy", "abort", "--app", "web", "--yes")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "is aborted") {
+		t.Errorf("err = %v", res.Err)
+	}
+}
+
+func TestDeployAbortDetach(t *testing.T) {
+	e, dir := newEnv(t)
+
+	e.MustRun("deploy", dir, "--app", "web", "--strategy", "canary")
+	res := e.MustRun("deploy", "abort", "--app", "web", "--yes", "--detach")
+	if !strings.Contains(res.Stdout, "Aborting deployment dep_web_1, rolling back") {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+}
+
+func TestDeployRollback(t *testing.T) {
+	e, dir := newEnv(t)
+	e.Server.FailHealthChecks("web", true)
+
+	res := e.Run("deploy", dir, "--app", "web", "--strategy", "rolling")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "deployment dep_web_1 rolled back: health checks of the new instances failed") {
+		t.Errorf("err = %v", res.Err)
+	}
+
+	res = e.Run("deploy", dir, "--app", "web", "--strategy", "bluegreen", "--auto-rollback=false")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "deployment dep_web_2 failed") {
+		t.Errorf("err = %v", res.Err)
+	}
+
+	// Detached deploys don't wait for the outcome.
+	res = e.MustRun("deploy", dir, "--app", "web", "--strategy", "rolling", "--detach")
+	if strings.Contains(res.Stdout, "Deploying:") {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+}
+
+func TestDeployStrategyInvalid(t *testing.T) {
+	e, dir := newEnv(t)
+
+	for _, tt := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--canary-percent", "20"}, "--canary-percent needs --strategy"},
+		{[]string{"--strategy", "bigbang"}, `unknown strategy "bigbang"`},
+		{[]string{"--strategy", "rolling", "--max-surge", "0"}, "invalid --max-surge"},
+		{[]string{"--strategy", "rolling", "--max-surge", "150%"}, "percentages go up to 100%"},
+		{[]string{"--strategy", "canary", "--canary-percent", "100"}, "invalid --canary-percent 100"},
+		{[]string{"--strategy", "bluegreen", "--max-surge", "2"}, "--max-surge only applies to --strategy rolling"},
+		{[]string{"--strategy", "rolling", "--canary-percent", "5"}, "--canary-percent only applies to --strategy canary"},
+	} {
+		res := e.Run(append([]string{"deploy", dir, "--app", "web"}, tt.args...)...)
+		if res.Err == nil || !strings.Contains(res.Err.Error(), tt.err) {
+			t.Errorf("deploy %v: err = %v, want %q", tt.args, res.Err, tt.err)
+		}
+	}
+	if reqs := e.Server.DeployRequests("web"); len(reqs) != 0 {
+		t.Errorf("deploy requests = %+v", reqs)
+	}
+}
@@ -0,0 +1,6 @@
+package deploy
+
+func init() {
+	// Rollouts of the fake API advance every time they are polled.
+	rolloutPollInterval = 0
+}
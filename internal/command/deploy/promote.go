@@ -0,0 +1,156 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/a0dotrun/a0ctl/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+func newPromote() *cobra.Command {
+	const (
+		use   = "promote [id]"
+		short = "Send all the traffic to a canary"
+		long  = "Promote a canary deployment, paused with part of the traffic, to replace " +
+			"all the running instances. Promotes the latest deployment by default."
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Long:              long,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              promote,
+	}
+	addDetachFlag(cmd)
+	return cmd
+}
+
+func newAbort() *cobra.Command {
+	const (
+		use   = "abort [id]"
+		short = "Stop a deployment and roll back"
+		long  = "Stop a deployment in progress or a paused canary, and send all the " +
+			"traffic back to the previous release. Aborts the latest deployment by default."
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Long:              long,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              abort,
+	}
+	addDetachFlag(cmd)
+	return cmd
+}
+
+func promote(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	id, err := deploymentID(client, app, args, func(d api.Deployment) bool { return d.Status == api.DeploymentStatusPaused })
+	if err != nil {
+		return err
+	}
+	d, err := client.Deploys.Promote(app, id)
+	if err != nil {
+		return err
+	}
+
+	if !detach && !d.Done() {
+		if d, err = watchRollout(cmd, cmdutil.Messages(cmd), client, app, d); err != nil {
+			return err
+		}
+	}
+	if err := rolloutError(d); err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), d, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "✔  Success! Promoted release %s\n", d.ReleaseID)
+		return err
+	})
+}
+
+func abort(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	id, err := deploymentID(client, app, args, func(d api.Deployment) bool { return !d.Done() })
+	if err != nil {
+		return err
+	}
+	if err := prompt.ConfirmAction(cmd, fmt.Sprintf("Abort deployment %s of %s and roll back?", id, app)); err != nil {
+		return err
+	}
+	d, err := client.Deploys.Abort(app, id)
+	if err != nil {
+		return err
+	}
+
+	if !detach && !d.Done() {
+		if d, err = watchRollout(cmd, cmdutil.Messages(cmd), client, app, d); err != nil {
+			return err
+		}
+	}
+	switch {
+	case !d.Done():
+		return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), d, func(w io.Writer) error {
+			_, err := fmt.Fprintf(w, "Aborting deployment %s, rolling back to the previous release\n", d.ID)
+			return err
+		})
+	case d.Status != api.DeploymentStatusAborted && d.Status != api.DeploymentStatusRolledBack:
+		if err := rolloutError(d); err != nil {
+			return err
+		}
+		return fmt.Errorf("deployment %s %s before it could be aborted", d.ID, d.Status)
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), d, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "✔  Aborted deployment %s, the previous release is back\n", d.ID)
+		return err
+	})
+}
+
+// deploymentID returns the deployment given as argument, or the latest one
+// of the app if ok accepts it.
+func deploymentID(client *api.Client, app string, args []string, ok func(api.Deployment) bool) (string, error) {
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	deployments, err := client.Deploys.List(app)
+	if err != nil {
+		return "", err
+	}
+	if len(deployments) == 0 {
+		return "", errors.New("the app has no deployments")
+	}
+	latest := deployments[0]
+	if !ok(latest) {
+		return "", fmt.Errorf("the latest deployment %s is %s", latest.ID, latest.Status)
+	}
+	return latest.ID, nil
+}
@@ -0,0 +1,145 @@
+package deploy
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/spf13/cobra"
+)
+
+// rolloutPollInterval is how often the progress of a deployment is checked.
+var rolloutPollInterval = 2 * time.Second
+
+// strategies are the values of --strategy.
+var strategies = []string{api.StrategyRolling, api.StrategyCanary, api.StrategyBlueGreen}
+
+var (
+	strategyType  string
+	maxSurge      string
+	canaryPercent int
+	bakeTime      time.Duration
+	autoRollback  bool
+	detach        bool
+)
+
+// maxSurgePattern matches a number of instances or a percentage of them.
+var maxSurgePattern = regexp.MustCompile(`^[1-9][0-9]*%?$`)
+
+func addStrategyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&strategyType, "strategy", "", "How new instances replace the running ones: "+strings.Join(strategies, ", ")+". Rolling if empty")
+	cmd.Flags().StringVar(&maxSurge, "max-surge", "25%", "Instances started above the count at a time by rolling deploys, as a number or a percentage")
+	cmd.Flags().IntVar(&canaryPercent, "canary-percent", 10, "Percentage of the traffic sent to canaries until promoted")
+	cmd.Flags().DurationVar(&bakeTime, "bake-time", 0, "How long new instances must stay healthy before the traffic moves on to them, the platform's default if 0")
+	cmd.Flags().BoolVar(&autoRollback, "auto-rollback", true, "Roll back when health checks of new instances fail")
+	addDetachFlag(cmd)
+	_ = cmd.RegisterFlagCompletionFunc("strategy", cobra.FixedCompletions(strategies, cobra.ShellCompDirectiveNoFileComp))
+}
+
+func addDetachFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&detach, "detach", false, "Don't wait for the rollout to end")
+}
+
+// deployStrategy returns the strategy set by the flags, or nil for the
+// platform's default.
+func deployStrategy(cmd *cobra.Command) (*api.DeployStrategy, error) {
+	if strategyType == "" {
+		for _, name := range []string{"max-surge", "canary-percent", "bake-time", "auto-rollback"} {
+			if cmd.Flags().Changed(name) {
+				return nil, fmt.Errorf("--%s needs --strategy", name)
+			}
+		}
+		return nil, nil
+	}
+
+	s := &api.DeployStrategy{Type: strategyType, AutoRollback: autoRollback}
+	switch strategyType {
+	case api.StrategyRolling:
+		if !maxSurgePattern.MatchString(maxSurge) {
+			return nil, fmt.Errorf("invalid --max-surge %q, expected a number of instances or a percentage like 25%%", maxSurge)
+		}
+		if v, ok := strings.CutSuffix(maxSurge, "%"); ok {
+			if percent, _ := strconv.Atoi(v); percent > 100 {
+				return nil, fmt.Errorf("invalid --max-surge %q, percentages go up to 100%%", maxSurge)
+			}
+		}
+		s.MaxSurge = maxSurge
+	case api.StrategyCanary:
+		if canaryPercent < 1 || canaryPercent > 99 {
+			return nil, fmt.Errorf("invalid --canary-percent %d, expected 1 to 99", canaryPercent)
+		}
+		s.CanaryPercent = canaryPercent
+	case api.StrategyBlueGreen:
+	default:
+		return nil, fmt.Errorf("unknown strategy %q, expected one of %s", strategyType, strings.Join(strategies, ", "))
+	}
+
+	if cmd.Flags().Changed("max-surge") && strategyType != api.StrategyRolling {
+		return nil, fmt.Errorf("--max-surge only applies to --strategy %s", api.StrategyRolling)
+	}
+	if cmd.Flags().Changed("canary-percent") && strategyType != api.StrategyCanary {
+		return nil, fmt.Errorf("--canary-percent only applies to --strategy %s", api.StrategyCanary)
+	}
+	if bakeTime < 0 {
+		return nil, fmt.Errorf("invalid --bake-time %s", bakeTime)
+	}
+	s.BakeTimeSeconds = int(bakeTime.Round(time.Second).Seconds())
+	return s, nil
+}
+
+// watchRollout prints the progress of a deployment whenever it changes,
+// until it is over or a canary waits to be promoted. Interrupting it
+// doesn't stop the deployment.
+func watchRollout(cmd *cobra.Command, w io.Writer, client *api.Client, app string, d api.Deployment) (api.Deployment, error) {
+	last := ""
+	for {
+		if line := describeRollout(d); line != last {
+			fmt.Fprintln(w, line)
+			last = line
+		}
+		if d.Done() || d.Status == api.DeploymentStatusPaused {
+			return d, nil
+		}
+
+		select {
+		case <-cmd.Context().Done():
+			return d, cmd.Context().Err()
+		case <-time.After(rolloutPollInterval):
+		}
+		var err error
+		if d, err = client.Deploys.Get(app, d.ID); err != nil {
+			return d, err
+		}
+	}
+}
+
+// describeRollout returns a line telling where a deployment is at.
+func describeRollout(d api.Deployment) string {
+	status := strings.ReplaceAll(d.Status, "_", " ")
+	if len(status) > 0 {
+		status = strings.ToUpper(status[:1]) + status[1:]
+	}
+	r := d.Rollout
+	if r == nil {
+		return fmt.Sprintf("%s: deployment %s", status, d.ID)
+	}
+	return fmt.Sprintf("%s: %d old instance(s), %d/%d new healthy, %d%% of the traffic on the new ones",
+		status, r.OldInstances, r.HealthyNewInstances, r.NewInstances, r.TrafficPercent)
+}
+
+// rolloutError returns the error of a deployment that didn't succeed, if
+// it is over.
+func rolloutError(d api.Deployment) error {
+	switch d.Status {
+	case api.DeploymentStatusFailed, api.DeploymentStatusRolledBack, api.DeploymentStatusAborted:
+		if d.Message != "" {
+			return fmt.Errorf("deployment %s %s: %s", d.ID, strings.ReplaceAll(d.Status, "_", " "), d.Message)
+		}
+		return fmt.Errorf("deployment %s %s", d.ID, strings.ReplaceAll(d.Status, "_", " "))
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+package doctor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/buildinfo"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/settings"
+	"github.com/a0dotrun/a0ctl/internal/update"
+)
+
+const (
+	apiTimeout    = 10 * time.Second
+	dockerTimeout = 5 * time.Second
+
+	slowLatency = time.Second
+	// Tokens are rejected by the API when the clock is too far off.
+	maxClockSkew  = 5 * time.Minute
+	warnClockSkew = 30 * time.Second
+)
+
+const redacted = "[redacted]"
+
+func runChecks(ctx context.Context, f *cmdutil.Factory) []Check {
+	path := settings.FilePath(f.ConfigDir)
+	settingsCheck, settingsOK := checkSettings(path)
+	checks := []Check{settingsCheck}
+
+	var config *settings.Settings
+	if settingsOK {
+		var err error
+		if config, err = f.Settings(); err != nil {
+			checks[0] = fail(settingsCheck.Name, "could not read %s: %v", path, err)
+		}
+	}
+	if config == nil {
+		// Everything else depends on the settings, and reading them now
+		// would only repeat the same error.
+		for _, name := range []string{"API", "Proxy and TLS", "Clock", "Authentication", "CLI version"} {
+			checks = append(checks, warn(name, "skipped, fix the settings file first"))
+		}
+	} else {
+		conn := checkAPI(ctx, config.A0URL())
+		checks = append(checks, conn.api, conn.tls, conn.clock, checkAuth(config, conn.ok), checkVersion(ctx, config.A0HomeURL()))
+	}
+	return append(checks, checkDocker(ctx))
+}
+
+// checkSettings checks that the settings file at path can be read and is
+// valid JSON, before loading it which prints its own warnings.
+func checkSettings(path string) (Check, bool) {
+	const name = "Settings"
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return pass(name, "%s doesn't exist yet, defaults are used", path), true
+	case err != nil:
+		return fail(name, "could not read %s: %v", path, err), false
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fail(name, "%s is not valid JSON: %v", path, err), false
+	}
+	return pass(name, "%s is valid", path), true
+}
+
+// connectivity is the result of the checks made with a request to the API.
+type connectivity struct {
+	api, tls, clock Check
+	ok              bool
+}
+
+func checkAPI(ctx context.Context, base string) connectivity {
+	const (
+		apiName   = "API"
+		tlsName   = "Proxy and TLS"
+		clockName = "Clock"
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base, nil)
+	if err != nil {
+		return connectivity{
+			api:   fail(apiName, "invalid API URL %s: %v", base, err),
+			tls:   warn(tlsName, "skipped, the API URL is invalid"),
+			clock: warn(clockName, "skipped, the API URL is invalid"),
+		}
+	}
+
+	route := "direct connection"
+	proxy, err := http.ProxyFromEnvironment(req)
+	switch {
+	case err != nil:
+		route = fmt.Sprintf("invalid proxy configuration: %v", err)
+	case proxy != nil:
+		route = "via proxy " + proxy.Redacted()
+	}
+
+	client := &http.Client{Timeout: apiTimeout}
+	start := time.Now()
+	res, err := client.Do(req)
+	latency := time.Since(start)
+	if err != nil {
+		c := connectivity{
+			api:   fail(apiName, "%s is unreachable: %v", base, err),
+			tls:   warn(tlsName, "%s", route),
+			clock: warn(clockName, "skipped, the API is unreachable"),
+		}
+		if isCertificateError(err) {
+			c.tls = fail(tlsName, "%s, the certificate of %s is not trusted, check SSL_CERT_FILE and SSL_CERT_DIR", route, req.URL.Host)
+		}
+		return c
+	}
+	_ = res.Body.Close()
+
+	c := connectivity{ok: true}
+	switch {
+	case res.StatusCode >= http.StatusInternalServerError:
+		c.api = warn(apiName, "%s responded with %s in %s", base, res.Status, latency.Round(time.Millisecond))
+	case latency > slowLatency:
+		c.api = warn(apiName, "%s is slow to respond (%s)", base, latency.Round(time.Millisecond))
+	default:
+		c.api = pass(apiName, "%s reachable in %s", base, latency.Round(time.Millisecond))
+	}
+
+	if res.TLS == nil {
+		c.tls = warn(tlsName, "%s, the API URL doesn't use TLS", route)
+	} else {
+		c.tls = pass(tlsName, "%s, %s%s", route, tls.VersionName(res.TLS.Version), certificateExpiry(res.TLS))
+	}
+
+	c.clock = checkClock(res.Header.Get("Date"), start, latency)
+	return c
+}
+
+func isCertificateError(err error) bool {
+	var verifyErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	return errors.As(err, &verifyErr) || errors.As(err, &authorityErr) || errors.As(err, &hostnameErr)
+}
+
+func certificateExpiry(state *tls.ConnectionState) string {
+	if len(state.PeerCertificates) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", certificate valid until %s", state.PeerCertificates[0].NotAfter.Format(time.DateOnly))
+}
+
+// checkClock compares the local clock with the Date header of a response.
+// The server time is assumed to be halfway through the request.
+func checkClock(date string, start time.Time, latency time.Duration) Check {
+	const name = "Clock"
+	if date == "" {
+		return warn(name, "the API didn't report its time")
+	}
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return warn(name, "the API reported an invalid time %q", date)
+	}
+
+	skew := start.Add(latency / 2).Sub(serverTime)
+	if skew < 0 {
+		skew = -skew
+	}
+	// The Date header only has a precision of one second.
+	skew = skew.Truncate(time.Second)
+	switch {
+	case skew > maxClockSkew:
+		return fail(name, "local clock is off by %s, tokens will be rejected, sync your clock", skew)
+	case skew > warnClockSkew:
+		return warn(name, "local clock is off by %s", skew)
+	}
+	return pass(name, "in sync with the API")
+}
+
+func checkAuth(config *settings.Settings, apiOK bool) Check {
+	const name = "Authentication"
+	token, source := os.Getenv(settings.EnvAccessToken), settings.EnvAccessToken+" env var"
+	if token == "" {
+		token, source = config.GetToken(), fmt.Sprintf("%s profile", config.Profile())
+	}
+	if token == "" {
+		return fail(name, "not logged in, run a0ctl auth login")
+	}
+	if !apiOK {
+		return warn(name, "token from the %s not validated, the API is unreachable", source)
+	}
+
+	client, err := api.MakeClient(config, token)
+	if err != nil {
+		return fail(name, "%v", err)
+	}
+	valid, err := client.Tokens.Validate()
+	if err != nil || !valid {
+		return fail(name, "token from the %s is invalid or expired, run a0ctl auth login", source)
+	}
+	if client.Username != "" {
+		return pass(name, "valid token from the %s, logged in as %s", source, client.Username)
+	}
+	return pass(name, "valid token from the %s", source)
+}
+
+func checkVersion(ctx context.Context, homeURL string) Check {
+	const name = "CLI version"
+	if buildinfo.IsDev() {
+		return warn(name, "development build, can't compare with releases")
+	}
+
+	release, err := update.Latest(ctx, homeURL)
+	if err != nil {
+		return warn(name, "%s, %v", buildinfo.Version, err)
+	}
+	if release.IsNewer() {
+		return warn(name, "%s, version %s is available, run a0ctl update", buildinfo.Version, release.Version)
+	}
+	return pass(name, "%s is the latest version", buildinfo.Version)
+}
+
+// checkDocker looks for a container engine usable for local builds.
+func checkDocker(ctx context.Context) Check {
+	const name = "Docker"
+	for _, engine := range []string{"docker", "podman"} {
+		path, err := exec.LookPath(engine)
+		if err != nil {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, dockerTimeout)
+		out, err := exec.CommandContext(ctx, path, "version", "--format", "{{.Server.Version}}").Output()
+		cancel()
+		if err != nil {
+			return warn(name, "%s found but its daemon is not reachable, needed for local builds", engine)
+		}
+		return pass(name, "%s %s", engine, strings.TrimSpace(string(out)))
+	}
+	return warn(name, "neither docker nor podman found, only needed for local builds")
+}
+
+// redactedSettings returns the settings file at path with credentials
+// removed.
+func redactedSettings(path string) any {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return map[string]string{"error": err.Error()}
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return map[string]string{"error": err.Error()}
+	}
+
+	redactValues(values)
+	return values
+}
+
+// redactValues replaces the values of secret keys, including in nested
+// objects like profiles.
+func redactValues(values map[string]any) {
+	for k, v := range values {
+		switch {
+		case isSecretKey(k) && v != "":
+			values[k] = redacted
+		default:
+			if m, ok := v.(map[string]any); ok {
+				redactValues(m)
+			}
+		}
+	}
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range []string{"token", "secret", "password", "key"} {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// bundleEnv lists the environment variables affecting a0ctl.
+var bundleEnv = []string{
+	settings.EnvAccessToken, settings.EnvConfigPath, settings.EnvProfile, settings.EnvBaseURL, settings.EnvHomeURL,
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+	"SSL_CERT_FILE", "SSL_CERT_DIR", "CI",
+}
+
+// redactedEnv returns the set environment variables of bundleEnv, with
+// tokens and proxy passwords removed.
+func redactedEnv() map[string]string {
+	env := make(map[string]string)
+	for _, name := range bundleEnv {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		switch {
+		case name == settings.EnvAccessToken:
+			value = redacted
+		case strings.EqualFold(name, "HTTP_PROXY"), strings.EqualFold(name, "HTTPS_PROXY"):
+			value = redactProxy(value)
+		}
+		env[name] = value
+	}
+	return env
+}
+
+// redactProxy returns the proxy URL value with its password removed.
+// Proxies without a scheme, like user:pass@host:3128, are HTTP proxies.
+func redactProxy(value string) string {
+	if !strings.Contains(value, "://") {
+		u, err := url.Parse("http://" + value)
+		if err != nil {
+			return redacted
+		}
+		return strings.TrimPrefix(u.Redacted(), "http://")
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return redacted
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,158 @@
+// Package doctor provides the command diagnosing problems with the local
+// setup of a0ctl.
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/buildinfo"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/settings"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// Check statuses.
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+var bundlePath string
+
+// New initializes and returns a new doctor Command.
+func New() *cobra.Command {
+	const (
+		short = "Diagnose problems with your a0ctl setup"
+		long  = "Check the settings file, authentication, connectivity to the a0 API, " +
+			"proxy and TLS configuration, clock skew, the CLI version and local " +
+			"Docker. Use --bundle to save a report with secrets redacted to attach " +
+			"to support tickets."
+	)
+	cmd := &cobra.Command{
+		Use:               "doctor",
+		Short:             short,
+		Long:              long,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              run,
+	}
+	cmd.Flags().StringVar(&bundlePath, "bundle", "", "Write a redacted diagnostics bundle to this file")
+	return cmd
+}
+
+// Check is the result of one diagnostic.
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+func pass(name, format string, a ...any) Check {
+	return Check{Name: name, Status: StatusPass, Detail: fmt.Sprintf(format, a...)}
+}
+
+func warn(name, format string, a ...any) Check {
+	return Check{Name: name, Status: StatusWarn, Detail: fmt.Sprintf(format, a...)}
+}
+
+func fail(name, format string, a ...any) Check {
+	return Check{Name: name, Status: StatusFail, Detail: fmt.Sprintf(format, a...)}
+}
+
+func run(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	ctx, cancel := context.WithTimeout(cmd.Context(), time.Minute)
+	defer cancel()
+
+	spinner := cli.NewSpinner(cmd.ErrOrStderr(), "Running diagnostics")
+	spinner.Start()
+	f := cmdutil.FromCommand(cmd)
+	checks := runChecks(ctx, f)
+	spinner.Stop("")
+
+	if bundlePath != "" {
+		if err := writeBundle(bundlePath, settings.FilePath(f.ConfigDir), checks); err != nil {
+			return err
+		}
+	}
+
+	err := cmdutil.Output(cmd).Print(cmd.OutOrStdout(), checks, func(w io.Writer) error {
+		t := cli.NewTable(w)
+		for _, c := range checks {
+			t.Row(statusSymbol(c.Status), c.Name, c.Detail)
+		}
+		if err := t.Flush(); err != nil {
+			return err
+		}
+		if bundlePath != "" {
+			_, err := fmt.Fprintf(w, "\nDiagnostics bundle written to %s\n", cli.Emph(bundlePath))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, c := range checks {
+		if c.Status == StatusFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+func statusSymbol(status string) string {
+	switch status {
+	case StatusPass:
+		return color.GreenString("✔")
+	case StatusWarn:
+		return cli.Warn("!")
+	}
+	return color.RedString("✖")
+}
+
+// bundle is the diagnostics bundle for support tickets. Tokens and
+// credentials are redacted.
+type bundle struct {
+	GeneratedAt time.Time         `json:"generatedAt"`
+	Version     string            `json:"version"`
+	OS          string            `json:"os"`
+	Arch        string            `json:"arch"`
+	Checks      []Check           `json:"checks"`
+	Settings    any               `json:"settings"`
+	Env         map[string]string `json:"env"`
+}
+
+func writeBundle(path, settingsPath string, checks []Check) error {
+	b := bundle{
+		GeneratedAt: time.Now().UTC(),
+		Version:     buildinfo.Version,
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		Checks:      checks,
+		Settings:    redactedSettings(settingsPath),
+		Env:         redactedEnv(),
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write diagnostics bundle: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package domains
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+func newAdd() *cobra.Command {
+	const (
+		use   = "add <hostname>"
+		short = "Attach a domain to an app"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              add,
+	}
+	return cmd
+}
+
+func add(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	domain, err := client.Domains.Add(app, args[0])
+	if err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), domain, func(w io.Writer) error {
+		fmt.Fprintf(w, "Domain %s added to %s.\n\n", cli.Emph(domain.Hostname), app)
+		if domain.Status == api.DomainStatusVerified {
+			return nil
+		}
+
+		fmt.Fprintln(w, "Create the following DNS records with your DNS provider:")
+		fmt.Fprintln(w)
+		if err := printDNSRecords(w, domain.DNSRecords); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(w, "\nThen run %s to check them.\n", cli.Emph("a0ctl domains verify "+domain.Hostname))
+		return err
+	})
+}
@@ -0,0 +1,48 @@
+// Package domains provides commands to manage custom domains of an app.
+package domains
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+func New() *cobra.Command {
+	const (
+		short = "Manage custom domains"
+		long  = "Attach custom domains to an app and check their DNS and TLS certificate status."
+	)
+
+	cmd := &cobra.Command{
+		Use:   "domains",
+		Short: short,
+		Long:  long,
+	}
+
+	flags.AddApp(cmd)
+
+	cmd.AddCommand(newList(), newAdd(), newVerify(), newRemove())
+
+	return cmd
+}
+
+func printDNSRecords(w io.Writer, records []api.DNSRecord) error {
+	table := cli.NewTable(w, "TYPE", "NAME", "VALUE")
+	for _, r := range records {
+		table.Row(r.Type, r.Name, r.Value)
+	}
+	return table.Flush()
+}
+
+func certificateExpiry(cert api.Certificate) string {
+	if cert.ExpiresAt == nil {
+		return "-"
+	}
+	expiresIn := time.Until(*cert.ExpiresAt).Round(time.Hour)
+	return fmt.Sprintf("%s (%d days)", cert.ExpiresAt.Local().Format(time.DateOnly), int(expiresIn.Hours()/24))
+}
@@ -0,0 +1,56 @@
+package domains_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/api/apitest"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdtest"
+)
+
+func TestList(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+	e.Server.HandleJSON("GET /v1/apps/web/domains", http.StatusOK, map[string][]api.Domain{
+		"domains": {{Hostname: "www.example.com", Status: "verified"}},
+	})
+
+	res := e.MustRun("domains", "list", "--app", "web")
+	if !strings.Contains(res.Stdout, "www.example.com") || !strings.Contains(res.Stdout, "verified") {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+}
+
+func TestRemoveFailure(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+	e.Server.Inject("DELETE /v1/apps/web/domains/{hostname}", apitest.Failure{
+		Status: http.StatusNotFound, Message: "domain not found",
+	})
+
+	res := e.Run("domains", "remove", "www.example.com", "--app", "web", "--yes")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "domain not found") {
+		t.Errorf("err = %v", res.Err)
+	}
+}
+
+func TestVerifyTimeout(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+	e.Server.HandleJSON("POST /v1/apps/web/domains/{hostname}/verify", http.StatusOK, api.Domain{
+		Hostname: "www.example.com", Status: api.DomainStatusPending,
+	})
+
+	// The last check happens at the deadline, not a poll interval later.
+	start := time.Now()
+	res := e.Run("domains", "verify", "www.example.com", "--app", "web", "--timeout", "100ms")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "is not verified yet") {
+		t.Errorf("err = %v", res.Err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("verify took %s with a 100ms timeout", elapsed)
+	}
+}
@@ -0,0 +1,52 @@
+package domains
+
+import (
+	"io"
+
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+func newList() *cobra.Command {
+	const (
+		use   = "list"
+		short = "List the domains attached to an app"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Aliases:           []string{"ls"},
+		Short:             short,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              list,
+	}
+	return cmd
+}
+
+func list(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	domains, err := client.Domains.List(app)
+	if err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), domains, func(w io.Writer) error {
+		table := cli.NewTable(w, "HOSTNAME", "STATUS", "CERTIFICATE", "EXPIRES")
+		for _, d := range domains {
+			table.Row(d.Hostname, d.Status, d.Certificate.Status, certificateExpiry(d.Certificate))
+		}
+		return table.Flush()
+	})
+}
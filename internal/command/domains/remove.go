@@ -0,0 +1,50 @@
+package domains
+
+import (
+	"fmt"
+
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/a0dotrun/a0ctl/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+func newRemove() *cobra.Command {
+	const (
+		use   = "remove <hostname>"
+		short = "Detach a domain from an app"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Aliases:           []string{"rm"},
+		Short:             short,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              remove,
+	}
+	return cmd
+}
+
+func remove(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	if err := prompt.ConfirmAction(cmd, fmt.Sprintf("Remove domain %s from %s?", args[0], app)); err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Domains.Remove(app, args[0]); err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).PrintMessage(cmd.OutOrStdout(), "Domain %s removed from %s.", args[0], app)
+}
@@ -0,0 +1,115 @@
+package domains
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+const verifyPollInterval = 10 * time.Second
+
+var verifyTimeout time.Duration
+
+func newVerify() *cobra.Command {
+	const (
+		use   = "verify <hostname>"
+		short = "Verify the DNS records of a domain"
+		long  = "Check the DNS records of a domain and wait until it is verified and " +
+			"its TLS certificate is issued."
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Long:              long,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              verify,
+	}
+	cmd.Flags().DurationVar(&verifyTimeout, "timeout", 5*time.Minute, "How long to wait for verification, 0 to check only once")
+	return cmd
+}
+
+func verify(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	hostname := args[0]
+	domain, err := client.Domains.Verify(app, hostname)
+	if err != nil {
+		return err
+	}
+
+	messages := cmdutil.Messages(cmd)
+	deadline := time.Now().Add(verifyTimeout)
+	if !isDone(domain) && time.Now().Before(deadline) {
+		spinner := cli.NewSpinner(messages, verifyStatus(domain))
+		spinner.Start()
+		for !isDone(domain) && time.Now().Before(deadline) {
+			select {
+			case <-cmd.Context().Done():
+				spinner.Stop("")
+				return cmd.Context().Err()
+			case <-time.After(min(verifyPollInterval, time.Until(deadline))):
+			}
+
+			domain, err = client.Domains.Verify(app, hostname)
+			if err != nil {
+				spinner.Stop("")
+				return err
+			}
+			spinner.Update(verifyStatus(domain))
+		}
+		spinner.Stop("")
+	}
+
+	switch {
+	case domain.Status == api.DomainStatusFailed:
+		fmt.Fprintln(messages, "Verification failed. Make sure these DNS records exist:")
+		if err := printDNSRecords(messages, domain.DNSRecords); err != nil {
+			return err
+		}
+		return fmt.Errorf("domain %s could not be verified", hostname)
+	case domain.Status != api.DomainStatusVerified:
+		fmt.Fprintln(messages, "DNS records not found yet. Make sure these DNS records exist:")
+		if err := printDNSRecords(messages, domain.DNSRecords); err != nil {
+			return err
+		}
+		return fmt.Errorf("domain %s is not verified yet, DNS changes can take a while to propagate", hostname)
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), domain, func(w io.Writer) error {
+		fmt.Fprintf(w, "✔  Success! Domain %s is verified\n", hostname)
+		_, err := fmt.Fprintf(w, "Certificate: %s, expires %s\n", domain.Certificate.Status, certificateExpiry(domain.Certificate))
+		return err
+	})
+}
+
+func verifyStatus(d api.Domain) string {
+	return fmt.Sprintf("Waiting for verification: domain %s, certificate %s", d.Status, d.Certificate.Status)
+}
+
+// isDone reports whether polling can stop: the domain failed or is
+// verified with a settled certificate.
+func isDone(d api.Domain) bool {
+	switch d.Status {
+	case api.DomainStatusFailed:
+		return true
+	case api.DomainStatusVerified:
+		return d.Certificate.Status != api.CertificateStatusPending
+	}
+	return false
+}
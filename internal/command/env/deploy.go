@@ -0,0 +1,44 @@
+package env
+
+import (
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+// NewDeploy returns a command releasing staged env var and secret changes.
+func NewDeploy() *cobra.Command {
+	const (
+		use   = "deploy"
+		short = "Redeploy the app with staged environment and secret changes"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              deploy,
+	}
+	return cmd
+}
+
+func deploy(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	result, err := client.Env.Deploy(app)
+	if err != nil {
+		return err
+	}
+
+	return PrintChangeResult(cmd, result)
+}
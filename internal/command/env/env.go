@@ -0,0 +1,59 @@
+// Package env provides commands to manage app environment variables.
+package env
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+func New() *cobra.Command {
+	const (
+		short = "Manage app environment variables"
+		long  = "Manage the plain-text environment variables of an app.\n\nUse " +
+			"`a0ctl secrets` for sensitive values."
+	)
+
+	cmd := &cobra.Command{
+		Use:   "env",
+		Short: short,
+		Long:  long,
+	}
+
+	flags.AddApp(cmd)
+
+	setCmd := newSet()
+	unsetCmd := newUnset()
+	flags.AddStage(setCmd)
+	flags.AddStage(unsetCmd)
+
+	cmd.AddCommand(newList(), setCmd, unsetCmd, NewDeploy())
+
+	return cmd
+}
+
+// PrintChangeResult reports whether a change was staged or released.
+func PrintChangeResult(cmd *cobra.Command, result api.EnvChangeResult) error {
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), result, func(w io.Writer) error {
+		return WriteChangeResult(w, result)
+	})
+}
+
+// WriteChangeResult writes the human-readable form of a change result.
+func WriteChangeResult(w io.Writer, result api.EnvChangeResult) error {
+	var err error
+	switch {
+	case result.Staged:
+		_, err = fmt.Fprintf(w, "Changes staged. Run %s to release them.\n", cli.Emph("a0ctl env deploy"))
+	case result.ReleaseID != "":
+		_, err = fmt.Fprintf(w, "✔  Success! Deploying release %s\n", result.ReleaseID)
+	default:
+		_, err = fmt.Fprintln(w, "✔  Success! No changes to deploy.")
+	}
+	return err
+}
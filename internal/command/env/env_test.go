@@ -0,0 +1,103 @@
+package env_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/api/apitest"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdtest"
+)
+
+func newEnv(t *testing.T) *cmdtest.Env {
+	t.Helper()
+	e := cmdtest.New(t)
+	e.Login()
+	e.Server.AddApp(api.App{Name: "web"})
+	return e
+}
+
+func TestSetAndList(t *testing.T) {
+	e := newEnv(t)
+
+	res := e.MustRun("env", "set", "--app", "web", "PORT=8080", "DEBUG=1")
+	if !strings.Contains(res.Stdout, "Deploying release rel_web_1") {
+		t.Errorf("set stdout = %q", res.Stdout)
+	}
+
+	res = e.MustRun("env", "list", "--app", "web", "-o", "json")
+	var vars []api.EnvVar
+	if err := json.Unmarshal([]byte(res.Stdout), &vars); err != nil {
+		t.Fatalf("%v\n%s", err, res.Stdout)
+	}
+	want := []api.EnvVar{{Name: "DEBUG", Value: "1"}, {Name: "PORT", Value: "8080"}}
+	if len(vars) != len(want) || vars[0] != want[0] || vars[1] != want[1] {
+		t.Errorf("vars = %+v, want %+v", vars, want)
+	}
+
+	res = e.MustRun("env", "list", "--app", "web", "--dotenv")
+	if res.Stdout != "DEBUG=1\nPORT=8080\n" {
+		t.Errorf("dotenv = %q", res.Stdout)
+	}
+}
+
+func TestSetFromStdin(t *testing.T) {
+	e := newEnv(t)
+
+	e.MustRun("env", "set", "--app", "web", "PORT=8080")
+	if res := e.RunInput("-----BEGIN KEY-----\nabc\n", "env", "set", "--app", "web", "TLS_KEY=-"); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if got := e.Server.Env("web")["TLS_KEY"]; !strings.HasPrefix(got, "-----BEGIN KEY-----\nabc") {
+		t.Errorf("TLS_KEY = %q", got)
+	}
+}
+
+func TestStageThenDeploy(t *testing.T) {
+	e := newEnv(t)
+
+	res := e.MustRun("env", "set", "--app", "web", "--stage", "A=1")
+	if !strings.Contains(res.Stdout, "Changes staged") {
+		t.Errorf("set stdout = %q", res.Stdout)
+	}
+	e.MustRun("env", "unset", "--app", "web", "--stage", "A")
+	if n := len(e.Server.Releases("web")); n != 0 {
+		t.Fatalf("%d releases before deploy", n)
+	}
+
+	e.MustRun("env", "deploy", "--app", "web")
+	if n := len(e.Server.Releases("web")); n != 1 {
+		t.Errorf("%d releases after deploy, want 1", n)
+	}
+	if _, ok := e.Server.Env("web")["A"]; ok {
+		t.Error("A is still set")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		inject  string
+		wantErr string
+	}{
+		{name: "no app", args: []string{"env", "list"}, wantErr: "no app specified"},
+		{name: "unknown app", args: []string{"env", "list", "--app", "api"}, wantErr: "app api not found"},
+		{name: "invalid name", args: []string{"env", "set", "--app", "web", "1A=x"}, wantErr: "1A"},
+		{name: "server error", args: []string{"env", "list", "--app", "web"}, inject: "GET /v1/apps/web/env", wantErr: "database unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			if tt.inject != "" {
+				e.Server.Inject(tt.inject, apitest.Failure{Status: http.StatusInternalServerError, Message: "database unavailable"})
+			}
+			res := e.Run(tt.args...)
+			if res.Err == nil || !strings.Contains(res.Err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", res.Err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package env
+
+import (
+	"io"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+var dotenv bool
+
+func newList() *cobra.Command {
+	const (
+		use   = "list"
+		short = "List the environment variables of an app"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Aliases:           []string{"ls"},
+		Short:             short,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              list,
+	}
+	cmd.Flags().BoolVar(&dotenv, "dotenv", false, "Print the variables in dotenv format")
+	return cmd
+}
+
+func list(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	vars, err := client.Env.List(app)
+	if err != nil {
+		return err
+	}
+
+	if dotenv {
+		m := make(map[string]string, len(vars))
+		for _, v := range vars {
+			m[v.Name] = v.Value
+		}
+		return api.WriteDotenv(cmd.OutOrStdout(), m)
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), vars, func(w io.Writer) error {
+		table := cli.NewTable(w, "NAME", "VALUE")
+		for _, v := range vars {
+			table.Row(v.Name, v.Value)
+		}
+		return table.Flush()
+	})
+}
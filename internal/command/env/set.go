@@ -0,0 +1,58 @@
+package env
+
+import (
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+func newSet() *cobra.Command {
+	const (
+		use   = "set KEY=VALUE..."
+		short = "Set environment variables on an app"
+		long  = "Set environment variables on an app and redeploy it.\n\n" +
+			"A value of - is read from stdin, and a value starting with @ is read " +
+			"from the named file, e.g. CONFIG=@config.json."
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Long:              long,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              set,
+	}
+	return cmd
+}
+
+func set(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	vars, err := cli.ParseKeyValues(args, cmd.InOrStdin())
+	if err != nil {
+		return err
+	}
+	for name := range vars {
+		if err := api.ValidateEnvName(name); err != nil {
+			return err
+		}
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	result, err := client.Env.Update(app, api.EnvChange{Set: vars, Stage: flags.Stage()})
+	if err != nil {
+		return err
+	}
+
+	return PrintChangeResult(cmd, result)
+}
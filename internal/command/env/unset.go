@@ -0,0 +1,44 @@
+package env
+
+import (
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+func newUnset() *cobra.Command {
+	const (
+		use   = "unset KEY..."
+		short = "Remove environment variables from an app"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              unset,
+	}
+	return cmd
+}
+
+func unset(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	result, err := client.Env.Update(app, api.EnvChange{Unset: args, Stage: flags.Stage()})
+	if err != nil {
+		return err
+	}
+
+	return PrintChangeResult(cmd, result)
+}
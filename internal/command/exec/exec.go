@@ -0,0 +1,125 @@
+// Package exec provides the command to run commands in app instances.
+package exec
+
+import (
+	"context"
+	"os"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+var tty bool
+
+func New() *cobra.Command {
+	const (
+		use   = "exec -- <command> [args...]"
+		short = "Run a command in an app instance"
+		long  = "Run a command in an app instance, streaming its input and output.\n\n" +
+			"A terminal is allocated when stdin and stdout are terminals, use " +
+			"--tty=false to disable it. The exit code of the command is passed on."
+		example = "  a0ctl exec --app web -- ls -la /app\n" +
+			"  a0ctl exec --app web --instance 9a8b7c -- node scripts/migrate.js"
+	)
+
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Long:              long,
+		Example:           example,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              execute,
+	}
+
+	flags.AddApp(cmd)
+	flags.AddInstance(cmd)
+	cmd.Flags().BoolVarP(&tty, "tty", "t", false, "Allocate a terminal for the command")
+
+	return cmd
+}
+
+func execute(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Flags().Changed("tty") {
+		tty = cli.Terminal(cmd.InOrStdin()) != nil && cli.Terminal(cmd.OutOrStdout()) != nil
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+	return Run(cmd, client, app, api.ExecRequest{
+		Instance: flags.Instance(),
+		Command:  args,
+		TTY:      tty,
+	})
+}
+
+// Run runs a command in an app instance, connected to the standard streams
+// of cmd. A non-zero exit code of the command is returned as a
+// cli.ExitError.
+func Run(cmd *cobra.Command, client *api.Client, app string, req api.ExecRequest) error {
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	stdout := cli.Terminal(cmd.OutOrStdout())
+	if req.TTY {
+		req.Size = terminalSize(stdout)
+		// The input is only put in raw mode when it is a terminal, e.g.
+		// not with --tty and piped input.
+		if stdin := cli.Terminal(cmd.InOrStdin()); stdin != nil {
+			restore, err := cli.MakeRaw(stdin)
+			if err != nil {
+				return err
+			}
+			defer restore()
+		}
+	}
+
+	session, err := client.Exec.Start(ctx, app, req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = session.Close()
+	}()
+
+	if req.TTY {
+		go func() {
+			for range cli.NotifyResize(ctx) {
+				if err := session.Resize(terminalSize(stdout)); err != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	code, err := session.Stream(cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return &cli.ExitError{Code: code}
+	}
+	return nil
+}
+
+// terminalSize returns the size of the terminal f, or a default size if f
+// is nil.
+func terminalSize(f *os.File) api.TermSize {
+	if f != nil {
+		if cols, rows, err := cli.TerminalSize(f); err == nil {
+			return api.TermSize{Cols: cols, Rows: rows}
+		}
+	}
+	return api.TermSize{Cols: 80, Rows: 24}
+}
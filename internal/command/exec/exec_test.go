@@ -0,0 +1,66 @@
+package exec_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdtest"
+)
+
+func newEnv(t *testing.T) *cmdtest.Env {
+	t.Helper()
+	e := cmdtest.New(t)
+	e.Login()
+	e.Server.AddApp(api.App{Name: "web"})
+	return e
+}
+
+func TestExec(t *testing.T) {
+	e := newEnv(t)
+
+	res := e.MustRun("exec", "--app", "web", "--", "echo", "hello", "world")
+	if res.Stdout != "hello world\n" {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+
+	// Without a terminal, no TTY is requested.
+	reqs := e.Server.Requests()
+	if q := reqs[len(reqs)-1].Query; strings.Contains(q, "tty") {
+		t.Errorf("query = %q, want no tty", q)
+	}
+
+	// --tty works with piped input, with the default terminal size.
+	e.MustRun("exec", "--app", "web", "--tty", "--", "echo")
+	reqs = e.Server.Requests()
+	if q := reqs[len(reqs)-1].Query; !strings.Contains(q, "tty=true") || !strings.Contains(q, "cols=80") {
+		t.Errorf("query = %q, want a tty", q)
+	}
+}
+
+func TestExecStdin(t *testing.T) {
+	e := newEnv(t)
+
+	res := e.RunInput("line 1\nline 2\n", "exec", "--app", "web", "--", "cat")
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if res.Stdout != "line 1\nline 2\n" {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+}
+
+func TestExecExitCode(t *testing.T) {
+	e := newEnv(t)
+
+	res := e.Run("exec", "--app", "web", "--", "missing")
+	var exit *cli.ExitError
+	if !errors.As(res.Err, &exit) || exit.Code != 127 {
+		t.Fatalf("err = %v, want exit code 127", res.Err)
+	}
+	if res.Stderr != "missing: command not found\n" {
+		t.Errorf("stderr = %q", res.Stderr)
+	}
+}
@@ -0,0 +1,123 @@
+// Package initcmd provides the command to prepare the source of an app to
+// be deployed. It is not called init, which Go reserves.
+package initcmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/buildcontext"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/detect"
+	"github.com/spf13/cobra"
+)
+
+var (
+	writeDockerfile bool
+	force           bool
+)
+
+// result is what init prints: the detected plan and the files written.
+type result struct {
+	Plan  api.BuildPlan `json:"plan"`
+	Files []string      `json:"files"`
+}
+
+func New() *cobra.Command {
+	const (
+		short = "Detect how to build an app without a Dockerfile"
+		long  = "Detect the runtime of the app in a directory, the current one by " +
+			"default, from its package.json, go.mod, requirements.txt or index.html, " +
+			"and show how it will be built and started. The command starting it can " +
+			"be set on a web: line of a Procfile.\n\n" +
+			"With --dockerfile, write a Dockerfile following the plan, and a " +
+			buildcontext.IgnoreFile + " if there is none, to build the app like any other."
+		example = "  a0ctl init\n" +
+			"  a0ctl init ./services/api --dockerfile"
+	)
+
+	cmd := &cobra.Command{
+		Use:     "init [dir]",
+		Short:   short,
+		Long:    long,
+		Example: example,
+		Args:    cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveFilterDirs
+		},
+		RunE: initApp,
+	}
+
+	cmd.Flags().BoolVar(&writeDockerfile, "dockerfile", false, "Write a Dockerfile building the app")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite the Dockerfile if it exists")
+
+	return cmd
+}
+
+func initApp(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	plan, err := detect.Detect(dir)
+	if err != nil {
+		return err
+	}
+
+	res := result{Plan: plan, Files: []string{}}
+	if writeDockerfile {
+		if res.Files, err = writeFiles(dir, plan); err != nil {
+			return err
+		}
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), res, func(w io.Writer) error {
+		fmt.Fprintf(w, "Detected a %s app:\n", plan.Runtime)
+		if err := detect.WritePlan(w, plan); err != nil {
+			return err
+		}
+		if !writeDockerfile {
+			_, err := fmt.Fprintf(w, "\n`a0ctl deploy` will build it with buildpacks. Run %s to write a Dockerfile instead.\n", cli.Emph("a0ctl init --dockerfile"))
+			return err
+		}
+		for _, name := range res.Files {
+			if _, err := fmt.Fprintf(w, "✔  Wrote %s\n", filepath.Join(dir, name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeFiles writes the Dockerfile of plan in dir, and an ignore file if
+// there is none, returning the names of the files written.
+func writeFiles(dir string, plan api.BuildPlan) ([]string, error) {
+	dockerfile := filepath.Join(dir, buildcontext.DefaultDockerfile)
+	if _, err := os.Stat(dockerfile); err == nil && !force {
+		return nil, fmt.Errorf("%s already exists, use --force to overwrite it", dockerfile)
+	}
+	data, err := detect.Dockerfile(plan)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(dockerfile, data, 0o644); err != nil {
+		return nil, err
+	}
+	files := []string{buildcontext.DefaultDockerfile}
+
+	ignoreFile := filepath.Join(dir, buildcontext.IgnoreFile)
+	if _, err := os.Stat(ignoreFile); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(ignoreFile, detect.DockerIgnore(plan), 0o644); err != nil {
+			return nil, err
+		}
+		files = append(files, buildcontext.IgnoreFile)
+	}
+	return files, nil
+}
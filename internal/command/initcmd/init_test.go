@@ -0,0 +1,87 @@
+package initcmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/command/cmdtest"
+)
+
+func newApp(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"package.json": `{"name": "app", "engines": {"node": "18"}}`,
+		"server.js":    "console.log('hello')\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestInit(t *testing.T) {
+	e := cmdtest.New(t)
+	dir := newApp(t)
+
+	res := e.MustRun("init", dir)
+	for _, want := range []string{"Detected a node app", "node 18 (from package.json)", "node server.js", "a0ctl init --dockerfile"} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("stdout = %q, want %q in it", res.Stdout, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Dockerfile")); !os.IsNotExist(err) {
+		t.Errorf("init without --dockerfile wrote a Dockerfile: %v", err)
+	}
+}
+
+func TestInitDockerfile(t *testing.T) {
+	e := cmdtest.New(t)
+	dir := newApp(t)
+	if err := os.WriteFile(filepath.Join(dir, ".dockerignore"), []byte("tmp\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res := e.MustRun("init", dir, "--dockerfile", "-o", "json")
+	var out struct {
+		Plan struct {
+			Runtime string `json:"runtime"`
+		} `json:"plan"`
+		Files []string `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(res.Stdout), &out); err != nil {
+		t.Fatalf("%v\n%s", err, res.Stdout)
+	}
+	if out.Plan.Runtime != "node" || len(out.Files) != 1 || out.Files[0] != "Dockerfile" {
+		t.Errorf("output = %+v", out)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "Dockerfile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "FROM node:18-alpine\n") || !strings.Contains(string(data), `CMD ["node", "server.js"]`) {
+		t.Errorf("Dockerfile =\n%s", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ".dockerignore")); string(data) != "tmp\n" {
+		t.Errorf("the existing .dockerignore was replaced with %q", data)
+	}
+
+	res = e.Run("init", dir, "--dockerfile")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "already exists, use --force") {
+		t.Errorf("err = %v", res.Err)
+	}
+	e.MustRun("init", dir, "--dockerfile", "--force")
+}
+
+func TestInitNotDetected(t *testing.T) {
+	e := cmdtest.New(t)
+
+	res := e.Run("init", t.TempDir())
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "could not detect the runtime") {
+		t.Errorf("err = %v", res.Err)
+	}
+}
@@ -0,0 +1,43 @@
+// Package instances provides commands to manage the running instances of an app.
+package instances
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+func New() *cobra.Command {
+	const (
+		short = "Manage app instances"
+	)
+
+	cmd := &cobra.Command{
+		Use:     "instances",
+		Aliases: []string{"instance"},
+		Short:   short,
+	}
+
+	flags.AddApp(cmd)
+
+	cmd.AddCommand(newList(), newRestart(), newStop())
+
+	return cmd
+}
+
+// formatUptime formats d in the largest whole units, e.g. 3d4h or 12m.
+func formatUptime(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+}
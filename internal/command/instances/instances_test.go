@@ -0,0 +1,67 @@
+package instances_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdtest"
+)
+
+func TestList(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+	started := time.Now().Add(-2 * time.Hour)
+	e.Server.HandleJSON("GET /v1/apps/web/instances", http.StatusOK, map[string][]api.Instance{
+		"instances": {
+			{ID: "i-1", Region: "fra", Size: "small", State: api.InstanceStateRunning, Health: api.InstanceHealthHealthy, StartedAt: &started},
+			{ID: "i-2", Region: "iad", Size: "small", State: api.InstanceStateStopped, Health: api.InstanceHealthUnknown},
+		},
+	})
+
+	res := e.MustRun("instances", "list", "--app", "web")
+	for _, want := range []string{"i-1", "fra", "healthy", "i-2", "stopped"} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("stdout doesn't contain %q:\n%s", want, res.Stdout)
+		}
+	}
+}
+
+func TestStopAndRestart(t *testing.T) {
+	tests := []struct {
+		action string
+		want   string
+	}{
+		{"stop", "Stopping instance i-1."},
+		{"restart", "Restarting instance i-1."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			e := cmdtest.New(t)
+			e.Login()
+			e.Server.HandleJSON("POST /v1/apps/web/instances/i-1/"+tt.action, http.StatusOK, map[string]bool{"ok": true})
+
+			res := e.MustRun("instances", tt.action, "i-1", "--app", "web", "--yes")
+			if !strings.Contains(res.Stdout, tt.want) {
+				t.Errorf("stdout = %q, want %q", res.Stdout, tt.want)
+			}
+
+			reqs := e.Server.Requests()
+			if last := reqs[len(reqs)-1]; last.Method != http.MethodPost || last.Path != "/v1/apps/web/instances/i-1/"+tt.action {
+				t.Errorf("last request = %s %s", last.Method, last.Path)
+			}
+		})
+	}
+}
+
+func TestStopNeedsConfirmation(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+
+	res := e.Run("instances", "stop", "i-1", "--app", "web", "--no-input")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "--yes") {
+		t.Errorf("err = %v", res.Err)
+	}
+}
@@ -0,0 +1,52 @@
+package instances
+
+import (
+	"io"
+
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+func newList() *cobra.Command {
+	const (
+		use   = "list"
+		short = "List the instances of an app"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Aliases:           []string{"ls"},
+		Short:             short,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              list,
+	}
+	return cmd
+}
+
+func list(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	instances, err := client.Instances.List(app)
+	if err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), instances, func(w io.Writer) error {
+		table := cli.NewTable(w, "ID", "REGION", "SIZE", "STATE", "HEALTH", "UPTIME")
+		for _, i := range instances {
+			table.Row(i.ID, i.Region, i.Size, i.State, i.Health, formatUptime(i.Uptime()))
+		}
+		return table.Flush()
+	})
+}
@@ -0,0 +1,42 @@
+package instances
+
+import (
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/completion"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+func newRestart() *cobra.Command {
+	const (
+		use   = "restart <id>"
+		short = "Restart an app instance"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completion.FirstArg(completion.Instances),
+		RunE:              restart,
+	}
+	return cmd
+}
+
+func restart(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Instances.Restart(app, args[0]); err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).PrintMessage(cmd.OutOrStdout(), "Restarting instance %s.", args[0])
+}
@@ -0,0 +1,49 @@
+package instances
+
+import (
+	"fmt"
+
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/completion"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/a0dotrun/a0ctl/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+func newStop() *cobra.Command {
+	const (
+		use   = "stop <id>"
+		short = "Stop an app instance"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completion.FirstArg(completion.Instances),
+		RunE:              stop,
+	}
+	return cmd
+}
+
+func stop(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	if err := prompt.ConfirmAction(cmd, fmt.Sprintf("Stop instance %s of %s?", args[0], app)); err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Instances.Stop(app, args[0]); err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).PrintMessage(cmd.OutOrStdout(), "Stopping instance %s.", args[0])
+}
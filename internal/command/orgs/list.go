@@ -0,0 +1,59 @@
+package orgs
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/spf13/cobra"
+)
+
+func newList() *cobra.Command {
+	const (
+		use   = "list"
+		short = "List the organizations you belong to"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Aliases:           []string{"ls"},
+		Short:             short,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              list,
+	}
+	return cmd
+}
+
+func list(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	orgs, err := client.Orgs.List()
+	if err != nil {
+		return err
+	}
+
+	current, _ := currentOrg(cmd)
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), orgs, func(w io.Writer) error {
+		table := cli.NewTable(w, "", "SLUG", "NAME", "ROLE")
+		for _, o := range orgs {
+			marker := ""
+			if o.Slug == current {
+				marker = "*"
+			}
+			table.Row(marker, o.Slug, o.Name, o.Role)
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+
+		if current == "" {
+			fmt.Fprintf(w, "\nCommands are scoped to your personal account. Use %s to select an organization.\n", cli.Emph("a0ctl orgs switch"))
+		}
+		return nil
+	})
+}
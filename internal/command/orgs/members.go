@@ -0,0 +1,211 @@
+package orgs
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var inviteRole string
+
+func newMembers() *cobra.Command {
+	const (
+		short = "Manage members of the current organization"
+	)
+
+	cmd := &cobra.Command{
+		Use:     "members",
+		Aliases: []string{"member"},
+		Short:   short,
+	}
+
+	cmd.AddCommand(newMembersList(), newInvite(), newRemove(), newSetRole())
+
+	return cmd
+}
+
+func newMembersList() *cobra.Command {
+	const (
+		use   = "list"
+		short = "List members of the current organization"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Aliases:           []string{"ls"},
+		Short:             short,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              listMembers,
+	}
+	return cmd
+}
+
+func listMembers(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	org, err := currentOrg(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	members, err := client.Orgs.Members(org)
+	if err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), members, func(w io.Writer) error {
+		table := cli.NewTable(w, "USERNAME", "EMAIL", "ROLE", "JOINED")
+		for _, m := range members {
+			table.Row(m.Username, m.Email, m.Role, m.JoinedAt.Local().Format(time.DateOnly))
+		}
+		return table.Flush()
+	})
+}
+
+func newInvite() *cobra.Command {
+	const (
+		use   = "invite [email]"
+		short = "Invite someone to the current organization"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              invite,
+	}
+	cmd.Flags().StringVar(&inviteRole, "role", api.OrgRoleMember, fmt.Sprintf("Role of the new member, one of %v", api.OrgRoles))
+	return cmd
+}
+
+func invite(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	if err := api.ValidateOrgRole(inviteRole); err != nil {
+		return err
+	}
+
+	org, err := currentOrg(cmd)
+	if err != nil {
+		return err
+	}
+
+	var email string
+	if len(args) == 1 {
+		email = args[0]
+	} else if email, err = prompt.Text(cmd, "Email address to invite", ""); err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	invitation, err := client.Orgs.Invite(org, email, inviteRole)
+	if err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), invitation, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "✔  Success! Invited %s to %s as %s. The invitation expires %s.\n",
+			invitation.Email, cli.Emph(org), invitation.Role, invitation.ExpiresAt.Local().Format(time.DateTime))
+		return err
+	})
+}
+
+func newRemove() *cobra.Command {
+	const (
+		use   = "remove <username>"
+		short = "Remove a member from the current organization"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Aliases:           []string{"rm"},
+		Short:             short,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              remove,
+	}
+	return cmd
+}
+
+func remove(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	org, err := currentOrg(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := prompt.ConfirmAction(cmd, fmt.Sprintf("Remove %s from %s?", args[0], org)); err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Orgs.RemoveMember(org, args[0]); err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).PrintMessage(cmd.OutOrStdout(), "Removed %s from %s.", args[0], org)
+}
+
+func newSetRole() *cobra.Command {
+	const (
+		use   = "set-role <username> <role>"
+		short = "Change the role of a member of the current organization"
+	)
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(2),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 1 {
+				return api.OrgRoles, cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: setRole,
+	}
+	return cmd
+}
+
+func setRole(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	username, role := args[0], args[1]
+	if err := api.ValidateOrgRole(role); err != nil {
+		return err
+	}
+
+	org, err := currentOrg(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	member, err := client.Orgs.SetRole(org, username, role)
+	if err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), member, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "✔  Success! %s is now %s of %s\n", member.Username, member.Role, org)
+		return err
+	})
+}
@@ -0,0 +1,51 @@
+// Package orgs provides commands to manage organizations and their members.
+package orgs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+func New() *cobra.Command {
+	const (
+		short = "Manage organizations"
+		long  = "List and switch between the organizations you belong to and manage " +
+			"their members.\n\nResource commands are scoped to the organization " +
+			"selected with `a0ctl orgs switch`, or to the one given with --org."
+	)
+
+	cmd := &cobra.Command{
+		Use:     "orgs",
+		Aliases: []string{"org"},
+		Short:   short,
+		Long:    long,
+	}
+
+	cmd.AddCommand(newList(), newSwitch(), newShow(), newMembers())
+
+	return cmd
+}
+
+var errNoOrg = errors.New("no organization selected, pass one with " + cli.Emph("--org") +
+	" or select one with " + cli.Emph("a0ctl orgs switch"))
+
+// currentOrg returns the organization given with --org or the default one.
+func currentOrg(cmd *cobra.Command) (string, error) {
+	if org := flags.Org(); org != "" {
+		return org, nil
+	}
+
+	config, err := cmdutil.Settings(cmd)
+	if err != nil {
+		return "", fmt.Errorf("could not retrieve local config: %w", err)
+	}
+	if org := config.GetOrg(); org != "" {
+		return org, nil
+	}
+	return "", errNoOrg
+}
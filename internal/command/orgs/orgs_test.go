@@ -0,0 +1,69 @@
+package orgs_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdtest"
+)
+
+func newEnv(t *testing.T) *cmdtest.Env {
+	t.Helper()
+	e := cmdtest.New(t)
+	e.Login()
+	e.Server.AddOrg(api.Org{ID: "org_1", Slug: "acme", Name: "Acme Inc", Role: api.OrgRoleAdmin})
+	return e
+}
+
+func TestSwitch(t *testing.T) {
+	e := newEnv(t)
+
+	e.MustRun("orgs", "switch", "acme")
+	if got := e.Setting("profiles.default.org"); got != "acme" {
+		t.Fatalf("org = %v, want acme", got)
+	}
+	res := e.MustRun("orgs", "list")
+	if !strings.Contains(res.Stdout, "*   acme") {
+		t.Errorf("list doesn't mark acme as current:\n%s", res.Stdout)
+	}
+
+	e.MustRun("orgs", "switch", "--personal")
+	if got := e.Setting("profiles.default.org"); got != "" {
+		t.Errorf("org = %v after switching to the personal account", got)
+	}
+
+	if res := e.Run("orgs", "switch", "missing"); res.Err == nil {
+		t.Error("switched to an unknown organization")
+	}
+}
+
+func TestOrgFlagScopesRequests(t *testing.T) {
+	e := newEnv(t)
+	e.Server.AddApp(api.App{Name: "site", Org: "acme"})
+
+	e.MustRun("orgs", "show", "--org", "acme")
+	reqs := e.Server.Requests()
+	last := reqs[len(reqs)-1]
+	if last.Path != "/v1/orgs/acme" || last.Header.Get("a0org") != "acme" {
+		t.Errorf("last request = %s with a0org %q", last.Path, last.Header.Get("a0org"))
+	}
+}
+
+func TestMembers(t *testing.T) {
+	e := newEnv(t)
+	e.Server.HandleJSON("GET /v1/orgs/acme/members", http.StatusOK, map[string][]api.Member{
+		"members": {{UserID: "u1", Username: "jane", Role: api.OrgRoleAdmin}},
+	})
+
+	res := e.MustRun("orgs", "members", "list", "--org", "acme")
+	if !strings.Contains(res.Stdout, "jane") || !strings.Contains(res.Stdout, api.OrgRoleAdmin) {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+
+	res = e.Run("orgs", "members", "list")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "no organization selected") {
+		t.Errorf("err = %v without an organization", res.Err)
+	}
+}
@@ -0,0 +1,59 @@
+package orgs
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/completion"
+	"github.com/spf13/cobra"
+)
+
+func newShow() *cobra.Command {
+	const (
+		use   = "show [slug]"
+		short = "Show details of an organization, the current one by default"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completion.FirstArg(completion.Orgs),
+		RunE:              show,
+	}
+	return cmd
+}
+
+func show(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	var slug string
+	if len(args) == 1 {
+		slug = args[0]
+	} else {
+		var err error
+		if slug, err = currentOrg(cmd); err != nil {
+			return err
+		}
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	org, err := client.Orgs.Get(slug)
+	if err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), org, func(w io.Writer) error {
+		fmt.Fprintf(w, "Slug:      %s\n", cli.Emph(org.Slug))
+		fmt.Fprintf(w, "Name:      %s\n", org.Name)
+		fmt.Fprintf(w, "ID:        %s\n", org.ID)
+		fmt.Fprintf(w, "Your role: %s\n", org.Role)
+		_, err := fmt.Fprintf(w, "Created:   %s\n", org.CreatedAt.Local().Format(time.DateTime))
+		return err
+	})
+}
@@ -0,0 +1,111 @@
+package orgs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/completion"
+	"github.com/a0dotrun/a0ctl/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var personal bool
+
+func newSwitch() *cobra.Command {
+	const (
+		use   = "switch [slug]"
+		short = "Select the default organization"
+		long  = "Select the organization commands are scoped to by default. Use " +
+			"--personal to go back to your personal account. Without arguments, " +
+			"asks which organization to select."
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Long:              long,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completion.FirstArg(completion.Orgs),
+		RunE:              switchOrg,
+	}
+	cmd.Flags().BoolVar(&personal, "personal", false, "Scope commands to your personal account")
+	return cmd
+}
+
+func switchOrg(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	if personal && len(args) == 1 {
+		return errors.New("pass either an organization slug or --personal")
+	}
+
+	config, err := cmdutil.Settings(cmd)
+	if err != nil {
+		return fmt.Errorf("could not retrieve local config: %w", err)
+	}
+
+	var org api.Org
+	if !personal {
+		client, err := cmdutil.Client(cmd)
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 1 {
+			org, err = client.Orgs.Get(args[0])
+		} else {
+			org, err = selectOrg(cmd, client)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if org.Slug == "" {
+		config.SetOrg("")
+		if err := config.Persist(); err != nil {
+			return err
+		}
+		return cmdutil.Output(cmd).PrintMessage(cmd.OutOrStdout(), "Switched to your personal account.")
+	}
+
+	config.SetOrg(org.Slug)
+	if err := config.Persist(); err != nil {
+		return err
+	}
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), org, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "✔  Success! Switched to %s (%s)\n", cli.Emph(org.Slug), org.Name)
+		return err
+	})
+}
+
+// selectOrg asks which organization to switch to. It returns an empty Org
+// when the personal account is chosen.
+func selectOrg(cmd *cobra.Command, client *api.Client) (api.Org, error) {
+	orgs, err := client.Orgs.List()
+	if err != nil {
+		return api.Org{}, err
+	}
+
+	// The client is scoped to the current organization.
+	current := client.Org
+	options := []string{"Personal account"}
+	def := 0
+	for i, o := range orgs {
+		options = append(options, fmt.Sprintf("%s (%s)", o.Slug, o.Name))
+		if o.Slug == current {
+			def = i + 1
+		}
+	}
+
+	choice, err := prompt.Select(cmd, "Which organization do you want to use?", options, def)
+	if err != nil {
+		return api.Org{}, err
+	}
+	if choice == 0 {
+		return api.Org{}, nil
+	}
+	return orgs[choice-1], nil
+}
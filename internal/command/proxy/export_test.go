@@ -0,0 +1,15 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+)
+
+// Handle tunnels conn to port of an instance of app.
+func Handle(ctx context.Context, client *api.Client, app string, port int, conn net.Conn) {
+	p := &proxier{client: client, app: app, port: port, messages: io.Discard}
+	p.handle(ctx, conn)
+}
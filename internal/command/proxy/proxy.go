@@ -0,0 +1,209 @@
+// Package proxy provides the command to forward local ports to app instances.
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+var bindAddr string
+
+func New() *cobra.Command {
+	const (
+		use   = "proxy <local-port>[:<remote-port>]"
+		short = "Forward a local port to an app instance"
+		long  = "Listen on a local port and tunnel every connection to a port of an " +
+			"app instance, e.g. to reach a database that isn't exposed publicly.\n\n" +
+			"Press Ctrl-C to stop the proxy."
+		example = "  a0ctl proxy 5432 --app db\n" +
+			"  a0ctl proxy 15432:5432 --app db --instance 9a8b7c"
+	)
+
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Long:              long,
+		Example:           example,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              proxy,
+	}
+
+	flags.AddApp(cmd)
+	flags.AddInstance(cmd)
+	cmd.Flags().StringVar(&bindAddr, "bind", "127.0.0.1", "Local address to listen on")
+
+	return cmd
+}
+
+func proxy(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	localPort, remotePort, err := parsePorts(args[0])
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(bindAddr, strconv.Itoa(localPort)))
+	if err != nil {
+		return fmt.Errorf("could not listen on port %d: %w", localPort, err)
+	}
+
+	messages := cmdutil.Messages(cmd)
+	p := &proxier{client: client, app: app, instance: flags.Instance(), port: remotePort, messages: messages}
+	fmt.Fprintf(messages, "Proxying %s to port %d of %s\n", cli.Emph(listener.Addr().String()), remotePort, app)
+	fmt.Fprintln(messages, "Press Ctrl-C to stop.")
+	return p.serve(ctx, listener)
+}
+
+// parsePorts parses "local:remote" or a single port used for both.
+func parsePorts(arg string) (local, remote int, err error) {
+	localStr, remoteStr, found := strings.Cut(arg, ":")
+	if !found {
+		remoteStr = localStr
+	}
+
+	local, err = parsePort(localStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	remote, err = parsePort(remoteStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	return local, remote, nil
+}
+
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+	return port, nil
+}
+
+type proxier struct {
+	client   *api.Client
+	app      string
+	instance string
+	port     int
+	messages io.Writer
+
+	lastID atomic.Int64
+}
+
+// serve accepts connections until ctx is done, then closes the listener and
+// all open tunnels and waits for them to finish.
+func (p *proxier) serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				fmt.Fprintln(p.messages, "Shutting down proxy...")
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.handle(ctx, conn)
+		}()
+	}
+}
+
+func (p *proxier) handle(ctx context.Context, conn net.Conn) {
+	id := p.lastID.Add(1)
+	start := time.Now()
+	log.Printf("[#%d] accepted connection from %s", id, conn.RemoteAddr())
+
+	tunnel, err := p.client.Proxy.Dial(ctx, p.app, p.instance, p.port)
+	if err != nil {
+		log.Printf("[#%d] %v", id, err)
+		_ = conn.Close()
+		return
+	}
+
+	// Closing both ends on shutdown unblocks the copies below.
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+		_ = tunnel.Close()
+	})
+	defer stop()
+
+	// Each side may stop sending and still wait for the other to answer,
+	// so the end of one copy only half-closes its destination when it can.
+	// Tunnels can't half-close: once the local client is done, the tunnel
+	// is closed, which also ends the copy of the answers.
+	var sent, received int64
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		sent, _ = io.Copy(tunnel, conn)
+		if !closeWrite(tunnel) {
+			_ = tunnel.Close()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		received, _ = io.Copy(conn, tunnel)
+		if !closeWrite(conn) {
+			_ = conn.Close()
+		}
+	}()
+	wg.Wait()
+	_ = conn.Close()
+	_ = tunnel.Close()
+
+	log.Printf("[#%d] closed after %s, sent %d bytes, received %d bytes",
+		id, time.Since(start).Round(time.Millisecond), sent, received)
+}
+
+// closeWrite signals the end of the data sent to conn, and reports whether
+// conn supports half-closing like TCP connections do.
+func closeWrite(conn net.Conn) bool {
+	c, ok := conn.(interface{ CloseWrite() error })
+	if ok {
+		_ = c.CloseWrite()
+	}
+	return ok
+}
@@ -0,0 +1,53 @@
+package proxy_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/api/apitest"
+	"github.com/a0dotrun/a0ctl/internal/command/proxy"
+)
+
+func TestHandleEndsWithLocalClient(t *testing.T) {
+	s := apitest.New(t)
+	s.AddApp(api.App{Name: "web"})
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := api.NewClient(u, apitest.DefaultToken, apitest.DefaultUsername)
+
+	local, conn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		proxy.Handle(context.Background(), client, "web", 5432, conn)
+	}()
+
+	if _, err := local.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(local, buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("read %q, %v", buf, err)
+	}
+
+	// The remote stays idle: only the local client hangs up.
+	_ = local.Close()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handle didn't return after the local client disconnected")
+	}
+	for deadline := time.Now().Add(5 * time.Second); s.OpenTunnels() > 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("the tunnel is still open")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
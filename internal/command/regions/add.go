@@ -0,0 +1,24 @@
+package regions
+
+import (
+	"github.com/a0dotrun/a0ctl/internal/completion"
+	"github.com/spf13/cobra"
+)
+
+func newAdd() *cobra.Command {
+	const (
+		use   = "add <region>..."
+		short = "Place an app in additional regions"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completion.Regions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return updateRegions(cmd, args, nil)
+		},
+	}
+	return cmd
+}
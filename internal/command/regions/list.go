@@ -0,0 +1,152 @@
+package regions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/spf13/cobra"
+)
+
+const latencyTimeout = 3 * time.Second
+
+var noLatency bool
+
+func newList() *cobra.Command {
+	const (
+		use   = "list"
+		short = "List available regions"
+		long  = "List the regions apps can run in, with the latency from this " +
+			"machine to each of them."
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Aliases:           []string{"ls"},
+		Short:             short,
+		Long:              long,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              list,
+	}
+	cmd.Flags().BoolVar(&noLatency, "no-latency", false, "Don't measure the latency to each region")
+	return cmd
+}
+
+func list(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	regions, err := client.Regions.List()
+	if err != nil {
+		return err
+	}
+
+	var latencies []time.Duration
+	if !noLatency {
+		latencies = measureLatencies(cmd.Context(), regions)
+	}
+
+	results := make([]regionResult, len(regions))
+	for i, r := range regions {
+		results[i] = regionResult{Code: r.Code, Name: r.Name}
+		if latencies != nil && latencies[i] != 0 {
+			results[i].LatencyMs = latencies[i].Milliseconds()
+		}
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), results, func(w io.Writer) error {
+		if noLatency {
+			table := cli.NewTable(w, "CODE", "NAME")
+			for _, r := range regions {
+				table.Row(r.Code, r.Name)
+			}
+			return table.Flush()
+		}
+
+		table := cli.NewTable(w, "CODE", "NAME", "LATENCY")
+		for i, r := range regions {
+			table.Row(r.Code, r.Name, formatLatency(latencies[i]))
+		}
+		return table.Flush()
+	})
+}
+
+// regionResult is a region with the latency measured from this machine.
+type regionResult struct {
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	LatencyMs int64  `json:"latencyMs,omitempty"`
+}
+
+// measureLatencies estimates the round trip time to each region
+// concurrently. Unreachable regions get a zero latency.
+func measureLatencies(ctx context.Context, regions []api.Region) []time.Duration {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	latencies := make([]time.Duration, len(regions))
+	var wg sync.WaitGroup
+	for i, r := range regions {
+		if r.PingURL == "" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			latencies[i] = measureLatency(ctx, r.PingURL)
+		}()
+	}
+	wg.Wait()
+	return latencies
+}
+
+// measureLatency times a request to url over an already established
+// connection, so that DNS and TLS handshakes don't skew the result.
+func measureLatency(ctx context.Context, url string) time.Duration {
+	ctx, cancel := context.WithTimeout(ctx, latencyTimeout)
+	defer cancel()
+
+	// A transport per region keeps the connection of each one apart, and
+	// is closed once measured.
+	client := &http.Client{Transport: &http.Transport{}}
+	defer client.CloseIdleConnections()
+	ping := func() (time.Duration, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+		if err != nil {
+			return 0, err
+		}
+		start := time.Now()
+		res, err := client.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		_ = res.Body.Close()
+		return time.Since(start), nil
+	}
+
+	if _, err := ping(); err != nil {
+		return 0
+	}
+	d, err := ping()
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+func formatLatency(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
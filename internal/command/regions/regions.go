@@ -0,0 +1,115 @@
+// Package regions provides commands to list regions and place apps in them.
+package regions
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/a0dotrun/a0ctl/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+func New() *cobra.Command {
+	const (
+		short = "List regions and place apps in them"
+		long  = "List the regions apps can run in, and add or remove an app from " +
+			"regions to place it close to its users."
+	)
+
+	cmd := &cobra.Command{
+		Use:     "regions",
+		Aliases: []string{"region"},
+		Short:   short,
+		Long:    long,
+	}
+
+	addCmd := newAdd()
+	removeCmd := newRemove()
+	flags.AddApp(addCmd)
+	flags.AddApp(removeCmd)
+
+	cmd.AddCommand(newList(), addCmd, removeCmd)
+
+	return cmd
+}
+
+// appRegions is the output of commands changing the regions of an app.
+type appRegions struct {
+	App     string   `json:"app"`
+	Regions []string `json:"regions"`
+}
+
+func updateRegions(cmd *cobra.Command, add, remove []string) error {
+	w := cmd.OutOrStdout()
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := validateCodes(client, append(add, remove...)); err != nil {
+		return err
+	}
+
+	regions, err := client.Regions.UpdateAppRegions(app, add, remove)
+	if err != nil {
+		return err
+	}
+
+	err = cmdutil.Output(cmd).Print(w, appRegions{App: app, Regions: regions}, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "%s now runs in: %s\n", app, cli.Emph(strings.Join(regions, ", ")))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return recordInManifest(cmdutil.Messages(cmd), app, regions)
+}
+
+func validateCodes(client *api.Client, codes []string) error {
+	regions, err := client.Regions.List()
+	if err != nil {
+		return err
+	}
+
+	for _, code := range codes {
+		if !slices.ContainsFunc(regions, func(r api.Region) bool { return r.Code == code }) {
+			return fmt.Errorf("unknown region %q, run %s to see the available regions", code, cli.Emph("a0ctl regions list"))
+		}
+	}
+	return nil
+}
+
+// recordInManifest keeps the regions in the app manifest in sync, if the
+// current directory has a manifest for the app.
+func recordInManifest(w io.Writer, app string, regions []string) error {
+	m, err := manifest.Load()
+	if errors.Is(err, manifest.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if m.App != app {
+		return nil
+	}
+
+	m.Regions = regions
+	if err := m.Save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Updated regions in %s\n", m.Path())
+	return nil
+}
@@ -0,0 +1,47 @@
+package regions_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdtest"
+	"github.com/a0dotrun/a0ctl/internal/manifest"
+)
+
+func TestAddKeepsManifestKeys(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+	e.Server.AddRegion(api.Region{Code: "fra", Name: "Frankfurt"})
+	e.Server.AddRegion(api.Region{Code: "iad", Name: "Washington"})
+	e.Server.HandleJSON("PATCH /v1/apps/web/regions", http.StatusOK, map[string][]string{"regions": {"fra", "iad"}})
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, manifest.FileName)
+	data := `{"app": "web", "regions": ["fra"], "healthcheck": {"path": "/up"}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	e.MustRun("regions", "add", "iad")
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values struct {
+		App         string
+		Regions     []string
+		Healthcheck map[string]string
+	}
+	if err := json.Unmarshal(got, &values); err != nil {
+		t.Fatal(err)
+	}
+	if values.App != "web" || len(values.Regions) != 2 || values.Healthcheck["path"] != "/up" {
+		t.Errorf("manifest = %s", got)
+	}
+}
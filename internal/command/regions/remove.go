@@ -0,0 +1,25 @@
+package regions
+
+import (
+	"github.com/a0dotrun/a0ctl/internal/completion"
+	"github.com/spf13/cobra"
+)
+
+func newRemove() *cobra.Command {
+	const (
+		use   = "remove <region>..."
+		short = "Remove an app from regions"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Aliases:           []string{"rm"},
+		Short:             short,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completion.AppRegions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return updateRegions(cmd, nil, args)
+		},
+	}
+	return cmd
+}
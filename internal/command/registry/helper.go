@@ -0,0 +1,115 @@
+package registry
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/spf13/cobra"
+)
+
+// errCredentialsNotFound is the message Docker expects from credential
+// helpers when they have no credentials for a registry.
+const errCredentialsNotFound = "credentials not found in native keychain"
+
+// helperCredentials are the credentials of a registry, in the format of
+// the Docker credential helper protocol.
+type helperCredentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+func newCredentialHelper() *cobra.Command {
+	const (
+		short = "Docker credential helper for the a0 registry"
+		long  = "Implements the Docker credential helper protocol. Docker runs it as " +
+			"docker-credential-a0ctl once configured by `a0ctl registry login`."
+	)
+
+	cmd := &cobra.Command{
+		Use:       "credential-helper get|store|erase|list",
+		Short:     short,
+		Long:      long,
+		Hidden:    true,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"get", "store", "erase", "list"},
+		RunE:      credentialHelper,
+	}
+	return cmd
+}
+
+func credentialHelper(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	w := cmd.OutOrStdout()
+
+	switch args[0] {
+	case "get":
+		server, err := readServerURL(cmd.InOrStdin())
+		if err != nil {
+			return err
+		}
+		creds, err := getCredentials(cmd, server)
+		if err != nil {
+			// Docker reads errors from stdout.
+			fmt.Fprintln(w, err)
+			return &cli.ExitError{Code: 1}
+		}
+		return json.NewEncoder(w).Encode(creds)
+	case "store", "erase":
+		// Credentials are short-lived and fetched on demand, there is
+		// nothing to store.
+		_, err := io.Copy(io.Discard, cmd.InOrStdin())
+		return err
+	case "list":
+		registries := map[string]string{}
+		if client, err := cmdutil.Client(cmd); err == nil {
+			if creds, err := client.Registry.Credentials(); err == nil {
+				registries[creds.Registry] = creds.Username
+			}
+		}
+		return json.NewEncoder(w).Encode(registries)
+	default:
+		return fmt.Errorf("unknown credential helper action %q, expected get, store, erase or list", args[0])
+	}
+}
+
+// getCredentials returns credentials for server, if it is the a0 registry.
+func getCredentials(cmd *cobra.Command, server string) (helperCredentials, error) {
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return helperCredentials{}, err
+	}
+	creds, err := client.Registry.Credentials()
+	if err != nil {
+		return helperCredentials{}, err
+	}
+	if registryHost(server) != registryHost(creds.Registry) {
+		return helperCredentials{}, fmt.Errorf("%s", errCredentialsNotFound)
+	}
+	return helperCredentials{ServerURL: server, Username: creds.Username, Secret: creds.Password}, nil
+}
+
+func readServerURL(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	server := strings.TrimSpace(line)
+	if server == "" {
+		return "", fmt.Errorf("no server URL on stdin")
+	}
+	return server, nil
+}
+
+// registryHost returns the host of a registry given as a URL or a host.
+func registryHost(server string) string {
+	server = strings.TrimPrefix(server, "https://")
+	server = strings.TrimPrefix(server, "http://")
+	host, _, _ := strings.Cut(server, "/")
+	return host
+}
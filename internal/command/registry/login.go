@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/a0dotrun/a0ctl/internal/builder"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/spf13/cobra"
+)
+
+// loginResult is what login prints.
+type loginResult struct {
+	Registry   string `json:"registry"`
+	Namespace  string `json:"namespace"`
+	ConfigPath string `json:"configPath"`
+}
+
+func newLogin() *cobra.Command {
+	const (
+		short = "Configure Docker to push to the a0 registry"
+		long  = "Configure Docker to get credentials for the a0 registry from a0ctl, " +
+			"with the account you are logged in with.\n\n" +
+			"Credentials are short-lived and fetched whenever Docker needs them, so " +
+			"nothing secret is written to the Docker configuration."
+	)
+
+	cmd := &cobra.Command{
+		Use:               "login",
+		Short:             short,
+		Long:              long,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              login,
+	}
+	return cmd
+}
+
+func login(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	creds, err := client.Registry.Credentials()
+	if err != nil {
+		return err
+	}
+
+	path, err := builder.DockerConfigPath()
+	if err != nil {
+		return fmt.Errorf("could not find the Docker configuration: %w", err)
+	}
+	if err := builder.UseCredentialHelper(path, creds.Registry); err != nil {
+		return fmt.Errorf("could not update the Docker configuration: %w", err)
+	}
+
+	w := cmd.OutOrStdout()
+	installHelper(cmdutil.Messages(cmd))
+
+	res := loginResult{Registry: creds.Registry, Namespace: creds.Namespace, ConfigPath: path}
+	return cmdutil.Output(cmd).Print(w, res, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "✔  Success! Docker now authenticates to %s with a0ctl. Push images to %s\n",
+			creds.Registry, cli.Emph(creds.Repository("<app>")+":<tag>"))
+		return err
+	})
+}
+
+// installHelper makes sure Docker finds the credential helper, by linking
+// it to a0ctl next to the a0ctl executable if needed.
+func installHelper(w io.Writer) {
+	name := builder.CredentialHelperPrefix + builder.CredentialHelper
+	if _, err := exec.LookPath(name); err == nil {
+		return
+	}
+
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "Warning: could not find the a0ctl executable, add a link to it named %s to your PATH\n", name)
+		return
+	}
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	link := filepath.Join(filepath.Dir(exe), name)
+	if err := os.Symlink(exe, link); err != nil && !os.IsExist(err) {
+		fmt.Fprintf(w, "Warning: could not install the credential helper: %v\nLink it to your PATH with: ln -s %s <dir in PATH>/%s\n", err, exe, name)
+		return
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		fmt.Fprintf(w, "Warning: %s is not in your PATH, add %s to it for Docker to find the credential helper\n", link, filepath.Dir(link))
+	}
+}
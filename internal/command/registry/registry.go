@@ -0,0 +1,24 @@
+// Package registry provides commands to use the a0 container registry with
+// Docker.
+package registry
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func New() *cobra.Command {
+	const (
+		short = "Use the a0 container registry"
+		long  = "Push images built with your own tools to the a0 registry."
+	)
+
+	cmd := &cobra.Command{
+		Use:   "registry",
+		Short: short,
+		Long:  long,
+	}
+
+	cmd.AddCommand(newLogin(), newCredentialHelper())
+
+	return cmd
+}
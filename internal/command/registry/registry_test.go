@@ -0,0 +1,110 @@
+package registry_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api/apitest"
+	"github.com/a0dotrun/a0ctl/internal/builder"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdtest"
+)
+
+func TestLogin(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+	dockerConfig := t.TempDir()
+	t.Setenv(builder.EnvDockerConfig, dockerConfig)
+
+	// The credential helper is already installed.
+	bin := t.TempDir()
+	helper := filepath.Join(bin, builder.CredentialHelperPrefix+builder.CredentialHelper)
+	if err := os.WriteFile(helper, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	res := e.MustRun("registry", "login")
+	if !strings.Contains(res.Stdout, "Push images to "+apitest.Registry+"/jane/<app>:<tag>") {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+	if strings.Contains(res.Stdout, "Warning") {
+		t.Errorf("unexpected warning in %q", res.Stdout)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dockerConfig, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		CredHelpers map[string]string `json:"credHelpers"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.CredHelpers[apitest.Registry] != builder.CredentialHelper {
+		t.Errorf("credHelpers = %v", config.CredHelpers)
+	}
+	if strings.Contains(string(data), apitest.RegistryPassword(apitest.DefaultUsername)) {
+		t.Error("the registry password was written to the Docker configuration")
+	}
+}
+
+func TestLoginLoggedOut(t *testing.T) {
+	e := cmdtest.New(t)
+	t.Setenv(builder.EnvDockerConfig, t.TempDir())
+
+	if res := e.Run("registry", "login"); res.Err == nil {
+		t.Error("login succeeded while logged out")
+	}
+}
+
+func TestCredentialHelperGet(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+
+	res := e.RunInput("https://"+apitest.Registry+"\n", "registry", "credential-helper", "get")
+	if res.Err != nil {
+		t.Fatalf("%v\n%s", res.Err, res.Stdout)
+	}
+	var creds struct{ ServerURL, Username, Secret string }
+	if err := json.Unmarshal([]byte(res.Stdout), &creds); err != nil {
+		t.Fatalf("%v\n%s", err, res.Stdout)
+	}
+	want := apitest.RegistryPassword(apitest.DefaultUsername)
+	if creds.ServerURL != "https://"+apitest.Registry || creds.Username != apitest.DefaultUsername || creds.Secret != want {
+		t.Errorf("credentials = %+v", creds)
+	}
+}
+
+func TestCredentialHelperGetOtherRegistry(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+
+	res := e.RunInput("ghcr.io", "registry", "credential-helper", "get")
+	var exit *cli.ExitError
+	if !errors.As(res.Err, &exit) || exit.Code != 1 {
+		t.Fatalf("err = %v, want exit code 1", res.Err)
+	}
+	if strings.TrimSpace(res.Stdout) != "credentials not found in native keychain" {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+}
+
+func TestCredentialHelperList(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+
+	res := e.MustRun("registry", "credential-helper", "list")
+	var registries map[string]string
+	if err := json.Unmarshal([]byte(res.Stdout), &registries); err != nil {
+		t.Fatalf("%v\n%s", err, res.Stdout)
+	}
+	if len(registries) != 1 || registries[apitest.Registry] != apitest.DefaultUsername {
+		t.Errorf("registries = %v", registries)
+	}
+}
@@ -4,18 +4,45 @@
 package root
 
 import (
+	"context"
 	"log"
 	"os"
 	"path/filepath"
 
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
 	"github.com/a0dotrun/a0ctl/internal/command/config"
+	"github.com/a0dotrun/a0ctl/internal/command/deploy"
+	"github.com/a0dotrun/a0ctl/internal/command/doctor"
+	"github.com/a0dotrun/a0ctl/internal/command/domains"
+	"github.com/a0dotrun/a0ctl/internal/command/env"
+	"github.com/a0dotrun/a0ctl/internal/command/exec"
+	"github.com/a0dotrun/a0ctl/internal/command/initcmd"
+	"github.com/a0dotrun/a0ctl/internal/command/instances"
+	"github.com/a0dotrun/a0ctl/internal/command/orgs"
+	"github.com/a0dotrun/a0ctl/internal/command/proxy"
+	"github.com/a0dotrun/a0ctl/internal/command/regions"
+	"github.com/a0dotrun/a0ctl/internal/command/registry"
+	"github.com/a0dotrun/a0ctl/internal/command/scale"
+	"github.com/a0dotrun/a0ctl/internal/command/secrets"
+	"github.com/a0dotrun/a0ctl/internal/command/ssh"
+	"github.com/a0dotrun/a0ctl/internal/command/update"
 	"github.com/a0dotrun/a0ctl/internal/command/version"
+	"github.com/a0dotrun/a0ctl/internal/command/volumes"
 
 	"github.com/a0dotrun/a0ctl/internal/command/auth"
+	"github.com/a0dotrun/a0ctl/internal/completion"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/a0dotrun/a0ctl/internal/output"
+	"github.com/a0dotrun/a0ctl/internal/settings"
+	updatecheck "github.com/a0dotrun/a0ctl/internal/update"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
-func New() *cobra.Command {
+// New returns the root command, running its subcommands with the settings
+// and API clients of f.
+func New(f *cmdutil.Factory) *cobra.Command {
 	const (
 		long  = "This is a0ctl - the a0.run command line interface."
 		short = "The a0.run command line interface"
@@ -30,15 +57,96 @@ func New() *cobra.Command {
 		exe = filepath.Base(exePath)
 	}
 
+	// Run the root persistent hooks even for commands defining their own.
+	cobra.EnableTraverseRunHooks = true
+
 	root := &cobra.Command{
 		Use: exe, Short: short, Long: long,
+		// Errors are printed by main, which also handles exit codes.
+		SilenceErrors:     true,
+		PersistentPreRunE: preRun,
 	}
+	root.SetContext(cmdutil.NewContext(context.Background(), f))
 
 	root.AddCommand(
 		version.New(),
 		auth.New(),
 		config.New(),
+		initcmd.New(),
+		deploy.New(),
+		env.New(),
+		secrets.New(),
+		domains.New(),
+		scale.New(),
+		instances.New(),
+		regions.New(),
+		exec.New(),
+		ssh.New(),
+		proxy.New(),
+		volumes.New(),
+		registry.New(),
+		orgs.New(),
+		update.New(),
+		doctor.New(),
 	)
 
+	flags.AddOrg(root)
+	flags.AddProfile(root)
+	flags.AddResetConfigFlag(root)
+	flags.AddOutput(root)
+	flags.AddInput(root)
+
+	completion.Register(root)
+
 	return root
 }
+
+func preRun(cmd *cobra.Command, args []string) error {
+	// Commands report errors reading the settings when they need them.
+	config, _ := cmdutil.Settings(cmd)
+	if err := setupOutput(cmd, config); err != nil {
+		return err
+	}
+
+	// Only tell about new versions to people reading the output, and not
+	// while they are updating.
+	if config != nil && cmdutil.Output(cmd).IsTable() && cmd.Name() != "update" && !isCompletion(cmd) {
+		updatecheck.CheckInBackground(config)
+	}
+	return nil
+}
+
+func isCompletion(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Name() == "completion" {
+			return true
+		}
+	}
+	return false
+}
+
+// colorDisabled tells whether the color package turned colors off when
+// loaded, e.g. for NO_COLOR or TERM=dumb.
+var colorDisabled = color.NoColor
+
+// setupOutput selects the output format from the --output flag, falling
+// back to the output setting. Colors are disabled for structured formats
+// and when stdout is not a terminal.
+func setupOutput(cmd *cobra.Command, config *settings.Settings) error {
+	value := flags.Output()
+	if value == "" && config != nil {
+		value = config.GetOutput()
+	}
+
+	format, err := output.Parse(value)
+	if err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	cmdutil.FromCommand(cmd).Output = format
+
+	// The color package has a single switch, set on every run so that a run
+	// doesn't inherit the choice of the previous one.
+	color.NoColor = colorDisabled || !format.IsTable() || cli.Terminal(cmd.OutOrStdout()) == nil
+	return nil
+}
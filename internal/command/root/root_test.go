@@ -0,0 +1,104 @@
+package root_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdtest"
+)
+
+func TestOutputFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", want: "a0ctl version"},
+		{name: "flag", args: []string{"-o", "json"}, want: `"version":`},
+		{name: "setting", setting: "yaml", want: "version:"},
+		{name: "flag wins", setting: "yaml", args: []string{"-o", "json"}, want: `"version":`},
+		{name: "invalid", args: []string{"-o", "xml"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := cmdtest.New(t)
+			if tt.setting != "" {
+				e.MustRun("config", "set", "output", tt.setting)
+			}
+
+			res := e.Run(append([]string{"version"}, tt.args...)...)
+			if (res.Err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", res.Err, tt.wantErr)
+			}
+			if !strings.Contains(res.Stdout, tt.want) {
+				t.Errorf("stdout = %q, want %q", res.Stdout, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+	e.Server.HandleJSON("GET /v1/apps/web/scale", http.StatusOK, api.Scale{Count: 1, Size: "small"})
+	e.Server.HandleJSON("PUT /v1/apps/web/scale", http.StatusOK, api.Scale{Count: 2, Size: "small"})
+	e.Server.HandleJSON("GET /v1/sizes", http.StatusOK, map[string][]api.Size{"sizes": {{Name: "small", CPUs: 1, MemoryMB: 512}}})
+
+	// Messages go to the command's stderr, keeping stdout parsable.
+	res := e.MustRun("scale", "count=2", "--app", "web", "-o", "json")
+	if !strings.Contains(res.Stderr, "Scaling web") || strings.Contains(res.Stdout, "Scaling web") {
+		t.Errorf("stdout = %q, stderr = %q", res.Stdout, res.Stderr)
+	}
+
+	// The next run doesn't inherit the format.
+	res = e.MustRun("scale", "count=2", "--app", "web")
+	if !strings.Contains(res.Stdout, "Scaling web") || strings.Contains(res.Stderr, "Scaling web") {
+		t.Errorf("stdout = %q, stderr = %q", res.Stdout, res.Stderr)
+	}
+}
+
+func TestBrokenSettings(t *testing.T) {
+	e := cmdtest.New(t)
+	if err := os.WriteFile(e.SettingsPath(), []byte("{broken"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	res := e.Run("config", "set", "output", "json")
+	if res.Err == nil {
+		t.Fatal("command succeeded with a broken settings file")
+	}
+	// The warning is printed once, although the settings are needed twice.
+	if n := strings.Count(res.Stderr, "could not parse JSON config"); n != 1 {
+		t.Errorf("warning printed %d times:\n%s", n, res.Stderr)
+	}
+
+	res = e.MustRun("config", "set", "output", "json", "--reset-config")
+	if !strings.Contains(res.Stderr, "replaced unparsable settings file") {
+		t.Errorf("stderr = %q", res.Stderr)
+	}
+	if got := e.Setting("output"); got != "json" {
+		t.Errorf("output = %v after reset", got)
+	}
+	backups, _ := filepath.Glob(e.SettingsPath() + ".broken-*.bak")
+	if len(backups) != 1 {
+		t.Errorf("backups = %v", backups)
+	}
+}
+
+func TestMissingSettingsAreCreated(t *testing.T) {
+	e := cmdtest.New(t)
+	if err := os.Remove(e.SettingsPath()); err != nil {
+		t.Fatal(err)
+	}
+
+	e.MustRun("version")
+	if got := e.Setting("version"); got != float64(1) {
+		t.Errorf("version = %v in the new settings file", got)
+	}
+}
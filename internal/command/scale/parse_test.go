@@ -0,0 +1,118 @@
+package scale
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    request
+		wantErr string
+	}{
+		{args: nil, want: request{count: -1}},
+		{args: []string{"count=3"}, want: request{count: 3}},
+		{args: []string{"COUNT=0", "size=small"}, want: request{count: 0, size: "small"}},
+		{args: []string{"memory=1gb", "cpu=2"}, want: request{count: -1, memoryMB: 1024, cpus: 2}},
+		{args: []string{"cpus=0.5"}, want: request{count: -1, cpus: 0.5}},
+		{args: []string{"count"}, wantErr: "expected key=value"},
+		{args: []string{"count="}, wantErr: "expected key=value"},
+		{args: []string{"count=-1"}, wantErr: "must not be negative"},
+		{args: []string{"count=two"}, wantErr: `invalid count value "two"`},
+		{args: []string{"cpu=0"}, wantErr: "must be positive"},
+		{args: []string{"memory=lots"}, wantErr: "expected a size"},
+		{args: []string{"disk=10gb"}, wantErr: `unknown scale option "disk"`},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			got, err := parseArgs(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("parseArgs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMemory(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{in: "256", want: 256},
+		{in: "512mb", want: 512},
+		{in: "512M", want: 512},
+		{in: "1gb", want: 1024},
+		{in: " 2G ", want: 2048},
+		{in: "0.5gb", want: 512},
+		{in: "0", wantErr: true},
+		{in: "-1gb", wantErr: true},
+		{in: "gb", wantErr: true},
+		{in: "1tb", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseMemory(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseMemory(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	sizes := []api.Size{
+		{Name: "small", CPUs: 1, MemoryMB: 512},
+		{Name: "medium", CPUs: 1, MemoryMB: 1024},
+		{Name: "large", CPUs: 2, MemoryMB: 1024},
+	}
+	current := api.Scale{Count: 2, Size: "small"}
+
+	tests := []struct {
+		name    string
+		req     request
+		want    api.Scale
+		wantErr string
+	}{
+		{name: "unchanged", req: request{count: -1}, want: current},
+		{name: "count", req: request{count: 5}, want: api.Scale{Count: 5, Size: "small"}},
+		{name: "size", req: request{count: -1, size: "large"}, want: api.Scale{Count: 2, Size: "large"}},
+		{name: "memory keeps cpu", req: request{count: -1, memoryMB: 1024}, want: api.Scale{Count: 2, Size: "medium"}},
+		{name: "memory and cpu", req: request{count: 3, memoryMB: 1024, cpus: 2}, want: api.Scale{Count: 3, Size: "large"}},
+		{name: "unknown size", req: request{count: -1, size: "huge"}, wantErr: "available sizes are"},
+		{name: "size and memory", req: request{count: -1, size: "small", memoryMB: 512}, wantErr: "cannot be combined"},
+		{name: "no matching size", req: request{count: -1, cpus: 4}, wantErr: "no size with 512mb memory and 4 CPU(s)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolve(tt.req, current, sizes)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("resolve() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
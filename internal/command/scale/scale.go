@@ -0,0 +1,276 @@
+// Package scale provides the command to change the number and size of app instances.
+package scale
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+const waitPollInterval = 5 * time.Second
+
+var (
+	wait        bool
+	waitTimeout time.Duration
+)
+
+func New() *cobra.Command {
+	const (
+		use   = "scale [count=N] [memory=SIZE] [cpu=N] [size=NAME]"
+		short = "Change the number and size of app instances"
+		long  = "Change the number and size of the instances an app runs with.\n\n" +
+			"Memory and CPU must match one of the size presets offered by the platform. " +
+			"Without arguments, shows the current scale."
+		example = "  a0ctl scale --app web count=3\n" +
+			"  a0ctl scale --app web count=3 memory=512mb cpu=1 --wait"
+	)
+
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Long:              long,
+		Example:           example,
+		ValidArgsFunction: completeArgs,
+		RunE:              scale,
+	}
+
+	flags.AddApp(cmd)
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait until the target number of instances is healthy")
+	cmd.Flags().DurationVar(&waitTimeout, "wait-timeout", 5*time.Minute, "How long to wait with --wait")
+
+	return cmd
+}
+
+func completeArgs(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return []string{"count=", "memory=", "cpu=", "size="}, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
+
+// request is the scale change parsed from the command arguments.
+type request struct {
+	count    int
+	memoryMB int
+	cpus     float64
+	size     string
+}
+
+func scale(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	req, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	current, err := client.Scale.Get(app)
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	if len(args) == 0 {
+		return cmdutil.Output(cmd).Print(w, current, func(w io.Writer) error {
+			_, err := fmt.Fprintf(w, "%s runs %d instance(s) of size %s\n", app, current.Count, cli.Emph(current.Size))
+			return err
+		})
+	}
+
+	sizes, err := client.Scale.Sizes()
+	if err != nil {
+		return err
+	}
+
+	target, err := resolve(req, current, sizes)
+	if err != nil {
+		return err
+	}
+
+	updated, err := client.Scale.Update(app, target)
+	if err != nil {
+		return err
+	}
+
+	messages := cmdutil.Messages(cmd)
+	fmt.Fprintf(messages, "Scaling %s to %d instance(s) of size %s\n", app, updated.Count, cli.Emph(updated.Size))
+	if wait {
+		if err := waitHealthy(cmd, messages, client, app, updated.Count); err != nil {
+			return err
+		}
+	}
+
+	return cmdutil.Output(cmd).Print(w, updated, func(w io.Writer) error {
+		if !wait {
+			return nil
+		}
+		_, err := fmt.Fprintf(w, "✔  Success! %d/%d instance(s) healthy\n", updated.Count, updated.Count)
+		return err
+	})
+}
+
+func parseArgs(args []string) (request, error) {
+	req := request{count: -1}
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || value == "" {
+			return req, fmt.Errorf("invalid argument %q: expected key=value", arg)
+		}
+
+		var err error
+		switch strings.ToLower(key) {
+		case "count":
+			req.count, err = strconv.Atoi(value)
+			if err == nil && req.count < 0 {
+				err = fmt.Errorf("must not be negative")
+			}
+		case "memory":
+			req.memoryMB, err = parseMemory(value)
+		case "cpu", "cpus":
+			req.cpus, err = strconv.ParseFloat(value, 64)
+			if err == nil && req.cpus <= 0 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "size":
+			req.size = value
+		default:
+			return req, fmt.Errorf("unknown scale option %q, expected count, memory, cpu or size", key)
+		}
+		if err != nil {
+			return req, fmt.Errorf("invalid %s value %q: %w", key, value, err)
+		}
+	}
+	return req, nil
+}
+
+// parseMemory parses sizes like 512mb, 1gb or 256 (megabytes).
+func parseMemory(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	multiplier := 1
+	for _, unit := range []struct {
+		suffix     string
+		multiplier int
+	}{{"gb", 1024}, {"g", 1024}, {"mb", 1}, {"m", 1}} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSuffix(s, unit.suffix)
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("expected a size like 512mb or 1gb")
+	}
+	return int(n * float64(multiplier)), nil
+}
+
+// resolve validates the requested change against the size presets and
+// returns the scale to submit.
+func resolve(req request, current api.Scale, sizes []api.Size) (api.Scale, error) {
+	target := current
+	if req.count >= 0 {
+		target.Count = req.count
+	}
+
+	if req.size == "" && req.memoryMB == 0 && req.cpus == 0 {
+		return target, nil
+	}
+
+	if req.size != "" {
+		if req.memoryMB != 0 || req.cpus != 0 {
+			return target, fmt.Errorf("size cannot be combined with memory or cpu")
+		}
+		if !slices.ContainsFunc(sizes, func(s api.Size) bool { return s.Name == req.size }) {
+			return target, unknownSizeError(sizes)
+		}
+		target.Size = req.size
+		return target, nil
+	}
+
+	// Fill in whichever of memory and cpu wasn't given from the current size.
+	idx := slices.IndexFunc(sizes, func(s api.Size) bool { return s.Name == current.Size })
+	if idx >= 0 {
+		if req.memoryMB == 0 {
+			req.memoryMB = sizes[idx].MemoryMB
+		}
+		if req.cpus == 0 {
+			req.cpus = sizes[idx].CPUs
+		}
+	}
+
+	idx = slices.IndexFunc(sizes, func(s api.Size) bool {
+		return s.MemoryMB == req.memoryMB && s.CPUs == req.cpus
+	})
+	if idx < 0 {
+		return target, fmt.Errorf("no size with %s memory and %g CPU(s): %w", formatMemory(req.memoryMB), req.cpus, unknownSizeError(sizes))
+	}
+	target.Size = sizes[idx].Name
+	return target, nil
+}
+
+func unknownSizeError(sizes []api.Size) error {
+	var b strings.Builder
+	b.WriteString("available sizes are:")
+	for _, s := range sizes {
+		fmt.Fprintf(&b, "\n  %s (cpu=%g memory=%s)", s.Name, s.CPUs, formatMemory(s.MemoryMB))
+	}
+	return fmt.Errorf("%s", b.String())
+}
+
+func formatMemory(mb int) string {
+	if mb >= 1024 && mb%1024 == 0 {
+		return fmt.Sprintf("%dgb", mb/1024)
+	}
+	return fmt.Sprintf("%dmb", mb)
+}
+
+// waitHealthy waits until count instances of app are healthy, the timeout
+// or an interrupt.
+func waitHealthy(cmd *cobra.Command, w io.Writer, client *api.Client, app string, count int) error {
+	spinner := cli.NewSpinner(w, fmt.Sprintf("Waiting for %d instance(s) to be healthy", count))
+	spinner.Start()
+	defer spinner.Stop("")
+
+	deadline := time.Now().Add(waitTimeout)
+	for {
+		instances, err := client.Instances.List(app)
+		if err != nil {
+			return err
+		}
+
+		healthy := 0
+		for _, i := range instances {
+			if i.IsHealthy() {
+				healthy++
+			}
+		}
+		if healthy == count && len(instances) == count {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s with %d/%d instance(s) healthy", waitTimeout, healthy, count)
+		}
+		spinner.Update(fmt.Sprintf("%d/%d instance(s) healthy, %d running in total", healthy, count, len(instances)))
+		select {
+		case <-cmd.Context().Done():
+			return cmd.Context().Err()
+		case <-time.After(min(waitPollInterval, time.Until(deadline))):
+		}
+	}
+}
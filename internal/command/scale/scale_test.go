@@ -0,0 +1,32 @@
+package scale_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdtest"
+)
+
+func TestWaitTimeout(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+	e.Server.HandleJSON("GET /v1/apps/web/scale", http.StatusOK, api.Scale{Count: 1, Size: "small"})
+	e.Server.HandleJSON("PUT /v1/apps/web/scale", http.StatusOK, api.Scale{Count: 2, Size: "small"})
+	e.Server.HandleJSON("GET /v1/sizes", http.StatusOK, map[string][]api.Size{"sizes": {{Name: "small", CPUs: 1, MemoryMB: 512}}})
+	e.Server.HandleJSON("GET /v1/apps/web/instances", http.StatusOK, map[string][]api.Instance{
+		"instances": {{ID: "i-1", State: api.InstanceStateRunning, Health: api.InstanceHealthHealthy}},
+	})
+
+	start := time.Now()
+	res := e.Run("scale", "count=2", "--app", "web", "--wait", "--wait-timeout", "100ms")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "timed out after 100ms with 1/2 instance(s) healthy") {
+		t.Fatalf("err = %v", res.Err)
+	}
+	// The wait stops at the timeout rather than the next poll.
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("returned after %s", elapsed)
+	}
+}
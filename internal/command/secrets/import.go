@@ -0,0 +1,58 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+func newImport() *cobra.Command {
+	const (
+		use   = "import <.env file>"
+		short = "Import secrets from a dotenv file"
+		long  = "Import secrets from a dotenv file. Use - to read the file from stdin."
+	)
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long:  long,
+		Args:  cobra.ExactArgs(1),
+		RunE:  importSecrets,
+	}
+	return cmd
+}
+
+func importSecrets(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	r := cmd.InOrStdin()
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("could not open dotenv file: %w", err)
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				return
+			}
+		}()
+		r = f
+	}
+
+	secrets, err := api.ParseDotenv(r)
+	if err != nil {
+		return err
+	}
+	if len(secrets) == 0 {
+		return fmt.Errorf("no secrets found in %s", args[0])
+	}
+
+	return update(cmd, app, api.EnvChange{Set: secrets, Stage: flags.Stage()})
+}
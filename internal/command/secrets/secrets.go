@@ -0,0 +1,72 @@
+// Package secrets provides commands to manage app secrets.
+package secrets
+
+import (
+	"io"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/command/env"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+func New() *cobra.Command {
+	const (
+		short = "Manage app secrets"
+		long  = "Manage the secrets of an app.\n\nSecrets are exposed to the app as " +
+			"environment variables. Their values are never shown, only a digest."
+	)
+
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: short,
+		Long:  long,
+	}
+
+	flags.AddApp(cmd)
+
+	setCmd := newSet()
+	unsetCmd := newUnset()
+	importCmd := newImport()
+	flags.AddStage(setCmd)
+	flags.AddStage(unsetCmd)
+	flags.AddStage(importCmd)
+
+	cmd.AddCommand(newList(), setCmd, unsetCmd, importCmd, env.NewDeploy())
+
+	return cmd
+}
+
+func update(cmd *cobra.Command, app string, change api.EnvChange) error {
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	result, err := client.Secrets.Update(app, change)
+	if err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), result, func(w io.Writer) error {
+		if err := printSecrets(w, result.Secrets); err != nil {
+			return err
+		}
+		return env.WriteChangeResult(w, result.EnvChangeResult)
+	})
+}
+
+func printSecrets(w io.Writer, secrets []api.Secret) error {
+	table := cli.NewTable(w, "NAME", "DIGEST", "CREATED AT", "STAGED")
+	for _, s := range secrets {
+		staged := ""
+		if s.Staged {
+			staged = "yes"
+		}
+		table.Row(s.Name, s.Digest, s.CreatedAt.Local().Format(time.DateTime), staged)
+	}
+	return table.Flush()
+}
@@ -0,0 +1,75 @@
+package secrets_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdtest"
+)
+
+func TestSet(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+	e.Server.HandleJSON("PATCH /v1/apps/web/secrets", http.StatusOK, api.SecretsChangeResult{
+		EnvChangeResult: api.EnvChangeResult{ReleaseID: "rel_1"},
+		Secrets:         []api.Secret{{Name: "DB_PASSWORD", Digest: "sha256:abc"}},
+	})
+
+	res := e.MustRun("secrets", "set", "--app", "web", "DB_PASSWORD=hunter2")
+	for _, want := range []string{"DB_PASSWORD", "sha256:abc", "rel_1"} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("stdout doesn't contain %q:\n%s", want, res.Stdout)
+		}
+	}
+	if strings.Contains(res.Stdout, "hunter2") {
+		t.Error("secret value printed")
+	}
+
+	reqs := e.Server.Requests()
+	var change api.EnvChange
+	if err := json.Unmarshal(reqs[len(reqs)-1].Body, &change); err != nil {
+		t.Fatal(err)
+	}
+	if change.Set["DB_PASSWORD"] != "hunter2" || change.Stage {
+		t.Errorf("change = %+v", change)
+	}
+}
+
+func TestList(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+	e.Server.HandleJSON("GET /v1/apps/web/secrets", http.StatusOK, map[string][]api.Secret{
+		"secrets": {{Name: "API_KEY", Digest: "sha256:def", Staged: true}},
+	})
+
+	res := e.MustRun("secrets", "list", "--app", "web", "-o", "json")
+	var secrets []api.Secret
+	if err := json.Unmarshal([]byte(res.Stdout), &secrets); err != nil {
+		t.Fatalf("%v\n%s", err, res.Stdout)
+	}
+	if len(secrets) != 1 || secrets[0].Name != "API_KEY" || !secrets[0].Staged {
+		t.Errorf("secrets = %+v", secrets)
+	}
+}
+
+func TestImportStdin(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+	e.Server.HandleJSON("PATCH /v1/apps/web/secrets", http.StatusOK, api.SecretsChangeResult{})
+
+	res := e.RunInput("DB_PASSWORD=hunter2\nAPI_KEY=\"abc def\"\n", "secrets", "import", "--app", "web", "-")
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	reqs := e.Server.Requests()
+	var change api.EnvChange
+	if err := json.Unmarshal(reqs[len(reqs)-1].Body, &change); err != nil {
+		t.Fatal(err)
+	}
+	if change.Set["DB_PASSWORD"] != "hunter2" || change.Set["API_KEY"] != "abc def" {
+		t.Errorf("change = %+v", change)
+	}
+}
@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+func newSet() *cobra.Command {
+	const (
+		use   = "set KEY=VALUE..."
+		short = "Set secrets on an app"
+		long  = "Set secrets on an app and redeploy it.\n\n" +
+			"To keep values out of your shell history, use - to read a value " +
+			"from stdin or @path to read it from a file, e.g.\n\n" +
+			"  a0ctl secrets set --app web DATABASE_URL=- < db-url.txt\n" +
+			"  a0ctl secrets set --app web TLS_KEY=@server.key"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Long:              long,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              set,
+	}
+	return cmd
+}
+
+func set(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	secrets, err := cli.ParseKeyValues(args, cmd.InOrStdin())
+	if err != nil {
+		return err
+	}
+	for name := range secrets {
+		if err := api.ValidateEnvName(name); err != nil {
+			return err
+		}
+	}
+
+	return update(cmd, app, api.EnvChange{Set: secrets, Stage: flags.Stage()})
+}
@@ -0,0 +1,42 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/a0dotrun/a0ctl/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+func newUnset() *cobra.Command {
+	const (
+		use   = "unset KEY..."
+		short = "Remove secrets from an app"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              unset,
+	}
+	return cmd
+}
+
+func unset(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	question := fmt.Sprintf("Remove %s from %s? Secret values cannot be recovered.", strings.Join(args, ", "), app)
+	if err := prompt.ConfirmAction(cmd, question); err != nil {
+		return err
+	}
+
+	return update(cmd, app, api.EnvChange{Unset: args, Stage: flags.Stage()})
+}
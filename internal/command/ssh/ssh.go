@@ -0,0 +1,73 @@
+// Package ssh provides commands to open interactive sessions in app instances.
+package ssh
+
+import (
+	"errors"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/command/exec"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+var shell string
+
+func New() *cobra.Command {
+	const (
+		short = "Open interactive sessions in app instances"
+	)
+
+	cmd := &cobra.Command{
+		Use:   "ssh",
+		Short: short,
+	}
+
+	cmd.AddCommand(newConsole())
+
+	return cmd
+}
+
+func newConsole() *cobra.Command {
+	const (
+		use   = "console"
+		short = "Open an interactive shell in an app instance"
+	)
+
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              console,
+	}
+
+	flags.AddApp(cmd)
+	flags.AddInstance(cmd)
+	cmd.Flags().StringVar(&shell, "shell", "/bin/sh", "Shell to run in the instance")
+
+	return cmd
+}
+
+func console(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	if cli.Terminal(cmd.InOrStdin()) == nil || cli.Terminal(cmd.OutOrStdout()) == nil {
+		return errors.New("an interactive console requires a terminal, use " + cli.Emph("a0ctl exec") + " to run commands non-interactively")
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+	return exec.Run(cmd, client, app, api.ExecRequest{
+		Instance: flags.Instance(),
+		Command:  []string{shell, "-l"},
+		TTY:      true,
+	})
+}
@@ -0,0 +1,25 @@
+package ssh_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdtest"
+)
+
+func TestConsoleWithoutTerminal(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+	e.Server.AddApp(api.App{Name: "web"})
+
+	res := e.Run("ssh", "console", "--app", "web")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "requires a terminal") {
+		t.Fatalf("err = %v", res.Err)
+	}
+	for _, r := range e.Server.Requests() {
+		if strings.HasSuffix(r.Path, "/exec") {
+			t.Error("a session was started without a terminal")
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Package update provides the command to update a0ctl to the latest release.
+package update
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/a0dotrun/a0ctl/internal/buildinfo"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/update"
+	"github.com/spf13/cobra"
+)
+
+var checkOnly bool
+
+// New initializes and returns a new update Command.
+func New() *cobra.Command {
+	const (
+		short = "Update a0ctl to the latest version"
+		long  = "Download the latest release of a0ctl for this platform, verify its " +
+			"checksum and signature, and replace the running executable with it."
+	)
+	cmd := &cobra.Command{
+		Use:               "update",
+		Short:             short,
+		Long:              long,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              run,
+	}
+	cmd.Flags().BoolVar(&checkOnly, "check", false, "Only check whether a new version is available")
+	return cmd
+}
+
+// result is the output of the update command.
+type result struct {
+	Current string `json:"current"`
+	Latest  string `json:"latest"`
+	Updated bool   `json:"updated"`
+}
+
+func run(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	config, err := cmdutil.Settings(cmd)
+	if err != nil {
+		return fmt.Errorf("could not retrieve local config: %w", err)
+	}
+	release, err := update.Latest(cmd.Context(), config.A0HomeURL())
+	if err != nil {
+		return err
+	}
+
+	res := result{Current: buildinfo.Version, Latest: release.Version}
+	if !release.IsNewer() {
+		return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), res, func(w io.Writer) error {
+			_, err := fmt.Fprintf(w, "a0ctl is up to date (%s)\n", res.Current)
+			return err
+		})
+	}
+	if checkOnly {
+		return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), res, func(w io.Writer) error {
+			_, err := fmt.Fprintf(w, "A new version is available: %s → %s\nRun %s to install it.\n",
+				res.Current, cli.Emph(res.Latest), cli.Emph("a0ctl update"))
+			return err
+		})
+	}
+
+	if err := update.Install(cmd.Context(), cmd.ErrOrStderr(), release); err != nil {
+		return err
+	}
+	res.Updated = true
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), res, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "✔  Success! Updated a0ctl from %s to %s\n", res.Current, res.Latest)
+		return err
+	})
+}
@@ -3,7 +3,11 @@ package version
 
 import (
 	"fmt"
+	"io"
+	"runtime"
 
+	"github.com/a0dotrun/a0ctl/internal/buildinfo"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
 	"github.com/spf13/cobra"
 )
 
@@ -18,10 +22,19 @@ func New() *cobra.Command {
 		Use:   "version",
 		Short: short,
 		Long:  long,
-		Run: func(cmd *cobra.Command, args []string) {
-			ctx := cmd.Context()
-			_ = ctx // Access the context here
-			fmt.Printf("a0ctl version %s\n", "0.0.1")
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info := Info{Version: buildinfo.Version, OS: runtime.GOOS, Arch: runtime.GOARCH}
+			return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), info, func(w io.Writer) error {
+				_, err := fmt.Fprintf(w, "a0ctl version %s\n", info.Version)
+				return err
+			})
 		},
 	}
 }
+
+// Info describes the running a0ctl build.
+type Info struct {
+	Version string `json:"version"`
+	OS      string `json:"os"`
+	Arch    string `json:"arch"`
+}
@@ -0,0 +1,76 @@
+package volumes
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/a0dotrun/a0ctl/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	createSize   string
+	createRegion string
+)
+
+func newCreate() *cobra.Command {
+	const (
+		use   = "create [name]"
+		short = "Create a volume"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              create,
+	}
+	cmd.Flags().StringVar(&createSize, "size", "1", "Size of the volume in gigabytes")
+	cmd.Flags().StringVar(&createRegion, "region", "", "Region to create the volume in, the app's primary region if not set")
+	return cmd
+}
+
+func create(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	var name string
+	if len(args) == 1 {
+		name = args[0]
+	} else if name, err = prompt.Text(cmd, "Volume name", ""); err != nil {
+		return err
+	}
+	if err := validateName(name); err != nil {
+		return err
+	}
+	size, err := parseSize(createSize)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	volume, err := client.Volumes.Create(app, api.CreateVolumeRequest{
+		Name:   name,
+		SizeGB: size,
+		Region: createRegion,
+	})
+	if err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), volume, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "✔  Success! Created volume %s (%s, %dGB in %s)\n", cli.Emph(volume.Name), volume.ID, volume.SizeGB, volume.Region)
+		return err
+	})
+}
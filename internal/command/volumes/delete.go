@@ -0,0 +1,59 @@
+package volumes
+
+import (
+	"fmt"
+
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/a0dotrun/a0ctl/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+func newDelete() *cobra.Command {
+	const (
+		use   = "delete <id>"
+		short = "Delete a volume and all of its data"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Aliases:           []string{"rm"},
+		Short:             short,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              deleteVolume,
+	}
+	return cmd
+}
+
+func deleteVolume(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	volume, err := client.Volumes.Get(app, args[0])
+	if err != nil {
+		return err
+	}
+	if volume.AttachedTo != "" {
+		return fmt.Errorf("volume %s is attached to instance %s, stop the instance before deleting it", volume.Name, volume.AttachedTo)
+	}
+
+	question := fmt.Sprintf("Delete volume %s (%s, %dGB) and all of its data? This cannot be undone.", volume.Name, volume.ID, volume.SizeGB)
+	if err := prompt.ConfirmAction(cmd, question); err != nil {
+		return err
+	}
+
+	if err := client.Volumes.Delete(app, volume.ID); err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).PrintMessage(cmd.OutOrStdout(), "Volume %s deleted.", volume.Name)
+}
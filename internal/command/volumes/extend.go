@@ -0,0 +1,71 @@
+package volumes
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+var extendSize string
+
+func newExtend() *cobra.Command {
+	const (
+		use   = "extend <id>"
+		short = "Grow a volume"
+		long  = "Grow a volume to a new size. Volumes cannot be shrunk."
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Long:              long,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              extend,
+	}
+	cmd.Flags().StringVar(&extendSize, "size", "", "New size of the volume in gigabytes")
+	_ = cmd.MarkFlagRequired("size")
+	return cmd
+}
+
+func extend(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	size, err := parseSize(extendSize)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	volume, err := client.Volumes.Get(app, args[0])
+	if err != nil {
+		return err
+	}
+	if size <= volume.SizeGB {
+		return fmt.Errorf("volume %s is already %dGB, the new size must be larger", volume.Name, volume.SizeGB)
+	}
+
+	volume, err = client.Volumes.Extend(app, volume.ID, size)
+	if err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), volume, func(w io.Writer) error {
+		fmt.Fprintf(w, "✔  Success! Volume %s extended to %dGB\n", cli.Emph(volume.Name), volume.SizeGB)
+		if volume.AttachedTo != "" {
+			fmt.Fprintf(w, "Instance %s may need a restart to see the new size.\n", volume.AttachedTo)
+		}
+		return nil
+	})
+}
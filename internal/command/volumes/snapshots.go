@@ -0,0 +1,133 @@
+package volumes
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+var restoreName string
+
+func newSnapshots() *cobra.Command {
+	const (
+		short = "Manage volume snapshots"
+	)
+
+	cmd := &cobra.Command{
+		Use:     "snapshots",
+		Aliases: []string{"snapshot"},
+		Short:   short,
+	}
+
+	cmd.AddCommand(newSnapshotsList(), newSnapshotsRestore())
+
+	return cmd
+}
+
+func newSnapshotsList() *cobra.Command {
+	const (
+		use   = "list <volume-id>"
+		short = "List the snapshots of a volume"
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Aliases:           []string{"ls"},
+		Short:             short,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              listSnapshots,
+	}
+	return cmd
+}
+
+func listSnapshots(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := client.Volumes.Snapshots(app, args[0])
+	if err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), snapshots, func(w io.Writer) error {
+		table := cli.NewTable(w, "ID", "SIZE", "STATE", "CREATED AT")
+		for _, s := range snapshots {
+			table.Row(s.ID, fmt.Sprintf("%dGB", s.SizeGB), s.State, s.CreatedAt.Local().Format(time.DateTime))
+		}
+		return table.Flush()
+	})
+}
+
+func newSnapshotsRestore() *cobra.Command {
+	const (
+		use   = "restore <volume-id> <snapshot-id>"
+		short = "Restore a snapshot into a new volume"
+		long  = "Restore a snapshot into a new volume in the same region as the " +
+			"original. The original volume is left untouched."
+	)
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             short,
+		Long:              long,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: cli.NoFilesArg,
+		RunE:              restoreSnapshot,
+	}
+	cmd.Flags().StringVar(&restoreName, "name", "", "Name of the new volume, the original name if not set")
+	return cmd
+}
+
+func restoreSnapshot(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	app, err := flags.RequireApp()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return err
+	}
+
+	volume, err := client.Volumes.Get(app, args[0])
+	if err != nil {
+		return err
+	}
+
+	name := restoreName
+	if name == "" {
+		name = volume.Name
+	}
+	if err := validateName(name); err != nil {
+		return err
+	}
+
+	restored, err := client.Volumes.Create(app, api.CreateVolumeRequest{
+		Name:       name,
+		SizeGB:     volume.SizeGB,
+		Region:     volume.Region,
+		SnapshotID: args[1],
+	})
+	if err != nil {
+		return err
+	}
+
+	return cmdutil.Output(cmd).Print(cmd.OutOrStdout(), restored, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "✔  Success! Restoring snapshot %s into volume %s (%s)\n", args[1], cli.Emph(restored.Name), restored.ID)
+		return err
+	})
+}
@@ -0,0 +1,69 @@
+// Package volumes provides commands to manage persistent volumes of an app.
+package volumes
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+)
+
+var validName = regexp.MustCompile(`^[a-z0-9_]{1,30}$`)
+
+func New() *cobra.Command {
+	const (
+		short = "Manage persistent volumes"
+		long  = "Manage persistent volumes that keep data across deploys and restarts " +
+			"of an app's instances."
+	)
+
+	cmd := &cobra.Command{
+		Use:     "volumes",
+		Aliases: []string{"volume", "vol"},
+		Short:   short,
+		Long:    long,
+	}
+
+	flags.AddApp(cmd)
+
+	cmd.AddCommand(newList(), newCreate(), newExtend(), newDelete(), newSnapshots())
+
+	return cmd
+}
+
+func validateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid volume name %q: use up to 30 lowercase letters, digits and underscores", name)
+	}
+	return nil
+}
+
+// parseSize parses sizes like 10 or 10gb and validates them.
+func parseSize(s string) (int, error) {
+	s = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "gb")
+	size, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid volume size %q, expected gigabytes like 10 or 10gb", s)
+	}
+	return size, api.ValidateVolumeSize(size)
+}
+
+func printVolumes(w io.Writer, volumes []api.Volume) error {
+	table := cli.NewTable(w, "ID", "NAME", "SIZE", "REGION", "STATE", "ATTACHED TO", "CREATED AT")
+	for _, v := range volumes {
+		attachedTo := v.AttachedTo
+		if attachedTo == "" {
+			attachedTo = "-"
+		}
+		table.Row(v.ID, v.Name, fmt.Sprintf("%dGB", v.SizeGB), v.Region, v.State, attachedTo,
+			v.CreatedAt.Local().Format(time.DateTime))
+	}
+	return table.Flush()
+}
@@ -0,0 +1,34 @@
+package volumes_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdtest"
+)
+
+func TestList(t *testing.T) {
+	e := cmdtest.New(t)
+	e.Login()
+	e.Server.HandleJSON("GET /v1/apps/web/volumes", http.StatusOK, map[string][]api.Volume{
+		"volumes": {{ID: "vol_1", Name: "data", SizeGB: 10, Region: "fra", State: "attached", AttachedTo: "i-1"}},
+	})
+
+	res := e.MustRun("volumes", "list", "--app", "web")
+	for _, want := range []string{"vol_1", "data", "fra", "i-1"} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("stdout doesn't contain %q:\n%s", want, res.Stdout)
+		}
+	}
+}
+
+func TestNotLoggedIn(t *testing.T) {
+	e := cmdtest.New(t)
+
+	res := e.Run("volumes", "list", "--app", "web")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "not logged in") {
+		t.Errorf("err = %v", res.Err)
+	}
+}
@@ -0,0 +1,89 @@
+package completion
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheTTL is how long completions are reused. It is short so that new
+// resources show up quickly, but long enough to cover repeated tab presses.
+const cacheTTL = time.Minute
+
+type cacheEntry struct {
+	FetchedAt time.Time `json:"fetchedAt"`
+	Values    []string  `json:"values"`
+}
+
+// cacheDir returns the directory completion results are cached in.
+func cacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "a0ctl", "completion"), nil
+}
+
+func cachePath(key string) (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:16])+".json"), nil
+}
+
+// readCache returns the cached values for key, if they are still fresh.
+func readCache(key string) ([]string, bool) {
+	path, err := cachePath(key)
+	if err != nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	if time.Since(entry.FetchedAt) > cacheTTL {
+		return nil, false
+	}
+	return entry.Values, true
+}
+
+// writeCache stores values for key. Failures are ignored, the cache only
+// makes completion faster.
+func writeCache(key string, values []string) {
+	path, err := cachePath(key)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	data, err := json.Marshal(cacheEntry{FetchedAt: time.Now(), Values: values})
+	if err != nil {
+		return
+	}
+
+	// Write to a temporary file first so that concurrent completions never
+	// read a partial entry.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	_ = os.Rename(tmp.Name(), path)
+}
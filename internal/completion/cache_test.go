@@ -0,0 +1,115 @@
+package completion
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+)
+
+func TestCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	if _, ok := readCache("apps"); ok {
+		t.Fatal("readCache() of an empty cache hit")
+	}
+	writeCache("apps", []string{"web", "worker"})
+	if got, ok := readCache("apps"); !ok || !slices.Equal(got, []string{"web", "worker"}) {
+		t.Errorf("readCache() = %v, %v", got, ok)
+	}
+	if _, ok := readCache("orgs"); ok {
+		t.Error("readCache() of another key hit")
+	}
+}
+
+func TestCacheExpires(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	write := func(fetchedAt time.Time) {
+		t.Helper()
+		path, err := cachePath("apps")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(cacheEntry{FetchedAt: fetchedAt, Values: []string{"web"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(time.Now().Add(-cacheTTL + 10*time.Second))
+	if _, ok := readCache("apps"); !ok {
+		t.Error("readCache() of a fresh entry missed")
+	}
+	write(time.Now().Add(-cacheTTL - time.Second))
+	if _, ok := readCache("apps"); ok {
+		t.Error("readCache() of an expired entry hit")
+	}
+}
+
+func TestCacheCorrupt(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	writeCache("apps", []string{"web"})
+	path, err := cachePath("apps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"fetchedAt": `), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := readCache("apps"); ok {
+		t.Error("readCache() of a corrupt entry hit")
+	}
+	// The next fetch replaces it.
+	writeCache("apps", []string{"worker"})
+	if got, ok := readCache("apps"); !ok || !slices.Equal(got, []string{"worker"}) {
+		t.Errorf("readCache() = %v, %v", got, ok)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	client := func(baseURL, username, org string) *api.Client {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := api.NewClient(u, "t0k", username)
+		c.Org = org
+		return c
+	}
+	base := cacheKey(client("https://api.a0.run", "ada", "acme"), "apps")
+
+	tests := []struct {
+		name   string
+		client *api.Client
+		res    string
+	}{
+		{"base URL", client("https://api.staging.a0.run", "ada", "acme"), "apps"},
+		{"username", client("https://api.a0.run", "grace", "acme"), "apps"},
+		{"organization", client("https://api.a0.run", "ada", ""), "apps"},
+		{"resource", client("https://api.a0.run", "ada", "acme"), "orgs"},
+		// The separator keeps fields from running into each other.
+		{"boundaries", client("https://api.a0.run", "adaacme", ""), "apps"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cacheKey(tt.client, tt.res) == base {
+				t.Errorf("the key doesn't depend on the %s", tt.name)
+			}
+		})
+	}
+	if got := cacheKey(client("https://api.a0.run", "ada", "acme"), "apps"); got != base {
+		t.Error("the key isn't stable")
+	}
+}
@@ -0,0 +1,187 @@
+// Package completion provides shell completion of resource names fetched
+// from the a0 API. Results are cached for a short time so that completion
+// stays fast when pressing tab repeatedly.
+package completion
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/command/cmdutil"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const directive = cobra.ShellCompDirectiveNoFileComp
+
+// complete returns the values cached under resource, fetching them with
+// fetch on a cache miss. Errors result in no completions, as there is no
+// way to report them to the shell.
+func complete(cmd *cobra.Command, resource string, fetch func(client *api.Client) ([]string, error)) ([]string, cobra.ShellCompDirective) {
+	client, err := cmdutil.Client(cmd)
+	if err != nil {
+		return nil, directive
+	}
+
+	key := cacheKey(client, resource)
+	if values, ok := readCache(key); ok {
+		return values, directive
+	}
+
+	values, err := fetch(client)
+	if err != nil {
+		return nil, directive
+	}
+	writeCache(key, values)
+	return values, directive
+}
+
+// cacheKey returns the cache key of resource. Results depend on who is
+// asking and from which organization.
+func cacheKey(client *api.Client, resource string) string {
+	return strings.Join([]string{client.BaseURL.String(), client.Username, client.Org, resource}, "\x00")
+}
+
+// FirstArg restricts f to the first positional argument, for commands
+// taking a single resource.
+func FirstArg(f cobra.CompletionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, directive
+		}
+		return f(cmd, args, toComplete)
+	}
+}
+
+// Apps completes app names.
+func Apps(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return complete(cmd, "apps", func(client *api.Client) ([]string, error) {
+		apps, err := client.Apps.List()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, len(apps))
+		for i, a := range apps {
+			values[i] = fmt.Sprintf("%s\t%s", a.Name, strings.Join(a.Regions, ", "))
+		}
+		return values, nil
+	})
+}
+
+// Instances completes the instance IDs of the current app.
+func Instances(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	app, err := flags.RequireApp()
+	if err != nil {
+		return nil, directive
+	}
+	return complete(cmd, "instances/"+app, func(client *api.Client) ([]string, error) {
+		instances, err := client.Instances.List(app)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, len(instances))
+		for i, inst := range instances {
+			values[i] = fmt.Sprintf("%s\t%s %s", inst.ID, inst.Region, inst.State)
+		}
+		return values, nil
+	})
+}
+
+// Regions completes the codes of all regions, leaving out the ones
+// already given as arguments.
+func Regions(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	values, d := complete(cmd, "regions", func(client *api.Client) ([]string, error) {
+		regions, err := client.Regions.List()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, len(regions))
+		for i, r := range regions {
+			values[i] = fmt.Sprintf("%s\t%s", r.Code, r.Name)
+		}
+		return values, nil
+	})
+	return without(values, args), d
+}
+
+// AppRegions completes the codes of the regions the current app runs in,
+// leaving out the ones already given as arguments.
+func AppRegions(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	app, err := flags.RequireApp()
+	if err != nil {
+		return nil, directive
+	}
+	values, d := complete(cmd, "app-regions/"+app, func(client *api.Client) ([]string, error) {
+		return client.Regions.AppRegions(app)
+	})
+	return without(values, args), d
+}
+
+// Orgs completes organization slugs.
+func Orgs(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return complete(cmd, "orgs", func(client *api.Client) ([]string, error) {
+		orgs, err := client.Orgs.List()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, len(orgs))
+		for i, o := range orgs {
+			values[i] = fmt.Sprintf("%s\t%s", o.Slug, o.Name)
+		}
+		return values, nil
+	})
+}
+
+// Profiles completes the names of settings profiles. They are local, so
+// they aren't cached.
+func Profiles(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	config, err := cmdutil.Settings(cmd)
+	if err != nil {
+		return nil, directive
+	}
+	return config.Profiles(), directive
+}
+
+// flagFuncs maps the names of flags shared across commands to their
+// completion functions.
+var flagFuncs = map[string]cobra.CompletionFunc{
+	"app":      Apps,
+	"instance": Instances,
+	"org":      Orgs,
+	"region":   Regions,
+	"profile":  Profiles,
+}
+
+// Register adds completion of resource names for the shared flags of cmd
+// and all its subcommands.
+func Register(cmd *cobra.Command) {
+	for name, f := range flagFuncs {
+		for _, fs := range []*pflag.FlagSet{cmd.Flags(), cmd.PersistentFlags()} {
+			if fs.Lookup(name) == nil {
+				continue
+			}
+			if _, ok := cmd.GetFlagCompletionFunc(name); ok {
+				continue
+			}
+			_ = cmd.RegisterFlagCompletionFunc(name, f)
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		Register(sub)
+	}
+}
+
+// without removes the values whose name is in args.
+func without(values, args []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		name, _, _ := strings.Cut(v, "\t")
+		if !slices.Contains(args, name) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,343 @@
+// Package detect finds how to build and run an app without a Dockerfile,
+// from the files of its source: Node apps from package.json, Go apps from
+// go.mod, Python apps from requirements.txt and static sites from
+// index.html, in that order.
+//
+// The plan can be sent to the platform to build the app with buildpacks,
+// or turned into a Dockerfile to build it like any other.
+package detect
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+)
+
+// Runtimes of the plans.
+const (
+	RuntimeNode   = "node"
+	RuntimeGo     = "go"
+	RuntimePython = "python"
+	RuntimeStatic = "static"
+)
+
+// Versions of the runtimes used when the app doesn't ask for one.
+const (
+	DefaultNodeVersion   = "20"
+	DefaultGoVersion     = "1.23"
+	DefaultPythonVersion = "3.12"
+)
+
+// Procfile declares the command starting the app, on a web: line, for any
+// runtime.
+const Procfile = "Procfile"
+
+// ErrNotDetected is returned when no runtime is recognized in a directory.
+var ErrNotDetected = errors.New("could not detect the runtime of the app, expected a package.json, go.mod, requirements.txt or index.html")
+
+// detectors recognize the runtimes, in order of precedence: a Node app may
+// have an index.html too.
+var detectors = []struct {
+	runtime string
+	file    string
+	detect  func(dir string) (api.BuildPlan, error)
+}{
+	{RuntimeNode, "package.json", detectNode},
+	{RuntimeGo, "go.mod", detectGo},
+	{RuntimePython, "requirements.txt", detectPython},
+	{RuntimeStatic, "index.html", detectStatic},
+}
+
+// Detect returns the plan to build and run the app in dir.
+func Detect(dir string) (api.BuildPlan, error) {
+	for _, d := range detectors {
+		if !exists(dir, d.file) {
+			continue
+		}
+		plan, err := d.detect(dir)
+		if err != nil {
+			return api.BuildPlan{}, fmt.Errorf("detected a %s app from %s, but %w", d.runtime, d.file, err)
+		}
+		plan.DetectedFrom = d.file
+		if start, ok, err := procfileStart(dir); err != nil {
+			return api.BuildPlan{}, err
+		} else if ok {
+			if plan.Runtime == RuntimeGo && !runsGoServer(start) {
+				return api.BuildPlan{}, fmt.Errorf("detected a %s app from %s, but the web: command of the %s must run %s, the only file of the image", d.runtime, d.file, Procfile, goServer)
+			}
+			plan.Start = start
+		}
+		if plan.Start == "" {
+			return api.BuildPlan{}, fmt.Errorf("detected a %s app from %s, but not how to start it, add a web: command to a %s", d.runtime, d.file, Procfile)
+		}
+		return plan, nil
+	}
+	return api.BuildPlan{}, ErrNotDetected
+}
+
+// packageJSON is the part of package.json plans are made from.
+type packageJSON struct {
+	Main    string            `json:"main"`
+	Scripts map[string]string `json:"scripts"`
+	Engines struct {
+		Node string `json:"node"`
+	} `json:"engines"`
+}
+
+// nodeEntrypoints are started when package.json doesn't say how.
+var nodeEntrypoints = []string{"server.js", "index.js", "app.js"}
+
+// majorVersion finds the major version in constraints like >=18, ^20.1 or
+// v20.11.0.
+var majorVersion = regexp.MustCompile(`\d+`)
+
+func detectNode(dir string) (api.BuildPlan, error) {
+	var pkg packageJSON
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return api.BuildPlan{}, err
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return api.BuildPlan{}, fmt.Errorf("it is invalid: %w", err)
+	}
+
+	plan := api.BuildPlan{Runtime: RuntimeNode, Version: DefaultNodeVersion, Port: 3000}
+	if v := majorVersion.FindString(pkg.Engines.Node); v != "" {
+		plan.Version = v
+	} else if v := majorVersion.FindString(firstLine(dir, ".nvmrc")); v != "" {
+		plan.Version = v
+	}
+
+	pm := "npm"
+	switch {
+	case exists(dir, "pnpm-lock.yaml"):
+		pm = "pnpm"
+		plan.Install = "corepack enable && pnpm install --frozen-lockfile"
+	case exists(dir, "yarn.lock"):
+		pm = "yarn"
+		plan.Install = "yarn install --frozen-lockfile"
+	case exists(dir, "package-lock.json"):
+		plan.Install = "npm ci"
+	default:
+		plan.Install = "npm install"
+	}
+	if _, ok := pkg.Scripts["build"]; ok {
+		plan.Build = pm + " run build"
+	}
+
+	switch {
+	case pkg.Scripts["start"] != "":
+		plan.Start = pm + " start"
+	case pkg.Main != "" && exists(dir, pkg.Main):
+		plan.Start = "node " + filepath.ToSlash(pkg.Main)
+	default:
+		if i := slices.IndexFunc(nodeEntrypoints, func(name string) bool { return exists(dir, name) }); i >= 0 {
+			plan.Start = "node " + nodeEntrypoints[i]
+		}
+	}
+	return plan, nil
+}
+
+// goServer is the binary Go apps are built to. Their image contains nothing
+// else.
+const goServer = "/app/server"
+
+// runsGoServer tells whether a start command runs the built Go binary,
+// with or without arguments.
+func runsGoServer(start string) bool {
+	return start == goServer || strings.HasPrefix(start, goServer+" ")
+}
+
+// goDirective finds the Go version in go.mod.
+var goDirective = regexp.MustCompile(`(?m)^go\s+(\d+\.\d+)`)
+
+func detectGo(dir string) (api.BuildPlan, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return api.BuildPlan{}, err
+	}
+	plan := api.BuildPlan{Runtime: RuntimeGo, Version: DefaultGoVersion, Install: "go mod download", Start: goServer, Port: 8080}
+	if m := goDirective.FindSubmatch(data); m != nil {
+		plan.Version = string(m[1])
+	}
+
+	pkg, err := mainPackage(dir)
+	if err != nil {
+		return api.BuildPlan{}, err
+	}
+	plan.Build = "go build -o " + goServer + " " + pkg
+	return plan, nil
+}
+
+// mainPackage finds the command to build: the root package or the only
+// one under cmd.
+func mainPackage(dir string) (string, error) {
+	if isMainPackage(dir) {
+		return ".", nil
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "cmd"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	var commands []string
+	for _, e := range entries {
+		if e.IsDir() && isMainPackage(filepath.Join(dir, "cmd", e.Name())) {
+			commands = append(commands, "./cmd/"+e.Name())
+		}
+	}
+	switch len(commands) {
+	case 0:
+		return "", errors.New("it has no main package at its root or under cmd")
+	case 1:
+		return commands[0], nil
+	default:
+		return "", fmt.Errorf("it has several commands (%s), write a Dockerfile building the one to run", strings.Join(commands, ", "))
+	}
+}
+
+func isMainPackage(dir string) bool {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, f := range files {
+		if strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+		if firstLineWith(f, "package ") == "package main" {
+			return true
+		}
+	}
+	return false
+}
+
+// pythonVersion finds the major and minor version in .python-version or
+// runtime.txt, like 3.11.4 or python-3.11.4.
+var pythonVersion = regexp.MustCompile(`\d+\.\d+`)
+
+// gunicornRequirement tells apps served by gunicorn, a production server.
+var gunicornRequirement = regexp.MustCompile(`(?mi)^gunicorn\b`)
+
+func detectPython(dir string) (api.BuildPlan, error) {
+	requirements, err := os.ReadFile(filepath.Join(dir, "requirements.txt"))
+	if err != nil {
+		return api.BuildPlan{}, err
+	}
+	plan := api.BuildPlan{
+		Runtime: RuntimePython,
+		Version: DefaultPythonVersion,
+		Install: "pip install --no-cache-dir -r requirements.txt",
+		Port:    8000,
+	}
+	if v := pythonVersion.FindString(firstLine(dir, ".python-version")); v != "" {
+		plan.Version = v
+	} else if v := pythonVersion.FindString(firstLine(dir, "runtime.txt")); v != "" {
+		plan.Version = v
+	}
+
+	gunicorn := gunicornRequirement.Match(requirements)
+	for _, name := range []string{"app.py", "main.py", "wsgi.py"} {
+		if !exists(dir, name) {
+			continue
+		}
+		if gunicorn {
+			plan.Start = fmt.Sprintf("gunicorn --bind 0.0.0.0:%d %s:app", plan.Port, strings.TrimSuffix(name, ".py"))
+		} else {
+			plan.Start = "python " + name
+		}
+		break
+	}
+	return plan, nil
+}
+
+func detectStatic(string) (api.BuildPlan, error) {
+	return api.BuildPlan{Runtime: RuntimeStatic, Version: "stable", Start: "nginx", Port: 80}, nil
+}
+
+// procfileStart returns the web command of the Procfile of dir, if any.
+func procfileStart(dir string) (string, bool, error) {
+	f, err := os.Open(filepath.Join(dir, Procfile))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			return
+		}
+	}()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if cmd, ok := strings.CutPrefix(scanner.Text(), "web:"); ok && strings.TrimSpace(cmd) != "" {
+			return strings.TrimSpace(cmd), true, nil
+		}
+	}
+	return "", false, scanner.Err()
+}
+
+func exists(dir, name string) bool {
+	info, err := os.Stat(filepath.Join(dir, name))
+	return err == nil && !info.IsDir()
+}
+
+// firstLine returns the first line of a file of dir, or "" if it can't be
+// read.
+func firstLine(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	return strings.TrimSpace(line)
+}
+
+// firstLineWith returns the first line of the file at path starting with
+// prefix, trimmed, or "".
+func firstLineWith(path, prefix string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			return
+		}
+	}()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); strings.HasPrefix(line, prefix) {
+			return line
+		}
+	}
+	return ""
+}
+
+// WritePlan writes the steps of plan as aligned lines, leaving out those
+// the runtime doesn't have.
+func WritePlan(w io.Writer, plan api.BuildPlan) error {
+	t := cli.NewTable(w)
+	t.Row("Runtime", fmt.Sprintf("%s %s (from %s)", plan.Runtime, plan.Version, plan.DetectedFrom))
+	for _, step := range []struct{ name, command string }{
+		{"Install", plan.Install},
+		{"Build", plan.Build},
+		{"Start", plan.Start},
+	} {
+		if step.command != "" {
+			t.Row(step.name, step.command)
+		}
+	}
+	t.Row("Port", strconv.Itoa(plan.Port))
+	return t.Flush()
+}
@@ -0,0 +1,155 @@
+package detect
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  api.BuildPlan
+	}{
+		{
+			name:  "node entrypoint",
+			files: map[string]string{"package.json": `{"name": "app"}`, "server.js": ""},
+			want:  api.BuildPlan{Runtime: RuntimeNode, Version: DefaultNodeVersion, DetectedFrom: "package.json", Install: "npm install", Start: "node server.js", Port: 3000},
+		},
+		{
+			name: "node scripts",
+			files: map[string]string{
+				"package.json": `{"engines": {"node": ">=18.2"}, "scripts": {"build": "tsc", "start": "node dist/main.js"}}`,
+				"yarn.lock":    "",
+				"index.html":   "",
+			},
+			want: api.BuildPlan{Runtime: RuntimeNode, Version: "18", DetectedFrom: "package.json", Install: "yarn install --frozen-lockfile", Build: "yarn run build", Start: "yarn start", Port: 3000},
+		},
+		{
+			name:  "node nvmrc",
+			files: map[string]string{"package.json": `{"main": "src/app.js"}`, "src/app.js": "", "package-lock.json": "", ".nvmrc": "v22.1.0\n"},
+			want:  api.BuildPlan{Runtime: RuntimeNode, Version: "22", DetectedFrom: "package.json", Install: "npm ci", Start: "node src/app.js", Port: 3000},
+		},
+		{
+			name:  "go",
+			files: map[string]string{"go.mod": "module example.com/app\n\ngo 1.22.3\n", "main.go": "// Command app.\npackage main\n", "main_test.go": "package main_test\n"},
+			want:  api.BuildPlan{Runtime: RuntimeGo, Version: "1.22", DetectedFrom: "go.mod", Install: "go mod download", Build: "go build -o /app/server .", Start: "/app/server", Port: 8080},
+		},
+		{
+			name:  "go cmd",
+			files: map[string]string{"go.mod": "module example.com/app\n", "cmd/api/main.go": "package main\n", "lib.go": "package app\n"},
+			want:  api.BuildPlan{Runtime: RuntimeGo, Version: DefaultGoVersion, DetectedFrom: "go.mod", Install: "go mod download", Build: "go build -o /app/server ./cmd/api", Start: "/app/server", Port: 8080},
+		},
+		{
+			name:  "go procfile",
+			files: map[string]string{"go.mod": "module example.com/app\n", "main.go": "package main\n", "Procfile": "web: /app/server --port $PORT\n"},
+			want:  api.BuildPlan{Runtime: RuntimeGo, Version: DefaultGoVersion, DetectedFrom: "go.mod", Install: "go mod download", Build: "go build -o /app/server .", Start: "/app/server --port $PORT", Port: 8080},
+		},
+		{
+			name:  "python gunicorn",
+			files: map[string]string{"requirements.txt": "flask==3.0\nGunicorn>=21\n", "app.py": "", "runtime.txt": "python-3.11.4\n"},
+			want:  api.BuildPlan{Runtime: RuntimePython, Version: "3.11", DetectedFrom: "requirements.txt", Install: "pip install --no-cache-dir -r requirements.txt", Start: "gunicorn --bind 0.0.0.0:8000 app:app", Port: 8000},
+		},
+		{
+			name:  "python procfile",
+			files: map[string]string{"requirements.txt": "fastapi\n", "Procfile": "release: ./migrate\nweb: uvicorn api:app --host 0.0.0.0 --port $PORT\n"},
+			want:  api.BuildPlan{Runtime: RuntimePython, Version: DefaultPythonVersion, DetectedFrom: "requirements.txt", Install: "pip install --no-cache-dir -r requirements.txt", Start: "uvicorn api:app --host 0.0.0.0 --port $PORT", Port: 8000},
+		},
+		{
+			name:  "static",
+			files: map[string]string{"index.html": "<h1>hi</h1>"},
+			want:  api.BuildPlan{Runtime: RuntimeStatic, Version: "stable", DetectedFrom: "index.html", Start: "nginx", Port: 80},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Detect(writeFiles(t, tt.files))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Detect() = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectErrors(t *testing.T) {
+	if _, err := Detect(writeFiles(t, map[string]string{"README.md": ""})); !errors.Is(err, ErrNotDetected) {
+		t.Errorf("empty app error = %v, want ErrNotDetected", err)
+	}
+
+	tests := []struct {
+		files map[string]string
+		err   string
+	}{
+		{map[string]string{"package.json": "{"}, "detected a node app from package.json, but it is invalid"},
+		{map[string]string{"package.json": "{}"}, "but not how to start it"},
+		{map[string]string{"go.mod": "module x\n", "cmd/a/main.go": "package main\n", "cmd/b/main.go": "package main\n"}, "several commands (./cmd/a, ./cmd/b)"},
+		{map[string]string{"requirements.txt": ""}, "add a web: command to a Procfile"},
+		{map[string]string{"go.mod": "module x\n", "main.go": "package main\n", "Procfile": "web: ./bin/api --port $PORT\n"}, "the web: command of the Procfile must run /app/server"},
+		{map[string]string{"go.mod": "module x\n", "main.go": "package main\n", "Procfile": "web: /app/serverless\n"}, "must run /app/server"},
+	}
+	for _, tt := range tests {
+		if _, err := Detect(writeFiles(t, tt.files)); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Detect(%v) error = %v, want %q", tt.files, err, tt.err)
+		}
+	}
+}
+
+func TestDockerfile(t *testing.T) {
+	// Like examples/app/Dockerfile.
+	plan := api.BuildPlan{Runtime: RuntimeNode, Version: "18", Install: "npm install", Start: "node server.js", Port: 3000}
+	want := `FROM node:18-alpine
+
+WORKDIR /app
+
+COPY . .
+RUN npm install
+
+ENV NODE_ENV=production PORT=3000
+EXPOSE 3000
+
+CMD ["node", "server.js"]
+`
+	got, err := Dockerfile(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("Dockerfile() =\n%s\nwant\n%s", got, want)
+	}
+
+	plan = api.BuildPlan{Runtime: RuntimePython, Version: "3.12", Install: "pip install -r requirements.txt", Start: "uvicorn api:app --port $PORT", Port: 8000}
+	got, err = Dockerfile(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), "\nCMD uvicorn api:app --port $PORT\n") {
+		t.Errorf("commands using the shell must use the shell form, got\n%s", got)
+	}
+
+	if _, err := Dockerfile(api.BuildPlan{Runtime: "cobol"}); err == nil {
+		t.Error("Dockerfile() for an unknown runtime succeeded")
+	}
+}
@@ -0,0 +1,117 @@
+package detect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+)
+
+// dockerfiles build each runtime, in the style of examples/app/Dockerfile.
+var dockerfiles = map[string]*template.Template{
+	RuntimeNode: parse(`FROM node:{{.Version}}-alpine
+
+WORKDIR /app
+
+COPY . .
+RUN {{.Install}}
+{{- if .Build}}
+RUN {{.Build}}
+{{- end}}
+
+ENV NODE_ENV=production PORT={{.Port}}
+EXPOSE {{.Port}}
+
+CMD {{cmd .Start}}
+`),
+	RuntimeGo: parse(`FROM golang:{{.Version}}-alpine AS build
+
+WORKDIR /src
+
+COPY go.mod go.sum* ./
+RUN {{.Install}}
+
+COPY . .
+RUN CGO_ENABLED=0 {{.Build}}
+
+FROM alpine:3.20
+
+COPY --from=build /app/server /app/server
+
+ENV PORT={{.Port}}
+EXPOSE {{.Port}}
+
+CMD {{cmd .Start}}
+`),
+	RuntimePython: parse(`FROM python:{{.Version}}-slim
+
+WORKDIR /app
+
+COPY requirements.txt .
+RUN {{.Install}}
+
+COPY . .
+
+ENV PYTHONUNBUFFERED=1 PORT={{.Port}}
+EXPOSE {{.Port}}
+
+CMD {{cmd .Start}}
+`),
+	RuntimeStatic: parse(`FROM nginx:{{.Version}}-alpine
+
+COPY . /usr/share/nginx/html
+
+EXPOSE {{.Port}}
+`),
+}
+
+// ignored are the files of each runtime left out of the build context.
+var ignored = map[string][]string{
+	RuntimeNode:   {"node_modules", "npm-debug.log"},
+	RuntimeGo:     {"bin"},
+	RuntimePython: {"__pycache__", "*.pyc", ".venv", "venv"},
+	RuntimeStatic: {},
+}
+
+func parse(text string) *template.Template {
+	return template.Must(template.New("Dockerfile").Funcs(template.FuncMap{"cmd": execForm}).Parse(text))
+}
+
+// execForm returns a command in the JSON form of CMD, run without a shell,
+// unless it needs one.
+func execForm(command string) (string, error) {
+	if strings.ContainsAny(command, "$&|;<>*'\"`") {
+		return command, nil
+	}
+	args := strings.Fields(command)
+	for i, arg := range args {
+		data, err := json.Marshal(arg)
+		if err != nil {
+			return "", err
+		}
+		args[i] = string(data)
+	}
+	return "[" + strings.Join(args, ", ") + "]", nil
+}
+
+// Dockerfile returns a Dockerfile building and running the app of plan.
+func Dockerfile(plan api.BuildPlan) ([]byte, error) {
+	tmpl, ok := dockerfiles[plan.Runtime]
+	if !ok {
+		return nil, fmt.Errorf("no Dockerfile for runtime %q", plan.Runtime)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, plan); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DockerIgnore returns the content of a .dockerignore for the app of plan.
+func DockerIgnore(plan api.BuildPlan) []byte {
+	lines := append([]string{".git", ".env", "Dockerfile", ".dockerignore"}, ignored[plan.Runtime]...)
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
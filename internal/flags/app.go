@@ -0,0 +1,41 @@
+package flags
+
+import (
+	"errors"
+
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+var app string
+
+// ErrAppRequired is returned by commands that need an app but none was given.
+var ErrAppRequired = errors.New("no app specified, pass one with " + cli.Emph("--app") + " or add it to " + manifest.FileName)
+
+func AddApp(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&app, "app", "", "Name of the app to operate on, defaults to the app in "+manifest.FileName)
+}
+
+func App() string {
+	return app
+}
+
+// RequireApp returns the app given with --app, falling back to the app in
+// the manifest of the current directory, or ErrAppRequired.
+func RequireApp() (string, error) {
+	if app != "" {
+		return app, nil
+	}
+
+	m, err := manifest.Load()
+	switch {
+	case errors.Is(err, manifest.ErrNotFound):
+		return "", ErrAppRequired
+	case err != nil:
+		return "", err
+	case m.App == "":
+		return "", ErrAppRequired
+	}
+	return m.App, nil
+}
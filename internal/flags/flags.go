@@ -7,8 +7,10 @@ import (
 
 var resetConfig bool
 
+// AddResetConfigFlag adds the hidden --reset-config flag, used to replace
+// a settings file that can't be parsed.
 func AddResetConfigFlag(cmd *cobra.Command) {
-	cmd.PersistentFlags().BoolVar(&resetConfig, "reset-config", false, "")
+	cmd.PersistentFlags().BoolVar(&resetConfig, "reset-config", false, "Replace an unparsable settings file with a fresh one, keeping a backup")
 	err := cmd.PersistentFlags().MarkHidden("reset-config")
 	if err != nil {
 		return
@@ -0,0 +1,24 @@
+package flags
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	yes     bool
+	noInput bool
+)
+
+// AddInput adds the global flags controlling interactive prompts.
+func AddInput(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVarP(&yes, "yes", "y", false, "Answer yes to all confirmations")
+	cmd.PersistentFlags().BoolVar(&noInput, "no-input", false, "Never prompt for input, fail instead. Useful in scripts and CI.")
+}
+
+func Yes() bool {
+	return yes
+}
+
+func NoInput() bool {
+	return noInput
+}
@@ -0,0 +1,15 @@
+package flags
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var instance string
+
+func AddInstance(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&instance, "instance", "", "ID of the instance to connect to, any instance of the app if not set")
+}
+
+func Instance() string {
+	return instance
+}
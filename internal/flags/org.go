@@ -0,0 +1,16 @@
+package flags
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var org string
+
+// AddOrg adds the --org flag scoping all commands to an organization.
+func AddOrg(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&org, "org", "", "Slug of the organization to operate on, overrides the default organization")
+}
+
+func Org() string {
+	return org
+}
@@ -0,0 +1,17 @@
+package flags
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var outputFormat string
+
+// AddOutput adds the global -o/--output flag selecting the output format.
+func AddOutput(cmd *cobra.Command) {
+	usage := "Output format: table, json, yaml, template=<go template> or jsonpath=<expression>. Defaults to the output setting, or table."
+	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "", usage)
+}
+
+func Output() string {
+	return outputFormat
+}
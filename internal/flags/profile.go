@@ -0,0 +1,17 @@
+package flags
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var profile string
+
+// AddProfile adds the --profile flag selecting the settings profile, e.g.
+// to switch between accounts or API environments.
+func AddProfile(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&profile, "profile", "", "Settings profile to use, overrides A0_PROFILE and the default profile")
+}
+
+func Profile() string {
+	return profile
+}
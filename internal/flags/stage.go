@@ -0,0 +1,15 @@
+package flags
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var stage bool
+
+func AddStage(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&stage, "stage", false, "Record the changes without redeploying the app. Staged changes are released on the next deploy.")
+}
+
+func Stage() bool {
+	return stage
+}
@@ -0,0 +1,110 @@
+// Package manifest reads and writes the app manifest, an a0.json file kept
+// in the app's source directory.
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileName is the name of the manifest file.
+const FileName = "a0.json"
+
+// ErrNotFound is returned when no manifest exists in the current directory
+// or any of its parents.
+var ErrNotFound = errors.New("no " + FileName + " found")
+
+// Manifest describes how an app is deployed.
+type Manifest struct {
+	App     string   `json:"app"`
+	Regions []string `json:"regions,omitempty"`
+
+	path string
+}
+
+// Load finds the manifest in the current directory or its parents.
+func Load() (*Manifest, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		path := filepath.Join(dir, FileName)
+		m, err := Read(path)
+		if err == nil {
+			return m, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, ErrNotFound
+		}
+		dir = parent
+	}
+}
+
+// Read reads the manifest at path.
+func Read(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{path: path}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// New returns an empty manifest that will be saved at path.
+func New(path string) *Manifest {
+	return &Manifest{path: path}
+}
+
+// Path returns the location of the manifest file.
+func (m *Manifest) Path() string {
+	return m.path
+}
+
+// Save writes the manifest back to its file. Other keys of the file, e.g.
+// written by users or newer versions of a0ctl, are kept as they are.
+func (m *Manifest) Save() error {
+	values := map[string]json.RawMessage{}
+	data, err := os.ReadFile(m.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(data, &values); err != nil {
+			return fmt.Errorf("could not parse %s: %w", m.path, err)
+		}
+	}
+
+	if values["app"], err = json.Marshal(m.App); err != nil {
+		return err
+	}
+	delete(values, "regions")
+	if len(m.Regions) > 0 {
+		if values["regions"], err = json.Marshal(m.Regions); err != nil {
+			return err
+		}
+	}
+
+	data, err = json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(m.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("could not write %s: %w", m.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package manifest_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/manifest"
+)
+
+func TestSaveKeepsOtherKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), manifest.FileName)
+	data := `{"app": "web", "regions": ["fra"], "build": {"dockerfile": "Dockerfile.prod"}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := manifest.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Regions = nil
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal(got, &values); err != nil {
+		t.Fatal(err)
+	}
+	if values["app"] != "web" || values["build"] == nil {
+		t.Errorf("manifest = %s", got)
+	}
+	if _, ok := values["regions"]; ok {
+		t.Errorf("regions weren't removed: %s", got)
+	}
+}
+
+func TestSaveNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), manifest.FileName)
+	m := manifest.New(path)
+	m.App = "web"
+	m.Regions = []string{"fra", "iad"}
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := manifest.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.App != "web" || len(got.Regions) != 2 {
+		t.Errorf("manifest = %+v", got)
+	}
+}
@@ -0,0 +1,178 @@
+// Package ocitest provides an in-process fake OCI registry, serving the
+// manifests of images pushed to it for tests.
+package ocitest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/oci"
+)
+
+// Registry is a fake OCI registry listening on a local port. It serves
+// manifests and blobs, and issues tokens when it requires credentials.
+type Registry struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	manifests map[string]content // by repository and tag or digest
+	blobs     map[string][]byte  // by digest
+	auth      *oci.Auth
+}
+
+// content is a stored manifest.
+type content struct {
+	data      []byte
+	mediaType string
+}
+
+// tokenPath serves tokens, like the realm of a real registry.
+const tokenPath = "/token"
+
+// token is the token issued to clients with valid credentials.
+const token = "ocitest-token"
+
+// New starts a Registry, stopped at the end of the test.
+func New(t testing.TB) *Registry {
+	t.Helper()
+	r := &Registry{manifests: map[string]content{}, blobs: map[string][]byte{}}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.serve))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// Host returns the host of the registry, to use in references.
+func (r *Registry) Host() string {
+	return strings.TrimPrefix(r.URL, "http://")
+}
+
+// RequireAuth makes the registry refuse clients without a token, which it
+// only issues with the credentials of username.
+func (r *Registry) RequireAuth(username, password string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.auth = &oci.Auth{Username: username, Password: password}
+}
+
+// Push stores an image in repository under tag, for each platform like
+// linux/amd64, and returns the digest of its manifest. Images for several
+// platforms are stored as an index.
+func (r *Registry) Push(repository, tag string, platforms ...string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var descriptors []map[string]any
+	var digest string
+	for _, p := range platforms {
+		platform, err := oci.ParsePlatform(p)
+		if err != nil {
+			panic(err)
+		}
+		config := r.storeBlob(platform)
+		digest = r.store(repository, oci.MediaTypeOCIManifest, map[string]any{
+			"schemaVersion": 2,
+			"mediaType":     oci.MediaTypeOCIManifest,
+			"config":        map[string]any{"mediaType": "application/vnd.oci.image.config.v1+json", "digest": config},
+			"layers":        []any{},
+		})
+		descriptors = append(descriptors, map[string]any{
+			"mediaType": oci.MediaTypeOCIManifest,
+			"digest":    digest,
+			"platform":  platform,
+		})
+	}
+	if len(platforms) > 1 {
+		digest = r.store(repository, oci.MediaTypeOCIIndex, map[string]any{
+			"schemaVersion": 2,
+			"mediaType":     oci.MediaTypeOCIIndex,
+			"manifests":     descriptors,
+		})
+	}
+	r.manifests[repository+":"+tag] = r.manifests[repository+"@"+digest]
+	return digest
+}
+
+func (r *Registry) storeBlob(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	digest := digestOf(data)
+	r.blobs[digest] = data
+	return digest
+}
+
+func (r *Registry) store(repository, mediaType string, v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	digest := digestOf(data)
+	r.manifests[repository+"@"+digest] = content{data: data, mediaType: mediaType}
+	return digest
+}
+
+func (r *Registry) serve(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.URL.Path == tokenPath {
+		if username, password, _ := req.BasicAuth(); r.auth == nil || (oci.Auth{Username: username, Password: password}) != *r.auth {
+			http.Error(w, `{"errors":[{"code":"UNAUTHORIZED"}]}`, http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"token":%q}`, token)
+		return
+	}
+
+	if r.auth != nil && req.Header.Get("Authorization") != "Bearer "+token {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s%s",service="ocitest"`, r.URL, tokenPath))
+		http.Error(w, `{"errors":[{"code":"UNAUTHORIZED"}]}`, http.StatusUnauthorized)
+		return
+	}
+
+	path, ok := strings.CutPrefix(req.URL.Path, "/v2/")
+	if !ok || req.Method != http.MethodGet {
+		http.NotFound(w, req)
+		return
+	}
+	if i := strings.LastIndex(path, "/manifests/"); i >= 0 {
+		ref := path[i+len("/manifests/"):]
+		sep := ":"
+		if strings.HasPrefix(ref, "sha256:") {
+			sep = "@"
+		}
+		m, ok := r.manifests[path[:i]+sep+ref]
+		if !ok {
+			http.Error(w, `{"errors":[{"code":"MANIFEST_UNKNOWN"}]}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", m.mediaType)
+		w.Header().Set("Docker-Content-Digest", digestOf(m.data))
+		_, _ = w.Write(m.data)
+		return
+	}
+	if i := strings.LastIndex(path, "/blobs/"); i >= 0 {
+		data, ok := r.blobs[path[i+len("/blobs/"):]]
+		if !ok {
+			http.Error(w, `{"errors":[{"code":"BLOB_UNKNOWN"}]}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+		return
+	}
+	http.NotFound(w, req)
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,103 @@
+// Package oci resolves image references against OCI registries, checking
+// that an image exists for a platform and pinning it to its digest.
+package oci
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DockerHub is the registry of references without one, like nginx:1.27.
+const DockerHub = "docker.io"
+
+// dockerHubHost serves the Docker Hub API.
+const dockerHubHost = "registry-1.docker.io"
+
+var (
+	digestPattern     = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+	tagPattern        = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	repositoryPattern = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+)
+
+// Reference identifies an image in a registry, by tag, digest or both.
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference parses a reference like ghcr.io/acme/web:v1,
+// ghcr.io/acme/web@sha256:… or both, following the rules of Docker:
+// without a registry, the image is on Docker Hub.
+func ParseReference(s string) (Reference, error) {
+	var ref Reference
+	name := s
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.Digest = name[:i], name[i+1:]
+		if !digestPattern.MatchString(ref.Digest) {
+			return Reference{}, fmt.Errorf("invalid image reference %q: digest must be sha256: followed by 64 hex digits", s)
+		}
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Tag = name[:i], name[i+1:]
+		if !tagPattern.MatchString(ref.Tag) {
+			return Reference{}, fmt.Errorf("invalid image reference %q: invalid tag %q", s, ref.Tag)
+		}
+	}
+
+	ref.Registry, ref.Repository = DockerHub, name
+	if first, rest, ok := strings.Cut(name, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.Registry, ref.Repository = first, rest
+	}
+	if ref.Registry == DockerHub && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+	if !repositoryPattern.MatchString(ref.Repository) {
+		return Reference{}, fmt.Errorf("invalid image reference %q: invalid repository %q", s, ref.Repository)
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	return ref, nil
+}
+
+// Name returns the registry and repository of the reference.
+func (r Reference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the reference with its tag and digest.
+func (r Reference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// Pinned returns the reference by digest only, which always designates
+// the same image.
+func (r Reference) Pinned(digest string) string {
+	return r.Name() + "@" + digest
+}
+
+// host returns the host serving the API of the registry.
+func (r Reference) host() string {
+	if r.Registry == DockerHub {
+		return dockerHubHost
+	}
+	return r.Registry
+}
+
+// ref returns what designates the manifest in the API.
+func (r Reference) ref() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
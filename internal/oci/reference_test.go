@@ -0,0 +1,47 @@
+package oci
+
+import "testing"
+
+const testDigest = "sha256:3f8a2b1c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8"
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Reference
+	}{
+		{"nginx", Reference{Registry: DockerHub, Repository: "library/nginx", Tag: "latest"}},
+		{"acme/web:1.2", Reference{Registry: DockerHub, Repository: "acme/web", Tag: "1.2"}},
+		{"ghcr.io/acme/web:v1", Reference{Registry: "ghcr.io", Repository: "acme/web", Tag: "v1"}},
+		{"localhost:5000/web", Reference{Registry: "localhost:5000", Repository: "web", Tag: "latest"}},
+		{"ghcr.io/acme/web@" + testDigest, Reference{Registry: "ghcr.io", Repository: "acme/web", Digest: testDigest}},
+		{"ghcr.io/acme/web:v1@" + testDigest, Reference{Registry: "ghcr.io", Repository: "acme/web", Tag: "v1", Digest: testDigest}},
+	}
+	for _, tt := range tests {
+		got, err := ParseReference(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseReference(%q) = %+v, %v, want %+v", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{
+		"",
+		"ghcr.io/Acme/web",
+		"ghcr.io/acme/web:",
+		"ghcr.io/acme/web@sha256:abc",
+		"ghcr.io/acme/web@md5:" + testDigest[7:],
+	} {
+		if ref, err := ParseReference(in); err == nil {
+			t.Errorf("ParseReference(%q) = %+v, want an error", in, ref)
+		}
+	}
+}
+
+func TestReferenceString(t *testing.T) {
+	ref := Reference{Registry: "ghcr.io", Repository: "acme/web", Tag: "v1", Digest: testDigest}
+	if got, want := ref.String(), "ghcr.io/acme/web:v1@"+testDigest; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := ref.Pinned(testDigest), "ghcr.io/acme/web@"+testDigest; got != want {
+		t.Errorf("Pinned() = %q, want %q", got, want)
+	}
+}
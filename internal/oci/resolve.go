@@ -0,0 +1,296 @@
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Media types of the manifests Resolve understands.
+const (
+	MediaTypeOCIIndex       = "application/vnd.oci.image.index.v1+json"
+	MediaTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeDockerList     = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+)
+
+// maxManifestSize bounds the manifests and image configurations read.
+const maxManifestSize = 4 << 20
+
+// ErrNotFound is returned when a registry has no image for a reference.
+var ErrNotFound = errors.New("image not found")
+
+// ErrUnauthorized is returned when a registry refuses the credentials, or
+// requires some.
+var ErrUnauthorized = errors.New("not authorized to pull the image")
+
+// Auth authenticates to a registry. The zero value pulls anonymously.
+type Auth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Platform is an operating system and CPU architecture images are built
+// for, like linux/amd64 or linux/arm/v7.
+type Platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// ParsePlatform parses a platform like linux/amd64.
+func ParsePlatform(s string) (Platform, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return Platform{}, fmt.Errorf("invalid platform %q, expected os/architecture[/variant]", s)
+	}
+	p := Platform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		p.Variant = parts[2]
+	}
+	return p, nil
+}
+
+func (p Platform) String() string {
+	if p.Variant != "" {
+		return p.OS + "/" + p.Architecture + "/" + p.Variant
+	}
+	return p.OS + "/" + p.Architecture
+}
+
+// matches tells whether an image for p runs on want. A missing variant
+// matches any.
+func (p Platform) matches(want Platform) bool {
+	if p.OS != want.OS || p.Architecture != want.Architecture {
+		return false
+	}
+	return p.Variant == "" || want.Variant == "" || p.Variant == want.Variant
+}
+
+// Image is an image found in a registry.
+type Image struct {
+	Reference Reference `json:"reference"`
+	// Digest is the digest of the manifest of the reference, an index
+	// for multi-platform images.
+	Digest    string     `json:"digest"`
+	MediaType string     `json:"mediaType"`
+	Platforms []Platform `json:"platforms"`
+}
+
+// Pinned returns the reference of the image by digest.
+func (i Image) Pinned() string {
+	return i.Reference.Pinned(i.Digest)
+}
+
+// manifest is an image manifest or an index of manifests.
+type manifest struct {
+	MediaType string `json:"mediaType"`
+	Config    struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+	Manifests []struct {
+		Digest   string    `json:"digest"`
+		Platform *Platform `json:"platform"`
+	} `json:"manifests"`
+}
+
+// Resolve finds the manifest of ref in its registry and checks that the
+// image runs on platform. When ref has a digest, the manifest must match
+// it.
+func Resolve(ctx context.Context, ref Reference, platform Platform, auth Auth) (Image, error) {
+	r := &repository{ref: ref, auth: auth}
+
+	data, mediaType, err := r.fetch(ctx, "manifests/"+ref.ref(),
+		MediaTypeOCIIndex, MediaTypeDockerList, MediaTypeOCIManifest, MediaTypeDockerManifest)
+	if err != nil {
+		return Image{}, err
+	}
+	sum := sha256.Sum256(data)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	if ref.Digest != "" && ref.Digest != digest {
+		return Image{}, fmt.Errorf("%s returned a manifest with digest %s for %s", ref.Registry, digest, ref)
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Image{}, fmt.Errorf("invalid manifest for %s: %w", ref, err)
+	}
+	if m.MediaType != "" {
+		mediaType = m.MediaType
+	}
+	image := Image{Reference: ref, Digest: digest, MediaType: mediaType}
+
+	switch mediaType {
+	case MediaTypeOCIIndex, MediaTypeDockerList:
+		for _, d := range m.Manifests {
+			// Attestations have an unknown platform.
+			if d.Platform != nil && d.Platform.OS != "unknown" {
+				image.Platforms = append(image.Platforms, *d.Platform)
+			}
+		}
+	case MediaTypeOCIManifest, MediaTypeDockerManifest:
+		data, _, err := r.fetch(ctx, "blobs/"+m.Config.Digest)
+		if err != nil {
+			return Image{}, fmt.Errorf("could not read the configuration of %s: %w", ref, err)
+		}
+		var config Platform
+		if err := json.Unmarshal(data, &config); err != nil {
+			return Image{}, fmt.Errorf("invalid configuration for %s: %w", ref, err)
+		}
+		image.Platforms = []Platform{config}
+	default:
+		return Image{}, fmt.Errorf("%s is not an image, its manifest is of type %q", ref, mediaType)
+	}
+
+	if !slices.ContainsFunc(image.Platforms, func(p Platform) bool { return p.matches(platform) }) {
+		available := make([]string, len(image.Platforms))
+		for i, p := range image.Platforms {
+			available[i] = p.String()
+		}
+		return Image{}, fmt.Errorf("%s has no image for %s, only for %s", ref, platform, strings.Join(available, ", "))
+	}
+	return image, nil
+}
+
+// repository reads from the repository of a reference, authenticating
+// as the registry asks.
+type repository struct {
+	ref  Reference
+	auth Auth
+	// authorization is the Authorization header, once known.
+	authorization string
+}
+
+// fetch gets a manifest or blob of the repository, with its media type.
+func (r *repository) fetch(ctx context.Context, path string, accept ...string) ([]byte, string, error) {
+	u := url.URL{Scheme: scheme(r.ref.host()), Host: r.ref.host(), Path: "/v2/" + r.ref.Repository + "/" + path}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, t := range accept {
+			req.Header.Add("Accept", t)
+		}
+		if r.authorization != "" {
+			req.Header.Set("Authorization", r.authorization)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, "", fmt.Errorf("could not reach %s: %w", r.ref.Registry, err)
+		}
+		data, err := io.ReadAll(io.LimitReader(res.Body, maxManifestSize))
+		if closeErr := res.Body.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		switch {
+		case res.StatusCode == http.StatusOK:
+			return data, res.Header.Get("Content-Type"), nil
+		case res.StatusCode == http.StatusUnauthorized && attempt == 0:
+			if err := r.authenticate(ctx, res.Header.Get("WWW-Authenticate")); err != nil {
+				return nil, "", err
+			}
+		case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+			return nil, "", fmt.Errorf("%w %s from %s", ErrUnauthorized, r.ref, r.ref.Registry)
+		case res.StatusCode == http.StatusNotFound:
+			return nil, "", fmt.Errorf("%w: %s", ErrNotFound, r.ref)
+		default:
+			return nil, "", fmt.Errorf("%s responded %s: %s", r.ref.Registry, res.Status, strings.TrimSpace(string(data)))
+		}
+	}
+}
+
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// authenticate sets the Authorization header asked for by a challenge,
+// getting a token from the realm it points to for Bearer challenges.
+func (r *repository) authenticate(ctx context.Context, challenge string) error {
+	scheme, rest, _ := strings.Cut(challenge, " ")
+	switch {
+	case strings.EqualFold(scheme, "Basic"):
+		if r.auth == (Auth{}) {
+			return fmt.Errorf("%w %s: %s requires credentials", ErrUnauthorized, r.ref, r.ref.Registry)
+		}
+		r.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(r.auth.Username+":"+r.auth.Password))
+		return nil
+	case !strings.EqualFold(scheme, "Bearer"):
+		return fmt.Errorf("%s asks for unsupported authentication %q", r.ref.Registry, scheme)
+	}
+
+	params := map[string]string{}
+	for _, m := range challengeParam.FindAllStringSubmatch(rest, -1) {
+		params[m[1]] = m[2]
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return fmt.Errorf("%s asks for authentication with invalid realm %q", r.ref.Registry, params["realm"])
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", "repository:"+r.ref.Repository+":pull")
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if r.auth != (Auth{}) {
+		req.SetBasicAuth(r.auth.Username, r.auth.Password)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not authenticate to %s: %w", r.ref.Registry, err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w %s: %s refused the credentials (%s)", ErrUnauthorized, r.ref, r.ref.Registry, res.Status)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxManifestSize)).Decode(&token); err != nil {
+		return fmt.Errorf("invalid token from %s: %w", r.ref.Registry, err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	r.authorization = "Bearer " + token.Token
+	return nil
+}
+
+// scheme returns the scheme of the API of a registry: plain HTTP for local
+// ones, like Docker.
+func scheme(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "localhost" || net.ParseIP(host).IsLoopback() {
+		return "http"
+	}
+	return "https"
+}
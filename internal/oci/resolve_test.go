@@ -0,0 +1,98 @@
+package oci_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/a0dotrun/a0ctl/internal/oci"
+	"github.com/a0dotrun/a0ctl/internal/oci/ocitest"
+)
+
+var linuxAMD64 = oci.Platform{OS: "linux", Architecture: "amd64"}
+
+func parse(t *testing.T, s string) oci.Reference {
+	t.Helper()
+	ref, err := oci.ParseReference(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+func TestResolve(t *testing.T) {
+	r := ocitest.New(t)
+	single := r.Push("acme/web", "v1", "linux/amd64")
+	multi := r.Push("acme/web", "v2", "linux/amd64", "linux/arm64")
+
+	image, err := oci.Resolve(context.Background(), parse(t, r.Host()+"/acme/web:v1"), linuxAMD64, oci.Auth{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image.Digest != single || image.MediaType != oci.MediaTypeOCIManifest || image.Pinned() != r.Host()+"/acme/web@"+single {
+		t.Errorf("image = %+v", image)
+	}
+
+	image, err = oci.Resolve(context.Background(), parse(t, r.Host()+"/acme/web:v2@"+multi), linuxAMD64, oci.Auth{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image.Digest != multi || image.MediaType != oci.MediaTypeOCIIndex || len(image.Platforms) != 2 {
+		t.Errorf("image = %+v", image)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	r := ocitest.New(t)
+	single := r.Push("acme/web", "v1", "linux/arm64")
+	other := r.Push("acme/web", "v2", "linux/amd64")
+
+	_, err := oci.Resolve(context.Background(), parse(t, r.Host()+"/acme/web:v1"), linuxAMD64, oci.Auth{})
+	if err == nil || !strings.Contains(err.Error(), "has no image for linux/amd64, only for linux/arm64") {
+		t.Errorf("wrong platform error = %v", err)
+	}
+
+	_, err = oci.Resolve(context.Background(), parse(t, r.Host()+"/acme/web:v3"), linuxAMD64, oci.Auth{})
+	if !errors.Is(err, oci.ErrNotFound) {
+		t.Errorf("missing tag error = %v, want ErrNotFound", err)
+	}
+
+	// The digest wins over the tag.
+	_, err = oci.Resolve(context.Background(), parse(t, r.Host()+"/acme/web:v2@"+single), linuxAMD64, oci.Auth{})
+	if err == nil || !strings.Contains(err.Error(), "only for linux/arm64") {
+		t.Errorf("Resolve() of a digest for linux/arm64 error = %v", err)
+	}
+	if _, err := oci.Resolve(context.Background(), parse(t, r.Host()+"/acme/web@"+other), linuxAMD64, oci.Auth{}); err != nil {
+		t.Errorf("Resolve() by digest: %v", err)
+	}
+}
+
+func TestResolveAuth(t *testing.T) {
+	r := ocitest.New(t)
+	r.RequireAuth("bot", "s3cret")
+	digest := r.Push("acme/private", "v1", "linux/amd64")
+	ref := parse(t, r.Host()+"/acme/private:v1")
+
+	if _, err := oci.Resolve(context.Background(), ref, linuxAMD64, oci.Auth{}); !errors.Is(err, oci.ErrUnauthorized) {
+		t.Errorf("anonymous error = %v, want ErrUnauthorized", err)
+	}
+	if _, err := oci.Resolve(context.Background(), ref, linuxAMD64, oci.Auth{Username: "bot", Password: "wrong"}); !errors.Is(err, oci.ErrUnauthorized) {
+		t.Errorf("wrong password error = %v, want ErrUnauthorized", err)
+	}
+	image, err := oci.Resolve(context.Background(), ref, linuxAMD64, oci.Auth{Username: "bot", Password: "s3cret"})
+	if err != nil || image.Digest != digest {
+		t.Errorf("Resolve() = %+v, %v", image, err)
+	}
+}
+
+func TestParsePlatform(t *testing.T) {
+	if p, err := oci.ParsePlatform("linux/arm/v7"); err != nil || p != (oci.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}) {
+		t.Errorf("ParsePlatform() = %+v, %v", p, err)
+	}
+	for _, s := range []string{"linux", "linux/", "linux/arm/v7/x"} {
+		if _, err := oci.ParsePlatform(s); err == nil {
+			t.Errorf("ParsePlatform(%q) succeeded", s)
+		}
+	}
+}
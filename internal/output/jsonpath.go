@@ -0,0 +1,188 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a parsed JSONPath template in the style of kubectl: literal
+// text with {expressions} in braces, e.g. "{.name}: {.regions[*]}".
+//
+// Expressions support field access (.name), array indexes ([0], [-1]) and
+// wildcards ([*] and .*). Expressions without braces are taken as a single
+// expression.
+type jsonPath struct {
+	parts []jsonPathPart
+}
+
+type jsonPathPart struct {
+	literal string
+	steps   []jsonPathStep
+	isExpr  bool
+}
+
+type jsonPathStep struct {
+	field    string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+func parseJSONPath(s string) (*jsonPath, error) {
+	if !strings.Contains(s, "{") {
+		s = "{" + s + "}"
+	}
+
+	p := &jsonPath{}
+	for len(s) > 0 {
+		start := strings.IndexByte(s, '{')
+		if start < 0 {
+			p.parts = append(p.parts, jsonPathPart{literal: s})
+			break
+		}
+		if start > 0 {
+			p.parts = append(p.parts, jsonPathPart{literal: s[:start]})
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed brace in %q", s)
+		}
+		steps, err := parseJSONPathExpr(s[start+1 : start+end])
+		if err != nil {
+			return nil, err
+		}
+		p.parts = append(p.parts, jsonPathPart{steps: steps, isExpr: true})
+		s = s[start+end+1:]
+	}
+	return p, nil
+}
+
+func parseJSONPathExpr(expr string) ([]jsonPathStep, error) {
+	expr = strings.TrimPrefix(strings.TrimSpace(expr), "$")
+	var steps []jsonPathStep
+	for len(expr) > 0 {
+		switch expr[0] {
+		case '.':
+			expr = expr[1:]
+			end := strings.IndexAny(expr, ".[")
+			if end < 0 {
+				end = len(expr)
+			}
+			name := expr[:end]
+			expr = expr[end:]
+			switch name {
+			case "":
+				// a lone "." refers to the whole value
+			case "*":
+				steps = append(steps, jsonPathStep{wildcard: true})
+			default:
+				steps = append(steps, jsonPathStep{field: name})
+			}
+		case '[':
+			end := strings.IndexByte(expr, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed bracket in %q", expr)
+			}
+			sub := strings.Trim(expr[1:end], `'"`)
+			expr = expr[end+1:]
+			if sub == "*" {
+				steps = append(steps, jsonPathStep{wildcard: true})
+				continue
+			}
+			if i, err := strconv.Atoi(sub); err == nil {
+				steps = append(steps, jsonPathStep{index: i, isIndex: true})
+				continue
+			}
+			steps = append(steps, jsonPathStep{field: sub})
+		default:
+			return nil, fmt.Errorf("unexpected %q in expression, fields must start with '.'", expr)
+		}
+	}
+	return steps, nil
+}
+
+func (p *jsonPath) execute(w io.Writer, v any) error {
+	var b strings.Builder
+	for _, part := range p.parts {
+		if !part.isExpr {
+			b.WriteString(part.literal)
+			continue
+		}
+
+		results := []any{v}
+		for _, step := range part.steps {
+			results = step.apply(results)
+		}
+
+		formatted := make([]string, 0, len(results))
+		for _, r := range results {
+			s, err := formatJSONPathValue(r)
+			if err != nil {
+				return err
+			}
+			formatted = append(formatted, s)
+		}
+		b.WriteString(strings.Join(formatted, " "))
+	}
+	b.WriteByte('\n')
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// apply evaluates the step on each value. Values the step doesn't apply to
+// are dropped, like missing fields in kubectl.
+func (s jsonPathStep) apply(values []any) []any {
+	var out []any
+	for _, v := range values {
+		switch v := v.(type) {
+		case map[string]any:
+			switch {
+			case s.wildcard:
+				// In the order of the keys, maps have none.
+				for _, k := range slices.Sorted(maps.Keys(v)) {
+					out = append(out, v[k])
+				}
+			case !s.isIndex:
+				if e, ok := v[s.field]; ok {
+					out = append(out, e)
+				}
+			}
+		case []any:
+			switch {
+			case s.wildcard:
+				out = append(out, v...)
+			case s.isIndex:
+				i := s.index
+				if i < 0 {
+					i += len(v)
+				}
+				if i >= 0 && i < len(v) {
+					out = append(out, v[i])
+				}
+			}
+		}
+	}
+	return out
+}
+
+func formatJSONPathValue(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", nil
+	case map[string]any, []any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return fmt.Sprint(v), nil
+}
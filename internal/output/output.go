@@ -0,0 +1,163 @@
+// Package output renders command results in the format selected with the
+// global --output flag.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported output formats.
+const (
+	Table    = "table"
+	JSON     = "json"
+	YAML     = "yaml"
+	Template = "template"
+	JSONPath = "jsonpath"
+)
+
+// Formats lists the accepted values of the --output flag.
+var Formats = []string{Table, JSON, YAML, Template + "=...", JSONPath + "=..."}
+
+// Format is a parsed --output value.
+type Format struct {
+	Kind string
+	// Arg is the template or JSONPath expression.
+	Arg string
+}
+
+// Parse parses an --output value such as json or template={{.name}}.
+func Parse(s string) (Format, error) {
+	kind, arg, hasArg := strings.Cut(s, "=")
+	switch kind {
+	case "", Table:
+		return Format{Kind: Table}, nil
+	case JSON, YAML:
+		if hasArg {
+			return Format{}, fmt.Errorf("output format %s takes no argument", kind)
+		}
+		return Format{Kind: kind}, nil
+	case Template, "go-template":
+		if arg == "" {
+			return Format{}, fmt.Errorf("output format %s requires a template, e.g. %s", kind, `template='{{.username}}'`)
+		}
+		if _, err := template.New("output").Parse(arg); err != nil {
+			return Format{}, fmt.Errorf("invalid template: %w", err)
+		}
+		return Format{Kind: Template, Arg: arg}, nil
+	case JSONPath:
+		if arg == "" {
+			return Format{}, fmt.Errorf("output format %s requires an expression, e.g. %s", kind, `jsonpath='{.username}'`)
+		}
+		if _, err := parseJSONPath(arg); err != nil {
+			return Format{}, fmt.Errorf("invalid JSONPath: %w", err)
+		}
+		return Format{Kind: JSONPath, Arg: arg}, nil
+	}
+	return Format{}, fmt.Errorf("unknown output format %q, expected one of %s", s, strings.Join(Formats, ", "))
+}
+
+// IsTable reports whether f is the human-readable format.
+func (f Format) IsTable() bool {
+	return f.Kind == Table
+}
+
+// Messages returns where progress and informational messages should go:
+// stdout for human-readable output, stderr otherwise so that stdout only
+// contains the structured result.
+func (f Format) Messages(stdout, stderr io.Writer) io.Writer {
+	if f.IsTable() {
+		return stdout
+	}
+	return stderr
+}
+
+// Print writes v to w in format f. In table format, table is called to
+// write the human-readable representation instead.
+func (f Format) Print(w io.Writer, v any, table func(w io.Writer) error) error {
+	// Lists are always rendered as arrays, even when empty.
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice && rv.IsNil() {
+		v = reflect.MakeSlice(rv.Type(), 0, 0).Interface()
+	}
+
+	switch f.Kind {
+	case JSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("could not render JSON: %w", err)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	case YAML:
+		generic, err := toGeneric(v)
+		if err != nil {
+			return err
+		}
+		data, err := yaml.Marshal(generic)
+		if err != nil {
+			return fmt.Errorf("could not render YAML: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+	case Template:
+		generic, err := toGeneric(v)
+		if err != nil {
+			return err
+		}
+		tmpl, err := template.New("output").Parse(f.Arg)
+		if err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+		if err := tmpl.Execute(w, generic); err != nil {
+			return fmt.Errorf("could not render template: %w", err)
+		}
+		_, err = fmt.Fprintln(w)
+		return err
+	case JSONPath:
+		generic, err := toGeneric(v)
+		if err != nil {
+			return err
+		}
+		expr, err := parseJSONPath(f.Arg)
+		if err != nil {
+			return fmt.Errorf("invalid JSONPath: %w", err)
+		}
+		return expr.execute(w, generic)
+	}
+	return table(w)
+}
+
+// Message is the result of commands that only report what they did.
+type Message struct {
+	Message string `json:"message"`
+}
+
+// PrintMessage prints a message as plain text, or as a Message in
+// structured formats.
+func (f Format) PrintMessage(w io.Writer, format string, a ...any) error {
+	msg := fmt.Sprintf(format, a...)
+	return f.Print(w, Message{Message: msg}, func(w io.Writer) error {
+		_, err := fmt.Fprintln(w, msg)
+		return err
+	})
+}
+
+// toGeneric converts v to maps and slices through JSON, so that templates
+// and JSONPath expressions use the same field names as the JSON output.
+func toGeneric(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("could not render output: %w", err)
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("could not render output: %w", err)
+	}
+	return generic, nil
+}
@@ -0,0 +1,224 @@
+// Package prompt provides interactive prompts that respect the global
+// --yes and --no-input flags and fail fast when there is no terminal.
+// Prompts read the answers from the stdin of the command and write to its
+// stderr.
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/flags"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// ErrNonInteractive is returned when input is needed but prompting is not
+// possible, either because of --no-input or because stdin is not a terminal.
+var ErrNonInteractive = errors.New("input required")
+
+// ErrAborted is returned when a confirmation is declined.
+var ErrAborted = errors.New("aborted")
+
+// reader buffers the input of the prompts, read from source, so that the
+// answers typed ahead aren't lost between prompts.
+var (
+	reader *bufio.Reader
+	source io.Reader
+)
+
+// terminal returns the stdin of cmd if it is a terminal, or nil.
+func terminal(cmd *cobra.Command) *os.File {
+	return cli.Terminal(cmd.InOrStdin())
+}
+
+// nonInteractive returns an error explaining how to provide the input
+// without a prompt, or nil if prompting is possible.
+func nonInteractive(cmd *cobra.Command, question, hint string) error {
+	var reason string
+	switch {
+	case flags.NoInput():
+		reason = "--no-input is set"
+	case terminal(cmd) == nil:
+		reason = "stdin is not a terminal"
+	default:
+		return nil
+	}
+	return fmt.Errorf("%w: cannot ask %q because %s, %s", ErrNonInteractive, question, reason, hint)
+}
+
+func readLine(cmd *cobra.Command) (string, error) {
+	if in := cmd.InOrStdin(); reader == nil || source != in {
+		reader, source = bufio.NewReader(in), in
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Confirm asks a yes/no question. It returns true without asking when --yes
+// is set.
+func Confirm(cmd *cobra.Command, question string, def bool) (bool, error) {
+	if flags.Yes() {
+		return true, nil
+	}
+	if err := nonInteractive(cmd, question, "pass "+cli.Emph("--yes")+" to confirm"); err != nil {
+		return false, err
+	}
+
+	choices := "y/N"
+	if def {
+		choices = "Y/n"
+	}
+	for {
+		fmt.Fprintf(cmd.ErrOrStderr(), "%s [%s] ", question, choices)
+		answer, err := readLine(cmd)
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(cmd.ErrOrStderr(), "Please answer yes or no.")
+	}
+}
+
+// ConfirmAction asks to confirm a destructive action, which is declined by
+// default, and returns ErrAborted if it isn't confirmed.
+func ConfirmAction(cmd *cobra.Command, question string) error {
+	ok, err := Confirm(cmd, question, false)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrAborted
+	}
+	return nil
+}
+
+// Select asks to choose one of options and returns its index.
+func Select(cmd *cobra.Command, question string, options []string, def int) (int, error) {
+	if err := nonInteractive(cmd, question, "pass the value as an argument or flag"); err != nil {
+		return 0, err
+	}
+
+	fmt.Fprintln(cmd.ErrOrStderr(), question)
+	for i, o := range options {
+		fmt.Fprintf(cmd.ErrOrStderr(), "  %d) %s\n", i+1, o)
+	}
+	for {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Choose [1-%d] (%d): ", len(options), def+1)
+		answer, err := readLine(cmd)
+		if err != nil {
+			return 0, err
+		}
+		if answer == "" {
+			return def, nil
+		}
+		n, err := strconv.Atoi(answer)
+		if err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Please enter a number between 1 and %d.\n", len(options))
+	}
+}
+
+// MultiSelect asks to choose any number of options and returns their
+// indexes in ascending order.
+func MultiSelect(cmd *cobra.Command, question string, options []string) ([]int, error) {
+	if err := nonInteractive(cmd, question, "pass the values as arguments or flags"); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintln(cmd.ErrOrStderr(), question)
+	for i, o := range options {
+		fmt.Fprintf(cmd.ErrOrStderr(), "  %d) %s\n", i+1, o)
+	}
+	for {
+		fmt.Fprint(cmd.ErrOrStderr(), "Choose one or more, separated by commas: ")
+		answer, err := readLine(cmd)
+		if err != nil {
+			return nil, err
+		}
+
+		selected, ok := parseSelection(answer, len(options))
+		if ok {
+			return selected, nil
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Please enter numbers between 1 and %d, e.g. 1,3.\n", len(options))
+	}
+}
+
+func parseSelection(answer string, count int) ([]int, bool) {
+	var selected []int
+	for _, field := range strings.Split(answer, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 || n > count {
+			return nil, false
+		}
+		if !slices.Contains(selected, n-1) {
+			selected = append(selected, n-1)
+		}
+	}
+	slices.Sort(selected)
+	return selected, len(selected) > 0
+}
+
+// Text asks for a line of text. An empty answer returns def, and is not
+// accepted when def is empty.
+func Text(cmd *cobra.Command, question, def string) (string, error) {
+	if err := nonInteractive(cmd, question, "pass the value as an argument or flag"); err != nil {
+		return "", err
+	}
+
+	for {
+		if def != "" {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s (%s): ", question, def)
+		} else {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s: ", question)
+		}
+		answer, err := readLine(cmd)
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			answer = def
+		}
+		if answer != "" {
+			return answer, nil
+		}
+	}
+}
+
+// Password asks for a secret without echoing it.
+func Password(cmd *cobra.Command, question string) (string, error) {
+	if err := nonInteractive(cmd, question, "pass the value as an argument"); err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s: ", question)
+	data, err := term.ReadPassword(int(terminal(cmd).Fd()))
+	fmt.Fprintln(cmd.ErrOrStderr())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
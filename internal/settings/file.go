@@ -0,0 +1,224 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/kirsle/configdir"
+)
+
+// Settings are shared by all a0ctl processes of a user, e.g. parallel jobs
+// in CI. Writes are serialized with an advisory lock on a file next to the
+// settings file, and only the keys changed by this process are written on
+// top of the current content of the file.
+
+const (
+	dirPerm  = 0o700
+	filePerm = 0o600
+)
+
+// ErrConcurrentModification is returned when another process changed a
+// setting that this process also changed.
+var ErrConcurrentModification = errors.New("settings were changed by another a0ctl process at the same time, run the command again")
+
+func lockPath(configFile string) string {
+	return configFile + ".lock"
+}
+
+// ensureDir creates the settings directory, private to the user, as the
+// settings contain tokens. The permissions of the default directory are
+// tightened if needed, but another existing directory, e.g. a checkout
+// given with A0_CONFIG_PATH, is left as is with a warning.
+func ensureDir(configPath string, stderr io.Writer) error {
+	info, err := os.Stat(configPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return os.MkdirAll(configPath, dirPerm)
+	}
+	if err != nil {
+		return err
+	}
+	// Permissions are not enforced on Windows.
+	if info.Mode().Perm()&^dirPerm == 0 || runtime.GOOS == "windows" {
+		return nil
+	}
+	if filepath.Clean(configPath) == filepath.Clean(configdir.LocalConfig("a0")) {
+		return os.Chmod(configPath, dirPerm)
+	}
+	fmt.Fprintf(stderr, "%s: the settings directory %s is accessible by other users, only the settings file is kept private\n",
+		cli.Warn("Warning"), cli.Emph(configPath))
+	return nil
+}
+
+// ensureFilePerm makes the settings file readable only by the user, as it
+// contains tokens.
+func ensureFilePerm(configFile string) error {
+	info, err := os.Stat(configFile)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&^filePerm != 0 {
+		return os.Chmod(configFile, filePerm)
+	}
+	return nil
+}
+
+// readValues reads the settings file as a map with lowercase keys, like
+// viper uses. A missing file has no values.
+func readValues(configFile string) ([]byte, map[string]any, error) {
+	data, err := os.ReadFile(configFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, map[string]any{}, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	values, err := parseValues(data)
+	return data, values, err
+}
+
+// writeValues atomically replaces the settings file: readers see either
+// the old or the new content, never a partial write.
+func writeValues(configFile string, values map[string]any) error {
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(configFile), ".settings-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(filePerm); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), configFile)
+}
+
+// withLock runs f while holding the settings lock.
+func withLock(configFile string, f func() error) error {
+	unlock, err := lockFile(lockPath(configFile))
+	if err != nil {
+		return fmt.Errorf("could not lock settings file: %w", err)
+	}
+	defer unlock()
+	return f()
+}
+
+// persist writes the changes of s on top of the current content of the
+// settings file. It fails if another process changed one of the same keys
+// since s was loaded.
+func (s *Settings) persist() error {
+	return withLock(s.file, func() error {
+		data, current, err := readValues(s.file)
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(data, s.loaded) {
+			loaded, err := parseValues(s.loaded)
+			if err != nil {
+				return err
+			}
+			// Other processes' changes are kept, unless they conflict
+			// with ours.
+			for key, value := range s.changes {
+				theirs := lookup(current, key)
+				if !reflect.DeepEqual(theirs, lookup(loaded, key)) && !reflect.DeepEqual(theirs, normalize(value)) {
+					return ErrConcurrentModification
+				}
+			}
+		}
+
+		for key, value := range s.changes {
+			assign(current, key, value)
+		}
+		if err := writeValues(s.file, current); err != nil {
+			return err
+		}
+
+		s.loaded, err = os.ReadFile(s.file)
+		if err != nil {
+			return err
+		}
+		s.changes = map[string]any{}
+		s.changed = false
+		return nil
+	})
+}
+
+// parseValues parses the content of the settings file.
+func parseValues(data []byte) (map[string]any, error) {
+	values := map[string]any{}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+	}
+	return lowercaseKeys(values), nil
+}
+
+// normalize converts v to the type it has once read back from JSON.
+func normalize(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+// lookup returns the value at a dotted key like profiles.default.token.
+func lookup(values map[string]any, key string) any {
+	parts := strings.Split(strings.ToLower(key), ".")
+	var v any = values
+	for _, p := range parts {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[p]
+	}
+	return v
+}
+
+// assign sets the value at a dotted key, creating intermediate objects.
+func assign(values map[string]any, key string, value any) {
+	parts := strings.Split(strings.ToLower(key), ".")
+	m := values
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := m[p].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			m[p] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = value
+}
@@ -0,0 +1,206 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func load(t *testing.T, dir string) *Settings {
+	t.Helper()
+	s, err := Load(Options{Dir: dir, Stderr: io.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestEnsureDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not enforced on Windows")
+	}
+	perm := func(path string) os.FileMode {
+		t.Helper()
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Mode().Perm()
+	}
+
+	// Directories created by a0ctl are private.
+	created := filepath.Join(t.TempDir(), "a0")
+	load(t, created)
+	if got := perm(created); got != dirPerm {
+		t.Errorf("permissions of a new settings directory = %o, want %o", got, dirPerm)
+	}
+
+	// Like a checkout given with A0_CONFIG_PATH=.
+	chosen := t.TempDir()
+	if err := os.Chmod(chosen, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	var stderr strings.Builder
+	if _, err := Load(Options{Dir: chosen, Stderr: &stderr}); err != nil {
+		t.Fatal(err)
+	}
+	if got := perm(chosen); got != 0o755 {
+		t.Errorf("permissions of an existing directory changed to %o", got)
+	}
+	if !strings.Contains(stderr.String(), "accessible by other users") {
+		t.Errorf("stderr = %q, want a warning", stderr.String())
+	}
+	if got := perm(FilePath(chosen)); got != filePerm {
+		t.Errorf("permissions of the settings file = %o, want %o", got, filePerm)
+	}
+}
+
+func TestPersistMerges(t *testing.T) {
+	dir := t.TempDir()
+	a, b := load(t, dir), load(t, dir)
+
+	a.SetToken("t0k")
+	if err := a.Persist(); err != nil {
+		t.Fatal(err)
+	}
+	// b was loaded before a's change, which must be kept.
+	b.SetOutput("json")
+	if err := b.Persist(); err != nil {
+		t.Fatal(err)
+	}
+
+	s := load(t, dir)
+	if s.GetToken() != "t0k" || s.GetOutput() != "json" {
+		t.Errorf("token = %q, output = %q", s.GetToken(), s.GetOutput())
+	}
+}
+
+func TestPersistConflict(t *testing.T) {
+	dir := t.TempDir()
+	a, b, c := load(t, dir), load(t, dir), load(t, dir)
+
+	a.SetOutput("json")
+	if err := a.Persist(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Setting the same value isn't a conflict.
+	c.SetOutput("json")
+	if err := c.Persist(); err != nil {
+		t.Errorf("Persist() of the same value = %v", err)
+	}
+
+	b.SetOutput("yaml")
+	if err := b.Persist(); !errors.Is(err, ErrConcurrentModification) {
+		t.Errorf("Persist() = %v, want ErrConcurrentModification", err)
+	}
+	if s := load(t, dir); s.GetOutput() != "json" {
+		t.Errorf("output = %q, the change of the first process was overwritten", s.GetOutput())
+	}
+}
+
+func TestPersistConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	const n = 8
+
+	settings := make([]*Settings, n)
+	for i := range settings {
+		settings[i] = load(t, dir)
+		settings[i].set(profileKey(fmt.Sprintf("p%d", i), "token"), fmt.Sprintf("t%d", i))
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i, s := range settings {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = s.Persist()
+		}()
+	}
+	wg.Wait()
+
+	s := load(t, dir)
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Persist() %d = %v", i, err)
+		}
+		if got, want := s.ProfileValue(fmt.Sprintf("p%d", i), "token"), fmt.Sprintf("t%d", i); got != want {
+			t.Errorf("token of p%d = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestWithLock(t *testing.T) {
+	file := FilePath(t.TempDir())
+
+	var holders, overlaps atomic.Int32
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := withLock(file, func() error {
+				if holders.Add(1) > 1 {
+					overlaps.Add(1)
+				}
+				time.Sleep(10 * time.Millisecond)
+				holders.Add(-1)
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := overlaps.Load(); n != 0 {
+		t.Errorf("the lock was held %d times at once", n+1)
+	}
+
+	want := errors.New("failed")
+	if err := withLock(file, func() error { return want }); err != want {
+		t.Errorf("withLock() = %v, want the error of f", err)
+	}
+}
+
+func TestWriteValues(t *testing.T) {
+	dir := t.TempDir()
+	file := FilePath(dir)
+	if err := os.WriteFile(file, []byte(`{"output": "yaml"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeValues(file, map[string]any{"version": 1, "output": "json"}); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The file is replaced by a renamed temporary file, not written over.
+	if os.SameFile(before, after) {
+		t.Error("the settings file was written in place")
+	}
+	if runtime.GOOS != "windows" && after.Mode().Perm() != filePerm {
+		t.Errorf("permissions = %o, want %o", after.Mode().Perm(), filePerm)
+	}
+	if got := readSettings(t, dir); got["output"] != "json" || got["version"] != 1.0 {
+		t.Errorf("settings = %v", got)
+	}
+	if tmp, _ := filepath.Glob(filepath.Join(dir, ".settings-*")); len(tmp) != 0 {
+		t.Errorf("temporary files left: %v", tmp)
+	}
+}
@@ -0,0 +1,172 @@
+package settings
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// currentVersion is the version of the settings file layout written by
+// this build. Files without a version field are version 0.
+const currentVersion = 1
+
+// migration upgrades the settings file to version from the previous one.
+type migration struct {
+	version     int
+	description string
+	apply       func(values map[string]any)
+}
+
+// migrations must be sorted by version.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "move credentials into the default profile",
+		apply:       migrateToProfiles,
+	},
+}
+
+// migrate upgrades the settings file to currentVersion, after saving a
+// backup of it, and returns its new content, which v is reloaded with.
+// data is the content v read.
+func migrate(v *viper.Viper, configFile string, data []byte, stderr io.Writer) ([]byte, error) {
+	if v.GetInt("version") >= currentVersion {
+		return data, nil
+	}
+
+	var backup string
+	err := withLock(configFile, func() error {
+		// Another process may have upgraded the file in the meantime.
+		var values map[string]any
+		var err error
+		data, values, err = readValues(configFile)
+		if err != nil {
+			return err
+		}
+		version, _ := values["version"].(float64)
+		if int(version) >= currentVersion {
+			return nil
+		}
+
+		backup, err = backupFile(configFile, data, fmt.Sprintf("v%d", int(version)))
+		if err != nil {
+			return fmt.Errorf("could not back up settings file before upgrading it: %w", err)
+		}
+		for _, m := range migrations {
+			if m.version > int(version) {
+				m.apply(values)
+			}
+		}
+		values["version"] = currentVersion
+
+		if err := writeValues(configFile, values); err != nil {
+			return fmt.Errorf("failed to upgrade a0 settings file: %w", err)
+		}
+		data, err = os.ReadFile(configFile)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	if backup != "" {
+		fmt.Fprintf(stderr, "Upgraded settings file %s to version %d, the old file was saved to %s\n",
+			configFile, currentVersion, backup)
+	}
+	return data, nil
+}
+
+// createFile creates an empty settings file, unless another process just
+// did, and returns its content.
+func createFile(configFile string) ([]byte, error) {
+	var data []byte
+	err := withLock(configFile, func() error {
+		var err error
+		data, err = os.ReadFile(configFile)
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if err := writeValues(configFile, map[string]any{"version": currentVersion}); err != nil {
+			return err
+		}
+		data, err = os.ReadFile(configFile)
+		return err
+	})
+	return data, err
+}
+
+// resetFile replaces the settings file with an empty one after saving a
+// backup of it, returning the new content and the path of the backup.
+func resetFile(configFile string) ([]byte, string, error) {
+	var data []byte
+	var backup string
+	err := withLock(configFile, func() error {
+		old, err := os.ReadFile(configFile)
+		if err != nil {
+			return err
+		}
+		backup, err = backupFile(configFile, old, "broken")
+		if err != nil {
+			return fmt.Errorf("could not back up settings file before resetting it: %w", err)
+		}
+		if err := writeValues(configFile, map[string]any{"version": currentVersion}); err != nil {
+			return err
+		}
+		data, err = os.ReadFile(configFile)
+		return err
+	})
+	return data, backup, err
+}
+
+// profileSettings are the settings that moved into profiles in version 1.
+var profileSettings = []string{"token", "username", "org", "baseurl", "homeurl"}
+
+// migrateToProfiles moves the credentials and URLs at the top level into
+// the default profile. It also drops the config-path key that older
+// versions wrote by mistake when A0_CONFIG_PATH was set.
+func migrateToProfiles(values map[string]any) {
+	profile := make(map[string]any)
+	for _, key := range profileSettings {
+		if v, ok := values[key]; ok {
+			profile[key] = v
+			delete(values, key)
+		}
+	}
+	delete(values, "config-path")
+
+	if len(profile) > 0 {
+		values["profiles"] = map[string]any{DefaultProfile: profile}
+	}
+}
+
+func lowercaseKeys(values map[string]any) map[string]any {
+	out := make(map[string]any, len(values))
+	for k, v := range values {
+		if m, ok := v.(map[string]any); ok {
+			v = lowercaseKeys(m)
+		}
+		out[strings.ToLower(k)] = v
+	}
+	return out
+}
+
+// backupFile saves data, the content of the file at path, next to it with
+// the reason and the current time in its name, and returns the path of the
+// copy.
+func backupFile(path string, data []byte, reason string) (string, error) {
+	backup := fmt.Sprintf("%s.%s-%s.bak", path, reason, time.Now().Format("20060102T150405"))
+	if err := os.WriteFile(backup, data, filePerm); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
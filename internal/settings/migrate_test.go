@@ -0,0 +1,173 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeSettings writes the settings file of a new directory.
+func writeSettings(t *testing.T, content string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "a0")
+	if err := os.Mkdir(dir, dirPerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "settings.json"), []byte(content), filePerm); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// readSettings returns the values of the settings file of dir.
+func readSettings(t *testing.T, dir string) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "settings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	return values
+}
+
+// backups returns the backups of the settings file of dir.
+func backups(t *testing.T, dir string) []string {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "settings.json.*.bak"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return paths
+}
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]any
+		// backup is the prefix of the backup name, empty if none is made.
+		backup string
+	}{
+		{
+			name:    "legacy",
+			content: `{"token": "t0k", "username": "ada", "baseURL": "https://api.example.com", "config-path": "/tmp/a0", "output": "json"}`,
+			want: map[string]any{
+				"version": 1.0,
+				"output":  "json",
+				"profiles": map[string]any{
+					"default": map[string]any{"token": "t0k", "username": "ada", "baseurl": "https://api.example.com"},
+				},
+			},
+			backup: "settings.json.v0-",
+		},
+		{
+			name:    "legacy empty",
+			content: `{}`,
+			want:    map[string]any{"version": 1.0},
+			backup:  "settings.json.v0-",
+		},
+		{
+			name:    "current",
+			content: `{"version": 1, "profiles": {"work": {"token": "t0k"}}, "profile": "work"}`,
+			want:    map[string]any{"version": 1.0, "profiles": map[string]any{"work": map[string]any{"token": "t0k"}}, "profile": "work"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeSettings(t, tt.content)
+			var stderr bytes.Buffer
+			s, err := Load(Options{Dir: dir, Stderr: &stderr})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := readSettings(t, dir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("settings = %v, want %v", got, tt.want)
+			}
+
+			paths := backups(t, dir)
+			if tt.backup == "" {
+				if len(paths) != 0 || stderr.Len() != 0 {
+					t.Errorf("backups = %v, stderr = %q", paths, stderr.String())
+				}
+				return
+			}
+			if len(paths) != 1 || !strings.HasPrefix(filepath.Base(paths[0]), tt.backup) {
+				t.Fatalf("backups = %v, want one named %s*", paths, tt.backup)
+			}
+			data, err := os.ReadFile(paths[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.content {
+				t.Errorf("backup = %q, want %q", data, tt.content)
+			}
+			if !strings.Contains(stderr.String(), "Upgraded settings file") || !strings.Contains(stderr.String(), paths[0]) {
+				t.Errorf("stderr = %q", stderr.String())
+			}
+
+			// The upgraded file is what the settings are read from.
+			if s.GetToken() != lookupString(tt.want, "profiles.default.token") {
+				t.Errorf("token = %q", s.GetToken())
+			}
+		})
+	}
+}
+
+func lookupString(values map[string]any, key string) string {
+	v, _ := lookup(values, key).(string)
+	return v
+}
+
+func TestMigrateCorrupt(t *testing.T) {
+	const corrupt = `{"token": "t0k",`
+
+	dir := writeSettings(t, corrupt)
+	var stderr bytes.Buffer
+	if _, err := Load(Options{Dir: dir, Stderr: &stderr}); err == nil {
+		t.Fatal("Load() of a corrupt file succeeded")
+	}
+	if !strings.Contains(stderr.String(), "--reset-config") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "settings.json")); string(data) != corrupt {
+		t.Errorf("corrupt file changed without --reset-config: %q", data)
+	}
+
+	stderr.Reset()
+	if _, err := Load(Options{Dir: dir, Stderr: &stderr, ResetConfig: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readSettings(t, dir), map[string]any{"version": 1.0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("settings = %v, want %v", got, want)
+	}
+	paths := backups(t, dir)
+	if len(paths) != 1 || !strings.HasPrefix(filepath.Base(paths[0]), "settings.json.broken-") {
+		t.Fatalf("backups = %v", paths)
+	}
+	if data, _ := os.ReadFile(paths[0]); string(data) != corrupt {
+		t.Errorf("backup = %q, want %q", data, corrupt)
+	}
+	if !strings.Contains(stderr.String(), paths[0]) {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+func TestMigrateToProfiles(t *testing.T) {
+	values := map[string]any{"token": "t0k", "org": "acme", "homeurl": "https://example.com", "config-path": "/tmp", "output": "yaml"}
+	migrateToProfiles(values)
+	want := map[string]any{
+		"output":   "yaml",
+		"profiles": map[string]any{"default": map[string]any{"token": "t0k", "org": "acme", "homeurl": "https://example.com"}},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %v, want %v", values, want)
+	}
+}
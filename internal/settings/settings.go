@@ -2,14 +2,18 @@
 package settings
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
 	"path"
 	"path/filepath"
-	"sync"
+	"regexp"
+	"sort"
 
 	"github.com/a0dotrun/a0ctl/internal/cli"
-	"github.com/a0dotrun/a0ctl/internal/flags"
 	"github.com/kirsle/configdir"
 	"github.com/spf13/viper"
 )
@@ -17,122 +21,287 @@ import (
 const (
 	a0DefaultBaseURL = "https://api.a0.run"
 	a0DefaultHomeURL = "https://a0.run"
+
+	// DefaultProfile is the profile used when none is selected.
+	DefaultProfile = "default"
+
+	EnvConfigPath = "A0_CONFIG_PATH"
+	EnvProfile    = "A0_PROFILE"
+	EnvBaseURL    = "A0_API_BASEURL"
+	EnvHomeURL    = "A0_HOME_BASEURL"
 )
 
+// Options configure how Load reads the settings file.
+type Options struct {
+	// Dir is the directory of the settings file, DefaultDir if empty.
+	Dir string
+	// Profile selects the active profile, overriding A0_PROFILE and the
+	// profile setting.
+	Profile string
+	// ResetConfig replaces a settings file that can't be parsed with an
+	// empty one instead of failing.
+	ResetConfig bool
+	// Stderr receives warnings about the settings file, os.Stderr if nil.
+	Stderr io.Writer
+}
+
 type Settings struct {
+	v *viper.Viper
+	// profile is the profile given with Options.Profile.
+	profile string
+
 	changed bool
+	// file is the path of the settings file.
+	file string
+	// loaded is the content of the file when it was read, used to detect
+	// changes made by other processes.
+	loaded []byte
+	// changes are the values set by this process, by viper key.
+	changes map[string]any
 }
 
-var (
-	settings *Settings
-	mu       sync.Mutex
-)
+// Load reads the settings file, creating it if missing and upgrading it
+// if it was written by an older version.
+func Load(opts Options) (*Settings, error) {
+	if opts.Dir == "" {
+		opts.Dir = DefaultDir()
+	}
+	if opts.Stderr == nil {
+		opts.Stderr = os.Stderr
+	}
+	if err := ensureDir(opts.Dir, opts.Stderr); err != nil {
+		return nil, err
+	}
 
-func ReadSettings() (*Settings, error) {
-	mu.Lock()
-	defer mu.Unlock()
+	v := viper.New()
+	v.SetConfigType("json")
+	configFile := FilePath(opts.Dir)
 
-	if settings != nil {
-		return settings, nil
+	data, err := os.ReadFile(configFile)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// Force config creation
+		data, err = createFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
 	}
 
-	settings = &Settings{}
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		warning := cli.Warn("Warning")
+		if !opts.ResetConfig {
+			flag := cli.Emph("--reset-config")
+			fmt.Fprintf(opts.Stderr, "%s: could not parse JSON config from file %s\n", warning, cli.Emph(configFile))
+			fmt.Fprintf(opts.Stderr, "Fix the syntax errors on the file, or use the %s flag to replace it with a fresh one.\n", flag)
+			fmt.Fprintf(opts.Stderr, "E.g. a0ctl auth login --reset-config\n")
+			return nil, err
+		}
 
-	configPath := configdir.LocalConfig("a0")
-	err := viper.BindEnv("config-path", "A0_CONFIG_PATH")
+		var backup string
+		data, backup, err = resetFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(opts.Stderr, "%s: replaced unparsable settings file %s, the old file was saved to %s\n",
+			warning, cli.Emph(configFile), backup)
+	}
+
+	data, err = migrate(v, configFile, data, opts.Stderr)
 	if err != nil {
 		return nil, err
 	}
-	err = viper.BindEnv("baseURL", "A0_API_BASEURL")
-	if err != nil {
+	if err := ensureFilePerm(configFile); err != nil {
 		return nil, err
 	}
 
-	err = viper.BindEnv("homeURL", "A0_HOME_BASEURL")
-	if err != nil {
+	s := &Settings{
+		v:       v,
+		profile: opts.Profile,
+		file:    configFile,
+		loaded:  data,
+		changes: map[string]any{},
+	}
+	if err := ValidateProfile(s.Profile()); err != nil {
 		return nil, err
 	}
+	return s, nil
+}
 
-	configPathFlag := viper.GetString("config-path")
-	if len(configPathFlag) > 0 {
-		configPath = configPathFlag
+// validProfile matches profile names. They are lowercase as settings keys
+// are case-insensitive, and have no dots, which separate keys.
+var validProfile = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateProfile returns an error if name can't be the name of a profile.
+func ValidateProfile(name string) error {
+	if !validProfile.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q, use lowercase letters, digits, '-' and '_'", name)
 	}
+	return nil
+}
 
-	err = configdir.MakePath(configPath)
-	if err != nil {
-		return nil, err
+// DefaultDir returns the directory of the settings file, A0_CONFIG_PATH if
+// set.
+func DefaultDir() string {
+	if configPath := os.Getenv(EnvConfigPath); len(configPath) > 0 {
+		return configPath
 	}
+	return configdir.LocalConfig("a0")
+}
 
-	viper.SetConfigName("settings")
-	viper.SetConfigType("json")
-	viper.AddConfigPath(configPath)
-	configFile := path.Join(configPath, "settings.json")
+// FilePath returns the absolute path of the settings file in dir.
+func FilePath(dir string) string {
+	configFile := path.Join(dir, "settings.json")
 	if abs, err := filepath.Abs(configFile); err == nil {
 		configFile = abs
 	}
+	return configFile
+}
 
-	if err := viper.ReadInConfig(); err != nil {
-		var configFileNotFoundError viper.ConfigFileNotFoundError
-		var configParseError viper.ConfigParseError
-		switch {
-		case errors.As(err, &configFileNotFoundError):
-			// Force config creation
-			if err := viper.SafeWriteConfig(); err != nil {
-				return nil, err
-			}
-		case errors.As(err, &configParseError):
-			if flags.ResetConfig() {
-				err := viper.WriteConfig()
-				if err != nil {
-					return nil, err
-				}
-				break
-			}
-			warning := cli.Warn("Warning")
-			// FIXME: requires implementation
-			flag := cli.Emph("--reset-config")
-			fmt.Printf("%s: could not parse JSON config from file %s\n", warning, cli.Emph(configFile))
-			fmt.Printf("Fix the syntax errors on the file, or use the %s flag to replace it with a fresh one.\n", flag)
-			fmt.Printf("E.g. a0ctl auth login --reset-config\n")
-			return nil, err
-		default:
-			return nil, err
+// Path returns the absolute path of the settings file.
+func (s *Settings) Path() string {
+	return s.file
+}
+
+// Profile returns the name of the active profile: the one given to Load,
+// the A0_PROFILE env var, the profile setting, or DefaultProfile.
+func (s *Settings) Profile() string {
+	if s.profile != "" {
+		return s.profile
+	}
+	if p := os.Getenv(EnvProfile); p != "" {
+		return p
+	}
+	if p := s.v.GetString("profile"); p != "" {
+		return p
+	}
+	return DefaultProfile
+}
+
+// Profiles returns the names of the configured profiles, always including
+// the active one.
+func (s *Settings) Profiles() []string {
+	names := []string{s.Profile()}
+	for name := range s.v.GetStringMap("profiles") {
+		if name != names[0] {
+			names = append(names, name)
 		}
 	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfileValue returns a setting of the named profile.
+func (s *Settings) ProfileValue(profile, key string) string {
+	return s.v.GetString(profileKey(profile, key))
+}
 
-	return settings, nil
+// profileKey returns the key of a setting of a profile.
+func profileKey(profile, key string) string {
+	return "profiles." + profile + "." + key
 }
 
 func (s *Settings) GetToken() string {
-	return viper.GetString("token")
+	return s.v.GetString(profileKey(s.Profile(), "token"))
 }
 
+// GetBaseURL returns the API URL, A0_API_BASEURL if set.
 func (s *Settings) GetBaseURL() string {
-	return viper.GetString("baseURL")
+	if url := os.Getenv(EnvBaseURL); url != "" {
+		return url
+	}
+	return s.v.GetString(profileKey(s.Profile(), "baseURL"))
 }
 
 func (s *Settings) GetDefaultBaseURL() string {
 	return a0DefaultBaseURL
 }
 
+// A0URL returns the API URL, falling back to the default one.
+func (s *Settings) A0URL() string {
+	if url := s.GetBaseURL(); url != "" {
+		return url
+	}
+	return s.GetDefaultBaseURL()
+}
+
+// GetHomeURL returns the website URL, A0_HOME_BASEURL if set.
 func (s *Settings) GetHomeURL() string {
-	return viper.GetString("homeURL")
+	if url := os.Getenv(EnvHomeURL); url != "" {
+		return url
+	}
+	return s.v.GetString(profileKey(s.Profile(), "homeURL"))
 }
 
 func (s *Settings) GetDefaultHomeURL() string {
 	return a0DefaultHomeURL
 }
 
+// A0HomeURL returns the website URL, falling back to the default one.
+func (s *Settings) A0HomeURL() string {
+	if url := s.GetHomeURL(); url != "" {
+		return url
+	}
+	return s.GetDefaultHomeURL()
+}
+
 func (s *Settings) GetUsername() string {
-	return viper.GetString("username")
+	return s.v.GetString(profileKey(s.Profile(), "username"))
 }
 
-func (s *Settings) SetToken(token string) {
-	viper.Set("token", token)
+// GetOrg returns the slug of the organization commands are scoped to by
+// default, empty for the user's personal account.
+func (s *Settings) GetOrg() string {
+	return s.v.GetString(profileKey(s.Profile(), "org"))
+}
+
+// GetOutput returns the default output format, used when --output isn't given.
+func (s *Settings) GetOutput() string {
+	return s.v.GetString("output")
+}
+
+// GetUpdateNotice reports whether to tell about new versions of a0ctl, on
+// unless disabled.
+func (s *Settings) GetUpdateNotice() bool {
+	if !s.v.IsSet("updateNotice") {
+		return true
+	}
+	return s.v.GetBool("updateNotice")
+}
+
+// set changes a setting, to be written by Persist.
+func (s *Settings) set(key string, value any) {
+	s.v.Set(key, value)
+	s.changes[key] = value
 	s.changed = true
 }
 
+func (s *Settings) SetToken(token string) {
+	s.set(profileKey(s.Profile(), "token"), token)
+}
+
 func (s *Settings) SetUsername(username string) {
-	viper.Set("username", username)
-	s.changed = true
+	s.set(profileKey(s.Profile(), "username"), username)
+}
+
+func (s *Settings) SetOrg(org string) {
+	s.set(profileKey(s.Profile(), "org"), org)
+}
+
+// SetProfile selects the profile used when neither --profile nor
+// A0_PROFILE are given.
+func (s *Settings) SetProfile(profile string) {
+	s.set("profile", profile)
+}
+
+func (s *Settings) SetOutput(format string) {
+	s.set("output", format)
+}
+
+func (s *Settings) SetUpdateNotice(enabled bool) {
+	s.set("updateNotice", enabled)
 }
@@ -0,0 +1,60 @@
+package settings
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLoadValidatesProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		env     string
+		profile string
+		wantErr bool
+	}{
+		{name: "default", profile: DefaultProfile},
+		{name: "flag", flag: "staging", profile: "staging"},
+		{name: "env", env: "prod-eu_2", profile: "prod-eu_2"},
+		{name: "flag with dot", flag: "prod.eu", wantErr: true},
+		{name: "uppercase env", env: "Prod", wantErr: true},
+		{name: "flag over invalid env", flag: "staging", env: "Prod", profile: "staging"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvProfile, tt.env)
+			s, err := Load(Options{Dir: t.TempDir(), Profile: tt.flag, Stderr: io.Discard})
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "invalid profile name") {
+					t.Fatalf("err = %v, want an invalid profile name", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Profile(); got != tt.profile {
+				t.Errorf("Profile() = %q, want %q", got, tt.profile)
+			}
+		})
+	}
+}
+
+func TestSetTokenOfProfile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(EnvProfile, "")
+	s, err := Load(Options{Dir: dir, Profile: "staging", Stderr: io.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetToken("secret")
+	if err := s.Persist(); err != nil {
+		t.Fatal(err)
+	}
+
+	values := readSettings(t, dir)
+	if got := lookupString(values, "profiles.staging.token"); got != "secret" {
+		t.Errorf("profiles.staging.token = %q in %v", got, values)
+	}
+}
@@ -0,0 +1,166 @@
+package update
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/a0dotrun/a0ctl/internal/cli"
+)
+
+// PublicKey is the base64 encoded ed25519 key release binaries are signed
+// with, set at link time like buildinfo.Version.
+var PublicKey = ""
+
+// ErrNoPublicKey is returned when this build can't verify signatures.
+var ErrNoPublicKey = errors.New("this build of a0ctl has no release signing key, install updates with your package manager")
+
+// Install downloads the binary of the release for this platform, verifies
+// it and replaces the running executable with it. Progress is written to w.
+func Install(ctx context.Context, w io.Writer, release Release) error {
+	key, err := publicKey()
+	if err != nil {
+		return err
+	}
+	asset, err := release.Asset()
+	if err != nil {
+		return err
+	}
+	exe, err := executable()
+	if err != nil {
+		return err
+	}
+
+	// The new binary is written next to the executable so that renaming
+	// it into place is atomic.
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".a0ctl-update-*")
+	if err != nil {
+		return fmt.Errorf("could not write to %s, you may need to run the update with more permissions: %w", filepath.Dir(exe), err)
+	}
+	defer os.Remove(tmp.Name())
+
+	digest, err := download(ctx, w, asset.URL, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := verify(key, release.Version, asset, digest); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return err
+	}
+	return replace(exe, tmp.Name())
+}
+
+func publicKey() (ed25519.PublicKey, error) {
+	if PublicKey == "" {
+		return nil, ErrNoPublicKey
+	}
+	key, err := base64.StdEncoding.DecodeString(PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid release signing key")
+	}
+	return key, nil
+}
+
+// executable returns the path of the running binary, with symlinks
+// resolved so that the actual file is replaced.
+func executable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to find executable: %w", err)
+	}
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return "", fmt.Errorf("failed to find executable: %w", err)
+	}
+	return exe, nil
+}
+
+// download writes the binary at url to f, returning its SHA-256 checksum.
+func download(ctx context.Context, w io.Writer, url string, f *os.File) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download update: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download update: %s", res.Status)
+	}
+
+	progress := cli.NewProgressBar(w, "Downloading", res.ContentLength)
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), progress.Reader(res.Body)); err != nil {
+		return nil, fmt.Errorf("failed to download update: %w", err)
+	}
+	progress.Finish()
+	return hash.Sum(nil), nil
+}
+
+// verify checks the downloaded binary against the checksum and signature
+// of the release manifest. The signature must cover the version of the
+// release and the platform of the asset too.
+func verify(key ed25519.PublicKey, version string, asset Asset, digest []byte) error {
+	want, err := hex.DecodeString(asset.SHA256)
+	if err != nil {
+		return fmt.Errorf("invalid checksum in release manifest: %w", err)
+	}
+	if !bytes.Equal(digest, want) {
+		return errors.New("checksum of the downloaded binary doesn't match the release manifest")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(asset.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature in release manifest: %w", err)
+	}
+	message := SignedMessage(version, asset.OS, asset.Arch, hex.EncodeToString(digest))
+	if !ed25519.Verify(key, message, sig) {
+		return errors.New("signature of the downloaded binary is invalid")
+	}
+	return nil
+}
+
+// replace moves the binary at path over exe. Windows doesn't allow
+// replacing a running executable, but allows renaming it out of the way.
+func replace(exe, path string) error {
+	if runtime.GOOS != "windows" {
+		if err := os.Rename(path, exe); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", exe, err)
+		}
+		return nil
+	}
+
+	old := exe + ".old"
+	_ = os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+	if err := os.Rename(path, exe); err != nil {
+		_ = os.Rename(old, exe)
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package update
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("a0ctl binary"))
+	checksum := hex.EncodeToString(sum[:])
+	signed := Asset{
+		OS:        "linux",
+		Arch:      "amd64",
+		SHA256:    checksum,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(private, SignedMessage("v1.2.0", "linux", "amd64", checksum))),
+	}
+
+	if err := verify(public, "v1.2.0", signed, sum[:]); err != nil {
+		t.Errorf("verify() = %v", err)
+	}
+
+	other := signed
+	other.OS = "darwin"
+	tests := []struct {
+		name    string
+		version string
+		asset   Asset
+		digest  []byte
+		err     string
+	}{
+		// A manifest serving the old binary as a newer version.
+		{"version", "v1.3.0", signed, sum[:], "signature of the downloaded binary is invalid"},
+		{"platform", "v1.2.0", other, sum[:], "signature of the downloaded binary is invalid"},
+		{"checksum", "v1.2.0", signed, make([]byte, sha256.Size), "checksum of the downloaded binary doesn't match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verify(public, tt.version, tt.asset, tt.digest); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("verify() = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/buildinfo"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+	"github.com/a0dotrun/a0ctl/internal/settings"
+)
+
+const (
+	// checkInterval is how often the latest release is looked up for the
+	// new version notice.
+	checkInterval = 24 * time.Hour
+	checkTimeout  = 3 * time.Second
+)
+
+// noticeState is the result of the last check, kept so that the notice
+// only needs the network once a day.
+type noticeState struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Latest    string    `json:"latest"`
+}
+
+// latest receives the latest version found by CheckInBackground.
+var latest chan string
+
+// CheckInBackground looks up the latest release if the last check is more
+// than a day old, without blocking the command. The result is shown by
+// PrintNotice.
+func CheckInBackground(config *settings.Settings) {
+	if !noticeEnabled(config) {
+		return
+	}
+
+	latest = make(chan string, 1)
+	state, err := readState()
+	if err == nil && time.Since(state.CheckedAt) < checkInterval {
+		latest <- state.Latest
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+		defer cancel()
+
+		release, err := Latest(ctx, config.A0HomeURL())
+		if err != nil {
+			return
+		}
+		writeState(noticeState{CheckedAt: time.Now(), Latest: release.Version})
+		latest <- release.Version
+	}()
+}
+
+// PrintNotice tells about a new version found by CheckInBackground, if the
+// check already finished.
+func PrintNotice(w io.Writer) {
+	if latest == nil {
+		return
+	}
+
+	var version string
+	select {
+	case version = <-latest:
+	default:
+		return
+	}
+	if version == "" || compareVersions(version, buildinfo.Version) <= 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\nA new version of a0ctl is available: %s → %s\n", buildinfo.Version, cli.Emph(version))
+	fmt.Fprintf(w, "Run %s to install it.\n", cli.Emph("a0ctl update"))
+}
+
+// noticeEnabled reports whether to look for new versions: not for local
+// builds, in CI, when disabled in the settings or when nobody would see it.
+func noticeEnabled(config *settings.Settings) bool {
+	if buildinfo.IsDev() || os.Getenv("CI") != "" || !cli.IsTerminal(os.Stderr) {
+		return false
+	}
+	return config.GetUpdateNotice()
+}
+
+func statePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "a0ctl", "update-check.json"), nil
+}
+
+func readState() (noticeState, error) {
+	var state noticeState
+	path, err := statePath()
+	if err != nil {
+		return state, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, err
+	}
+	err = json.Unmarshal(data, &state)
+	return state, err
+}
+
+// writeState saves the result of a check. Failures only mean the next
+// command checks again.
+func writeState(state noticeState) {
+	path, err := statePath()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0o600)
+}
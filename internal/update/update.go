@@ -0,0 +1,172 @@
+// Package update checks for new releases of a0ctl and replaces the running
+// executable with them.
+//
+// Releases are described by a manifest listing a binary per platform with
+// its SHA-256 checksum and an ed25519 signature binding that checksum to the
+// version and platform, see SignedMessage. Binaries are only installed when
+// both match, so that a manifest can't pass an older binary off as a newer
+// version.
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/buildinfo"
+)
+
+// manifestPath is where the manifest of the latest release is published,
+// relative to the a0 home URL.
+const manifestPath = "/cli/releases/latest.json"
+
+// ErrNoAsset is returned when a release has no binary for this platform.
+var ErrNoAsset = fmt.Errorf("no release available for %s/%s", runtime.GOOS, runtime.GOARCH)
+
+// Release is a published version of a0ctl.
+type Release struct {
+	Version     string    `json:"version"`
+	PublishedAt time.Time `json:"publishedAt"`
+	Assets      []Asset   `json:"assets"`
+}
+
+// Asset is the binary of a release for one platform.
+type Asset struct {
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+	URL  string `json:"url"`
+	// SHA256 is the hex encoded checksum of the binary.
+	SHA256 string `json:"sha256"`
+	// Signature is the base64 encoded ed25519 signature of the
+	// SignedMessage of the binary.
+	Signature string `json:"signature"`
+}
+
+// SignedMessage returns the message signed for the binary of a version for
+// a platform, with its hex encoded SHA-256 checksum:
+//
+//	a0ctl release
+//	version: v1.2.3
+//	platform: linux/amd64
+//	sha256: 9f86d081...
+func SignedMessage(version, goos, goarch, sha256 string) []byte {
+	return fmt.Appendf(nil, "a0ctl release\nversion: %s\nplatform: %s/%s\nsha256: %s\n", version, goos, goarch, sha256)
+}
+
+// Asset returns the binary of the release for the running platform.
+func (r Release) Asset() (Asset, error) {
+	for _, a := range r.Assets {
+		if a.OS == runtime.GOOS && a.Arch == runtime.GOARCH {
+			return a, nil
+		}
+	}
+	return Asset{}, ErrNoAsset
+}
+
+// IsNewer reports whether the release is newer than the running build.
+// Local builds are never considered up to date.
+func (r Release) IsNewer() bool {
+	if buildinfo.IsDev() {
+		return true
+	}
+	return compareVersions(r.Version, buildinfo.Version) > 0
+}
+
+// manifestURL returns the URL of the manifest of the latest release.
+func manifestURL(homeURL string) (*url.URL, error) {
+	base, err := url.Parse(homeURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing home URL: %w", err)
+	}
+	return base.JoinPath(manifestPath), nil
+}
+
+// Latest fetches the manifest of the latest release. Relative asset URLs
+// are resolved against the manifest URL. Releases are published on the
+// website at homeURL.
+func Latest(ctx context.Context, homeURL string) (Release, error) {
+	u, err := manifestURL(homeURL)
+	if err != nil {
+		return Release{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return Release{}, err
+	}
+	req.Header.Set("User-Agent", fmt.Sprintf("a0ctl/%s (%s/%s)",
+		strings.TrimPrefix(buildinfo.Version, "v"), runtime.GOOS, runtime.GOARCH))
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Release{}, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("failed to check for updates: %s", res.Status)
+	}
+
+	var release Release
+	if err := json.NewDecoder(res.Body).Decode(&release); err != nil {
+		return Release{}, fmt.Errorf("failed to deserialize release manifest: %w", err)
+	}
+	if release.Version == "" {
+		return Release{}, errors.New("release manifest has no version")
+	}
+
+	for i, a := range release.Assets {
+		ref, err := url.Parse(a.URL)
+		if err != nil {
+			return Release{}, fmt.Errorf("invalid asset URL %q: %w", a.URL, err)
+		}
+		release.Assets[i].URL = u.ResolveReference(ref).String()
+	}
+	return release, nil
+}
+
+// compareVersions compares two versions like v1.2.3 or 1.2.3-rc.1,
+// returning -1, 0 or 1. Pre-releases sort before their release.
+func compareVersions(a, b string) int {
+	aCore, aPre, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	bCore, bPre, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+
+	aParts := strings.Split(aCore, ".")
+	bParts := strings.Split(bCore, ".")
+	for i := range max(len(aParts), len(bParts)) {
+		var x, y int
+		if i < len(aParts) {
+			x, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			y, _ = strconv.Atoi(bParts[i])
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return strings.Compare(aPre, bPre)
+}
@@ -0,0 +1,98 @@
+package upload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+)
+
+// journal records the progress of an upload on disk, to resume it after
+// an interruption.
+type journal struct {
+	path string
+
+	mu       sync.Mutex
+	UploadID string                 `json:"uploadId"`
+	SHA256   string                 `json:"sha256"`
+	Size     int64                  `json:"size"`
+	Parts    map[int]api.UploadPart `json:"parts"`
+}
+
+// journalKey identifies uploads of the same content, in parts of the same
+// size, to the same API and account.
+func journalKey(client *api.Client, sum string, partSize int64) string {
+	h := sha256.Sum256(fmt.Appendf(nil, "%s\x00%s\x00%s\x00%s\x00%d", client.BaseURL, client.Username, client.Org, sum, partSize))
+	return hex.EncodeToString(h[:16])
+}
+
+// openJournal reads the journal with key in dir. A missing or unreadable
+// journal is an empty one.
+func openJournal(dir, key string) *journal {
+	j := &journal{path: filepath.Join(dir, key+".json")}
+	if data, err := os.ReadFile(j.path); err == nil {
+		if err := json.Unmarshal(data, j); err != nil {
+			j.reset()
+		}
+	}
+	if j.Parts == nil {
+		j.Parts = map[int]api.UploadPart{}
+	}
+	return j
+}
+
+// addPart records a part received by the API.
+func (j *journal) addPart(p api.UploadPart) error {
+	j.mu.Lock()
+	j.Parts[p.Number] = p
+	j.mu.Unlock()
+	return j.save()
+}
+
+func (j *journal) reset() {
+	j.UploadID = ""
+	j.SHA256 = ""
+	j.Size = 0
+	j.Parts = map[int]api.UploadPart{}
+}
+
+// save writes the journal atomically, so that a killed process leaves
+// either the previous or the new one.
+func (j *journal) save() error {
+	j.mu.Lock()
+	data, err := json.Marshal(j)
+	j.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), j.path)
+}
+
+// remove deletes the journal once the upload is over.
+func (j *journal) remove() {
+	if err := os.Remove(j.path); err != nil {
+		return
+	}
+}
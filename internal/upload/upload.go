@@ -0,0 +1,348 @@
+// Package upload uploads large files, e.g. build contexts, in parts.
+//
+// Parts are checksummed and sent in parallel, and retried when the
+// connection drops or the API is unavailable. Progress is recorded in a
+// journal on disk so that an interrupted upload of the same file resumes
+// with the parts the API doesn't have yet, instead of starting over.
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/cli"
+)
+
+// Defaults of Options.
+const (
+	DefaultPartSize    = 8 << 20
+	DefaultConcurrency = 4
+	DefaultAttempts    = 4
+)
+
+// MinPartSize is the smallest part size the API accepts, except for the
+// last part.
+const MinPartSize = 1 << 10
+
+// retryDelay is the wait before the first retry of a request, doubled for
+// each following one.
+var retryDelay = 500 * time.Millisecond
+
+// Options configures an upload. The zero value uses the defaults.
+type Options struct {
+	// Name is the name of the upload, the base name of the file if empty.
+	Name        string
+	PartSize    int64
+	Concurrency int
+	// Attempts is how many times a part is sent before giving up.
+	Attempts int
+	// JournalDir is where the progress of uploads is recorded, under the
+	// user cache directory if empty.
+	JournalDir string
+	// Progress is where a progress bar is shown, none if nil.
+	Progress io.Writer
+}
+
+func (o *Options) setDefaults(path string) error {
+	if o.Name == "" {
+		o.Name = filepath.Base(path)
+	}
+	if o.PartSize == 0 {
+		o.PartSize = DefaultPartSize
+	}
+	if o.PartSize < MinPartSize {
+		return fmt.Errorf("part size must be at least %s", cli.FormatBytes(MinPartSize))
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultConcurrency
+	}
+	if o.Attempts <= 0 {
+		o.Attempts = DefaultAttempts
+	}
+	if o.JournalDir == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return fmt.Errorf("could not find the upload journal directory: %w", err)
+		}
+		o.JournalDir = filepath.Join(dir, "a0ctl", "uploads")
+	}
+	return nil
+}
+
+// File uploads the file at path, resuming a previous upload of the same
+// content if it was interrupted. It returns the completed upload, whose
+// checksum has been verified against the file.
+func File(ctx context.Context, client *api.Client, path string, opts Options) (api.Upload, error) {
+	if err := opts.setDefaults(path); err != nil {
+		return api.Upload{}, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return api.Upload{}, err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			return
+		}
+	}()
+
+	sum, parts, err := checksum(f, opts.PartSize)
+	if err != nil {
+		return api.Upload{}, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	var size int64
+	for _, p := range parts {
+		size += p.Size
+	}
+
+	j := openJournal(opts.JournalDir, journalKey(client, sum, opts.PartSize))
+	done := resume(client, j, sum, parts)
+	if j.UploadID == "" {
+		upload, err := client.Uploads.Initiate(api.InitiateUploadRequest{
+			Name:     opts.Name,
+			Size:     size,
+			SHA256:   sum,
+			PartSize: opts.PartSize,
+		})
+		if err != nil {
+			return api.Upload{}, err
+		}
+		j.UploadID = upload.ID
+		j.SHA256 = sum
+		j.Size = size
+	}
+	// A journal that can't be written only prevents resuming.
+	_ = j.save()
+
+	var progress *cli.ProgressBar
+	if opts.Progress != nil {
+		progress = cli.NewProgressBar(opts.Progress, "Uploading "+opts.Name, size)
+		var sent int64
+		for _, p := range parts {
+			if done[p.Number] {
+				sent += p.Size
+			}
+		}
+		progress.Set(sent)
+	}
+
+	u := &uploader{client: client, journal: j, file: f, opts: opts, progress: progress}
+	if err := u.sendParts(ctx, parts, done); err != nil {
+		return api.Upload{}, err
+	}
+	if progress != nil {
+		progress.Finish()
+	}
+
+	upload, err := client.Uploads.Complete(j.UploadID, parts)
+	if err != nil {
+		var statusErr *api.StatusError
+		if errors.As(err, &statusErr) && !statusErr.Temporary() {
+			// The parts the API has are unusable, start over next time.
+			j.remove()
+		}
+		return api.Upload{}, err
+	}
+	j.remove()
+	if upload.SHA256 != sum {
+		return api.Upload{}, fmt.Errorf("checksum of the upload %s doesn't match the file: got %s, want %s", upload.ID, upload.SHA256, sum)
+	}
+	return upload, nil
+}
+
+// checksum returns the SHA-256 of the whole of r and of each of its parts.
+func checksum(r io.Reader, partSize int64) (string, []api.UploadPart, error) {
+	total := sha256.New()
+	var parts []api.UploadPart
+	for number := 1; ; number++ {
+		h := sha256.New()
+		n, err := io.CopyN(io.MultiWriter(total, h), r, partSize)
+		if n > 0 || number == 1 {
+			parts = append(parts, api.UploadPart{Number: number, Size: n, SHA256: hex.EncodeToString(h.Sum(nil))})
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	return hex.EncodeToString(total.Sum(nil)), parts, nil
+}
+
+// resume returns the parts the API already has of the upload of the
+// journal, resetting the journal if the upload can't be resumed.
+func resume(client *api.Client, j *journal, sum string, parts []api.UploadPart) map[int]bool {
+	done := map[int]bool{}
+	if j.UploadID == "" {
+		return done
+	}
+	upload, err := client.Uploads.Get(j.UploadID)
+	if err != nil || upload.State != api.UploadStatePending || upload.SHA256 != sum {
+		// Expired, or completed by a process that then failed.
+		j.reset()
+		return done
+	}
+
+	// The API is the authority on what it received, the journal may lag
+	// behind when the previous process was killed.
+	expected := map[int]string{}
+	for _, p := range parts {
+		expected[p.Number] = p.SHA256
+	}
+	for _, p := range upload.Parts {
+		if expected[p.Number] == p.SHA256 {
+			done[p.Number] = true
+		}
+	}
+	return done
+}
+
+type uploader struct {
+	client   *api.Client
+	journal  *journal
+	file     io.ReaderAt
+	opts     Options
+	progress *cli.ProgressBar
+}
+
+// sendParts sends the parts not done yet, stopping at the first part that
+// fails after all its attempts.
+func (u *uploader) sendParts(ctx context.Context, parts []api.UploadPart, done map[int]bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	todo := make(chan api.UploadPart)
+	go func() {
+		defer close(todo)
+		for _, p := range parts {
+			if done[p.Number] {
+				continue
+			}
+			select {
+			case todo <- p:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for range u.opts.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, u.opts.PartSize)
+			for p := range todo {
+				if err := u.sendPart(ctx, p, buf[:p.Size]); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr == nil {
+		// The parent context was canceled.
+		firstErr = ctx.Err()
+	}
+	return firstErr
+}
+
+// sendPart sends a part, retrying on temporary failures with an
+// exponential backoff.
+func (u *uploader) sendPart(ctx context.Context, p api.UploadPart, buf []byte) error {
+	offset := int64(p.Number-1) * u.opts.PartSize
+	if _, err := u.file.ReadAt(buf, offset); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("could not read part %d: %w", p.Number, err)
+	}
+
+	return Retry(ctx, u.opts.Attempts, func(ctx context.Context) error {
+		sent, err := u.client.Uploads.PutPart(ctx, u.journal.UploadID, p.Number, buf)
+		if err != nil {
+			return err
+		}
+		if sent.SHA256 != p.SHA256 {
+			return &api.StatusError{
+				StatusCode: http.StatusUnprocessableEntity,
+				Err:        fmt.Errorf("checksum of part %d doesn't match: got %s, want %s", p.Number, sent.SHA256, p.SHA256),
+			}
+		}
+		if u.progress != nil {
+			u.progress.Add(len(buf))
+		}
+		// Recording progress is best effort, the API has the part.
+		_ = u.journal.addPart(sent)
+		return nil
+	})
+}
+
+// Retry calls send up to attempts times until it succeeds, waiting with an
+// exponential backoff between attempts. Only network errors and temporary
+// API errors are retried.
+func Retry(ctx context.Context, attempts int, send func(ctx context.Context) error) error {
+	delay := retryDelay
+	for attempt := 1; ; attempt++ {
+		err := send(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt >= attempts || !retryable(err) {
+			return err
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+// permanentError is an error Retry doesn't retry.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err so that Retry returns it without trying again, e.g.
+// when the content being sent is invalid.
+func Permanent(err error) error {
+	return &permanentError{err}
+}
+
+// retryable reports whether a failed request may succeed if sent again.
+// Errors without a status are network errors, e.g. dropped connections.
+func retryable(err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+	var statusErr *api.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Temporary() || statusErr.StatusCode == http.StatusUnprocessableEntity
+	}
+	return true
+}
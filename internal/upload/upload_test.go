@@ -0,0 +1,197 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a0dotrun/a0ctl/internal/api"
+	"github.com/a0dotrun/a0ctl/internal/api/apitest"
+)
+
+const testPartSize = MinPartSize
+
+func init() {
+	retryDelay = time.Millisecond
+}
+
+func newClient(t *testing.T) (*api.Client, *apitest.Server) {
+	t.Helper()
+	s := apitest.New(t)
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api.NewClient(u, apitest.DefaultToken, apitest.DefaultUsername), s
+}
+
+// writeFile writes size random bytes to a file.
+func writeFile(t *testing.T, size int) (string, []byte) {
+	t.Helper()
+	data := make([]byte, size)
+	r := rand.NewChaCha8([32]byte{})
+	_, _ = r.Read(data)
+	path := filepath.Join(t.TempDir(), "context.tar")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path, data
+}
+
+// partRequests counts the parts sent to the server.
+func partRequests(s *apitest.Server) int {
+	n := 0
+	for _, r := range s.Requests() {
+		if r.Method == http.MethodPut && strings.Contains(r.Path, "/parts/") {
+			n++
+		}
+	}
+	return n
+}
+
+func TestFile(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "empty", size: 0},
+		{name: "one part", size: testPartSize / 2},
+		{name: "exact parts", size: 4 * testPartSize},
+		{name: "last part shorter", size: 5*testPartSize + 17},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, s := newClient(t)
+			path, data := writeFile(t, tt.size)
+			journalDir := t.TempDir()
+
+			upload, err := File(context.Background(), client, path, Options{PartSize: testPartSize, JournalDir: journalDir})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := s.Uploaded(upload.ID)
+			if !ok || !bytes.Equal(got, data) {
+				t.Errorf("uploaded %d bytes, want %d", len(got), len(data))
+			}
+			if entries, _ := os.ReadDir(journalDir); len(entries) != 0 {
+				t.Errorf("journal left after the upload: %v", entries)
+			}
+		})
+	}
+}
+
+func TestFileRetriesParts(t *testing.T) {
+	client, s := newClient(t)
+	path, data := writeFile(t, 3*testPartSize)
+	s.Inject("PUT /v1/uploads/{id}/parts/2", apitest.Failure{Status: http.StatusServiceUnavailable, Times: 2})
+	s.Inject("PUT /v1/uploads/{id}/parts/3", apitest.Failure{Drop: true, Times: 1})
+
+	upload, err := File(context.Background(), client, path, Options{PartSize: testPartSize, JournalDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Uploaded(upload.ID); !bytes.Equal(got, data) {
+		t.Error("uploaded content doesn't match the file")
+	}
+	if n := partRequests(s); n != 6 {
+		t.Errorf("%d part requests, want 6", n)
+	}
+}
+
+func TestFileDoesNotRetryClientErrors(t *testing.T) {
+	client, s := newClient(t)
+	path, _ := writeFile(t, 2*testPartSize)
+	s.Inject("PUT /v1/uploads/{id}/parts/1", apitest.Failure{Status: http.StatusForbidden, Message: "quota exceeded"})
+
+	_, err := File(context.Background(), client, path, Options{PartSize: testPartSize, Concurrency: 1, JournalDir: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Fatalf("err = %v", err)
+	}
+	if n := partRequests(s); n != 1 {
+		t.Errorf("%d part requests, want 1", n)
+	}
+}
+
+func TestFileResumes(t *testing.T) {
+	client, s := newClient(t)
+	path, data := writeFile(t, 6*testPartSize)
+	journalDir := t.TempDir()
+	opts := Options{PartSize: testPartSize, Concurrency: 1, Attempts: 1, JournalDir: journalDir}
+
+	s.Inject("PUT /v1/uploads/{id}/parts/4", apitest.Failure{Status: http.StatusBadGateway})
+	if _, err := File(context.Background(), client, path, opts); err == nil {
+		t.Fatal("upload succeeded although part 4 failed")
+	}
+	sent := partRequests(s)
+
+	// The failure is over.
+	s.Inject("PUT /v1/uploads/{id}/parts/4", apitest.Failure{})
+	upload, err := File(context.Background(), client, path, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Uploaded(upload.ID); !bytes.Equal(got, data) {
+		t.Error("uploaded content doesn't match the file")
+	}
+	if upload.ID != "upl_1" {
+		t.Errorf("upload %s, want the resumed upl_1", upload.ID)
+	}
+	if n := partRequests(s) - sent; n != 3 {
+		t.Errorf("%d parts sent when resuming, want 3", n)
+	}
+}
+
+func TestFileRestartsExpiredUploads(t *testing.T) {
+	client, s := newClient(t)
+	path, data := writeFile(t, 2*testPartSize)
+	opts := Options{PartSize: testPartSize, Attempts: 1, JournalDir: t.TempDir()}
+
+	s.Inject("POST /v1/uploads/{id}/complete", apitest.Failure{Status: http.StatusServiceUnavailable, Times: 1})
+	if _, err := File(context.Background(), client, path, opts); err == nil {
+		t.Fatal("upload succeeded although completing it failed")
+	}
+	if err := client.Uploads.Abort("upl_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	upload, err := File(context.Background(), client, path, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upload.ID != "upl_2" {
+		t.Errorf("upload %s, want a new one", upload.ID)
+	}
+	if got, _ := s.Uploaded(upload.ID); !bytes.Equal(got, data) {
+		t.Error("uploaded content doesn't match the file")
+	}
+}
+
+func TestFileVerifiesChecksum(t *testing.T) {
+	client, s := newClient(t)
+	path, _ := writeFile(t, testPartSize)
+	s.HandleJSON("POST /v1/uploads/{id}/complete", http.StatusOK, api.Upload{ID: "upl_1", State: api.UploadStateCompleted, SHA256: "0000"})
+
+	_, err := File(context.Background(), client, path, Options{PartSize: testPartSize, JournalDir: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "doesn't match") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestFileCanceled(t *testing.T) {
+	client, s := newClient(t)
+	path, _ := writeFile(t, 4*testPartSize)
+	s.Inject("PUT /v1/uploads/{id}/parts/{n}", apitest.Failure{Delay: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := File(ctx, client, path, Options{PartSize: testPartSize, JournalDir: t.TempDir()}); err != context.DeadlineExceeded {
+		t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+}